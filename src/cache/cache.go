@@ -26,13 +26,16 @@ const (
 )
 
 const (
-	TEMPLATECACHE    = "template_cache"
-	TOGGLECACHE      = "toggle_cache"
-	PROMPTCOUNTCACHE = "prompt_count_cache"
-	ENGINECACHE      = "engine_cache"
-	FONTLISTCACHE    = "font_list_cache"
-	CLAUDECACHE      = "claude_cache"
-	COPILOTCLICACHE  = "copilot_cli_cache"
+	TEMPLATECACHE     = "template_cache"
+	TOGGLECACHE       = "toggle_cache"
+	SCOPEDTOGGLECACHE = "scoped_toggle_cache"
+	PROMPTCOUNTCACHE  = "prompt_count_cache"
+	ENGINECACHE       = "engine_cache"
+	FONTLISTCACHE     = "font_list_cache"
+	CLAUDECACHE       = "claude_cache"
+	COPILOTCLICACHE   = "copilot_cli_cache"
+	// TERMINALBACKGROUNDCACHE holds "light" or "dark" when the terminal answered its background color
+	TERMINALBACKGROUNDCACHE = "terminal_background_cache"
 )
 
 type Entry[T any] struct {
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -345,7 +347,28 @@ func DeleteAll(s Store) {
 	store.dirty = true
 }
 
-func Print(s Store) string {
+// Keys returns the keys of the entries in the store that haven't expired.
+func Keys(s Store) []string {
+	store := s.get()
+	if store == nil {
+		return nil
+	}
+
+	var keys []string
+
+	for key, entry := range store.cache.ToSimple() {
+		if !entry.Expired() {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Print returns a readable list of the entries in the store, only those of keys when given.
+func Print(s Store, keys ...string) string {
 	defer log.Trace(time.Now(), string(s))
 
 	store := s.get()
@@ -361,6 +384,10 @@ func Print(s Store) string {
 	var builder strings.Builder
 
 	for key, entry := range cache {
+		if len(keys) != 0 && !slices.Contains(keys, key) {
+			continue
+		}
+
 		builder.WriteString("\n")
 
 		if entry.Expired() {
@@ -385,5 +412,9 @@ func Print(s Store) string {
 		fmt.Fprintf(&builder, "  TTL: %s\n", ttlInfo)
 	}
 
+	if builder.Len() == 0 {
+		return fmt.Sprintf("Store %s has no entry for %s", string(s), strings.Join(keys, ", "))
+	}
+
 	return builder.String()
 }
@@ -30,6 +30,8 @@ type SimpleTemplate struct {
 	Root          bool
 	Interrupted   bool
 	Executed      bool
+	// TerminalIsLight is set when the terminal reported a light background color
+	TerminalIsLight bool
 }
 
 func (t *Template) AddSegmentData(key string, value any) {
@@ -15,7 +15,7 @@ var (
 )
 
 var cacheCmd = &cmdtree.Command{
-	Use:   "cache [path|clear|ttl|show]",
+	Use:   "cache [path|clear|ttl|show] [key]",
 	Short: "Interact with the oh-my-posh cache",
 	Long: `Interact with the oh-my-posh cache.
 
@@ -24,7 +24,7 @@ You can do the following:
 - path: list cache path
 - clear: remove all cache values
 - ttl: get cache TTL in days
-- show: print a detailed list of all cached values`,
+- show: print a detailed list of all cached values, or only the one of the given key`,
 	ValidArgs: []string{
 		"path",
 		"clear",
@@ -74,12 +74,13 @@ You can do the following:
 				store = cache.Session
 			}
 
-			fmt.Println(cache.Print(store))
+			fmt.Println(cache.Print(store, args[1:]...))
 		}
 	},
 }
 
 func init() {
 	cacheCmd.Flags().BoolVarP(&session, "session", "s", false, "show the session cache")
+	cacheCmd.ValidArgsFunction = completeCacheKeys
 	RootCmd.AddCommand(cacheCmd)
 }
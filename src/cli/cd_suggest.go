@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
+)
+
+var listSuggestions bool
+
+var cdSuggestCmd = &cmdtree.Command{
+	Use:   "cd-suggest [query]",
+	Short: "Suggest a folder to jump to",
+	Long: `Suggest a folder to jump to, based on the folders visited at the prompt.
+
+Needs the frecency setting in your config. Every word of the query has to match
+the folder's path in order, the last word has to match its last element. The
+folder with the highest rank that still exists and isn't the current directory
+wins.
+
+Example usage:
+
+> cd "$(oh-my-posh cd-suggest posh docs)"
+
+Exits with a non-zero code when no folder matches.`,
+	Args: cmdtree.MinimumNArgs(1),
+	Run: func(_ *cmdtree.Command, args []string) {
+		pwd, _ := os.Getwd()
+
+		suggestions := cdSuggestions(frecency.Load(frecency.File()), args, pwd)
+		if len(suggestions) == 0 {
+			exitcode = 1
+			return
+		}
+
+		if !listSuggestions {
+			suggestions = suggestions[:1]
+		}
+
+		for _, suggestion := range suggestions {
+			fmt.Println(suggestion)
+		}
+	},
+}
+
+// cdSuggestions returns the existing folders matching the query, best match
+// first, leaving out the current directory.
+func cdSuggestions(db *frecency.DB, query []string, pwd string) []string {
+	var suggestions []string
+
+	for _, entry := range db.Query(query, time.Now()) {
+		if entry.Path == pwd {
+			continue
+		}
+
+		if info, err := os.Stat(entry.Path); err != nil || !info.IsDir() {
+			continue
+		}
+
+		suggestions = append(suggestions, entry.Path)
+	}
+
+	return suggestions
+}
+
+func init() {
+	cdSuggestCmd.Flags().BoolVarP(&listSuggestions, "list", "l", false, "list every matching folder, best match first")
+	RootCmd.AddCommand(cdSuggestCmd)
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCdSuggestions(t *testing.T) {
+	root := t.TempDir()
+	projects := filepath.Join(root, "projects")
+	posh := filepath.Join(projects, "oh-my-posh")
+	poshGit := filepath.Join(projects, "posh-git")
+
+	require.NoError(t, os.MkdirAll(posh, 0o755))
+	require.NoError(t, os.MkdirAll(poshGit, 0o755))
+
+	now := time.Now().Unix()
+	db := &frecency.DB{
+		Entries: []*frecency.Entry{
+			{Path: posh, Rank: 5, LastAccess: now},
+			{Path: poshGit, Rank: 10, LastAccess: now},
+			{Path: filepath.Join(projects, "removed-posh"), Rank: 20, LastAccess: now},
+		},
+	}
+
+	cases := []struct {
+		Case     string
+		Pwd      string
+		Query    []string
+		Expected []string
+	}{
+		{Case: "best match first", Query: []string{"posh"}, Pwd: root, Expected: []string{poshGit, posh}},
+		{Case: "skip the current directory", Query: []string{"posh"}, Pwd: poshGit, Expected: []string{posh}},
+		{Case: "multiple keywords", Query: []string{"projects", "oh"}, Pwd: root, Expected: []string{posh}},
+		{Case: "no match", Query: []string{"nope"}, Pwd: root},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, cdSuggestions(db, tc.Query, tc.Pwd), tc.Case)
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/cli/font"
+	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
+)
+
+// Completions run without the root command's hooks, resolve the config the same way.
+func completionConfig() string {
+	if len(configFlag) != 0 {
+		return configFlag
+	}
+
+	return os.Getenv("POSH_CONFIG")
+}
+
+// completeThemes offers the built-in themes, unless a path is being typed.
+func completeThemes(_ *cmdtree.Command, _ []string, toComplete string) []string {
+	if strings.ContainsAny(toComplete, `/\~.:`) {
+		return nil
+	}
+
+	return config.Themes()
+}
+
+// completeSegments offers the segments of the current config that aren't on the command line yet.
+func completeSegments(_ *cmdtree.Command, args []string, _ string) []string {
+	cache.Init(os.Getenv("POSH_SHELL"))
+	defer cache.Close()
+
+	cfg := config.Get(completionConfig(), false)
+
+	var candidates []string
+
+	add := func(segment *config.Segment) {
+		name := segment.DataKey()
+		if slices.Contains(args, name) {
+			return
+		}
+
+		candidates = append(candidates, name+"\t"+string(segment.Type))
+	}
+
+	for _, block := range cfg.Blocks {
+		for _, segment := range block.Segments {
+			add(segment)
+		}
+	}
+
+	for _, tooltip := range cfg.Tooltips {
+		add(tooltip)
+	}
+
+	return candidates
+}
+
+func completeFonts(_ *cmdtree.Command, args []string, _ string) []string {
+	if len(args) != 0 {
+		return nil
+	}
+
+	// completion runs on a keypress, only offer what an earlier `font list` or
+	// `font install` cached and never wait on the network
+	cache.Init(os.Getenv("POSH_SHELL"), cache.NoSession)
+	defer cache.Close()
+
+	fonts, err := font.Cached()
+	if err != nil {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(fonts))
+	for _, f := range fonts {
+		candidates = append(candidates, f.Name)
+	}
+
+	return candidates
+}
+
+func completeCacheKeys(_ *cmdtree.Command, args []string, _ string) []string {
+	if len(args) != 1 || args[0] != "show" {
+		return nil
+	}
+
+	cache.Init(os.Getenv("POSH_SHELL"))
+	defer cache.Close()
+
+	store := cache.Device
+	if session {
+		store = cache.Session
+	}
+
+	return cache.Keys(store)
+}
+
+func completeValues(values ...string) cmdtree.CompletionFunc {
+	return func(_ *cmdtree.Command, _ []string, _ string) []string {
+		return values
+	}
+}
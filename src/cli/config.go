@@ -16,7 +16,8 @@ var configCmd = &cmdtree.Command{
 	Short: "Interact with the config",
 	Long: `Interact with the config.
 
-You can export or edit the config (via the editor specified in the environment variable "EDITOR").`,
+You can export, lint or edit the config (via the editor specified in the environment variable "EDITOR"),
+or import a terminal color scheme into its palette.`,
 	ValidArgs: []string{
 		"edit",
 	},
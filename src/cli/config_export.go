@@ -90,7 +90,10 @@ Exports the current config to "~/new_config.omp.json" (in JSON format).`,
 			}
 		}
 
-		cfg.Write(format)
+		if err := cfg.Write(format); err != nil {
+			fmt.Println(err.Error())
+			exitcode = 1
+		}
 	},
 }
 
@@ -109,5 +112,6 @@ func cleanOutputPath(output string) string {
 func init() {
 	exportCmd.Flags().StringVarP(&format, "format", "f", "json", "config format to migrate to")
 	exportCmd.Flags().StringVarP(&output, "output", "o", "", "config file to export to")
+	_ = exportCmd.RegisterFlagCompletionFunc("format", completeValues(config.JSON, config.YAML, config.TOML))
 	configCmd.AddCommand(exportCmd)
 }
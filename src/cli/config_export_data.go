@@ -70,7 +70,7 @@ src/prompt/testdata/fixtures - run from src/.`,
 				return
 			}
 
-			writeDataOutput(doc)
+			writeDataOutput(doc, outputData)
 
 			return
 		}
@@ -111,19 +111,19 @@ src/prompt/testdata/fixtures - run from src/.`,
 			}
 		}
 
-		writeDataOutput(doc)
+		writeDataOutput(doc, outputData)
 	},
 }
 
-// writeDataOutput prints doc to stdout, or writes it to --output when set.
-// Shared by the single-config path and the --themes merge path.
-func writeDataOutput(doc []byte) {
-	if outputData == "" {
+// writeDataOutput prints doc to stdout, or writes it to output when set.
+// Shared by the single-config path, the --themes merge path and debug record.
+func writeDataOutput(doc []byte, output string) {
+	if output == "" {
 		fmt.Println(string(doc))
 		return
 	}
 
-	if err := os.WriteFile(cleanOutputPath(outputData), doc, 0o644); err != nil {
+	if err := os.WriteFile(cleanOutputPath(output), doc, 0o644); err != nil {
 		exitcode = 666
 		fmt.Println(err.Error())
 	}
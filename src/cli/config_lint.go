@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
+	"github.com/jandedobbeleer/oh-my-posh/src/template"
+)
+
+var lintCmd = &cmdtree.Command{
+	Use:   "lint",
+	Short: "Lint the templates in your config",
+	Long: `Lint the templates in your config.
+
+Parses every template in the config and checks the properties it references against
+the data of the segment it belongs to, without rendering the prompt. Unknown properties
+and functions are reported as errors, functions that can access the host system
+(like cmd or readFile) as warnings. Segment colors that don't reach minimum_contrast
+(4.5 when not set) are reported as warnings too.
+
+Example usage:
+
+> oh-my-posh config lint --config ~/myconfig.omp.json
+
+Exits with a non-zero code when at least one error is found.`,
+	Args: cmdtree.NoArgs,
+	Run: func(_ *cmdtree.Command, _ []string) {
+		cache.Init(os.Getenv("POSH_SHELL"))
+
+		setConfigFlag()
+
+		cfg, err := config.Parse(configFlag)
+		if err != nil {
+			fmt.Println(err.Error())
+			exitcode = 1
+			return
+		}
+
+		issues := cfg.Lint()
+		if len(issues) == 0 {
+			fmt.Println("no issues found")
+			return
+		}
+
+		for _, issue := range issues {
+			fmt.Printf("%s: %s: %s\n", issue.Severity, issue.Location, issue.Message)
+
+			if issue.Severity == template.SeverityError {
+				exitcode = 1
+			}
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(lintCmd)
+}
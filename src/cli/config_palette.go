@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
+)
+
+var (
+	paletteName   string
+	schemeFormat  string
+	paletteDryRun bool
+)
+
+var paletteCmd = &cmdtree.Command{
+	Use:   "palette",
+	Short: "Interact with the palette of your config",
+	Long: `Interact with the palette of your config.
+
+You can import the colors of a terminal color scheme.`,
+	Args: cmdtree.NoArgs,
+	Run: func(cmd *cmdtree.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var paletteImportCmd = &cmdtree.Command{
+	Use:   "import [file]",
+	Short: "Import a terminal color scheme into your palette",
+	Long: `Import a terminal color scheme into your palette.
+
+Reads iTerm2 (.itermcolors), Windows Terminal (JSON), Alacritty, kitty, Ghostty,
+WezTerm and base16 (YAML) color schemes and adds their colors to the palette of your config
+as black, red, green, yellow, blue, magenta, cyan, white, their bright variants
+(brightBlack, ...), background, foreground and cursor. Base16 schemes also add base00 to base0F.
+Existing keys with the same name are overwritten, the original config is kept as a .bak file.
+
+The format is detected from the file, use --format to set it explicitly.
+
+Example usage:
+
+> oh-my-posh config palette import ~/Downloads/Dracula.itermcolors
+
+Adds the Dracula colors to the palette of the current config.
+
+> oh-my-posh config palette import ~/.config/ghostty/themes/nord --name nord --config ~/myconfig.omp.json
+
+Adds the Nord colors to the "nord" palette in palettes.list of "~/myconfig.omp.json".`,
+	Args: cmdtree.ExactArgs(1),
+	Run: func(_ *cmdtree.Command, args []string) {
+		palette, err := config.ImportScheme(args[0], config.SchemeFormat(schemeFormat))
+		if err != nil {
+			fmt.Println(err.Error())
+			exitcode = 1
+			return
+		}
+
+		cache.Init(os.Getenv("POSH_SHELL"))
+
+		setConfigFlag()
+
+		cfg, err := config.ReadSource(configFlag)
+		if err != nil {
+			fmt.Println(err.Error())
+			exitcode = 1
+			return
+		}
+
+		cfg.ImportPalette(palette, paletteName)
+
+		if paletteDryRun {
+			fmt.Print(cfg.Export(cfg.Format))
+			return
+		}
+
+		if err := cfg.Backup(); err != nil {
+			fmt.Println("unable to back up the config:", err.Error())
+			exitcode = 1
+			return
+		}
+
+		if err := cfg.Write(cfg.Format); err != nil {
+			fmt.Println("unable to write the config:", err.Error())
+			exitcode = 1
+			return
+		}
+
+		fmt.Printf("imported %d colors into %s\n", len(palette), cfg.Source)
+	},
+}
+
+func init() {
+	paletteImportCmd.Flags().StringVarP(&paletteName, "name", "n", "", "name of the palette in palettes.list to import into")
+	paletteImportCmd.Flags().StringVarP(&schemeFormat, "format", "f", "", "color scheme format: iterm2, windows-terminal, alacritty, kitty, ghostty, wezterm or base16")
+	paletteImportCmd.Flags().BoolVar(&paletteDryRun, "dry-run", false, "print the resulting config instead of writing it")
+	_ = paletteImportCmd.RegisterFlagCompletionFunc("format", completeValues(string(config.SchemeITerm2), string(config.SchemeWindowsTerminal),
+		string(config.SchemeAlacritty), string(config.SchemeKitty), string(config.SchemeGhostty), string(config.SchemeWezTerm), string(config.SchemeBase16)))
+	paletteCmd.AddCommand(paletteImportCmd)
+	configCmd.AddCommand(paletteCmd)
+}
@@ -19,8 +19,10 @@ import (
 )
 
 var (
-	debugCmd  = createDebugCmd()
-	startTime = time.Now()
+	debugCmd    = createDebugCmd()
+	startTime   = time.Now()
+	traceOut    string
+	traceFormat string
 )
 
 func init() {
@@ -35,6 +37,17 @@ func createDebugCmd() *cmdtree.Command {
 		Run: func(_ *cmdtree.Command, _ []string) {
 			startTime := time.Now()
 
+			if len(traceOut) != 0 {
+				if traceFormat != log.TraceChrome && traceFormat != log.TraceOTLP {
+					// usage error
+					fmt.Printf("trace format must be %s or %s\n", log.TraceChrome, log.TraceOTLP)
+					exitcode = 2
+					return
+				}
+
+				log.EnableTracing()
+			}
+
 			log.Enable(plain)
 
 			flags := &runtime.Flags{
@@ -52,6 +65,9 @@ func createDebugCmd() *cmdtree.Command {
 			cfg := getDebugConfig(configFlag)
 
 			template.Init(env, cfg.Var, cfg.Maps)
+			template.SetCapabilities(cfg.TemplateCapabilities)
+			template.SetPartials(cfg.Partials)
+			template.EnableProfiling()
 
 			defer func() {
 				template.SaveCache()
@@ -70,10 +86,28 @@ func createDebugCmd() *cmdtree.Command {
 			}
 
 			fmt.Print(eng.PrintDebug(startTime, build.Version))
+
+			if len(traceOut) == 0 {
+				return
+			}
+
+			log.Span(startTime, "debug", "debug")
+
+			path, err := writeTrace(traceOut, traceFormat)
+			if err != nil {
+				exitcode = 666
+				fmt.Println(err.Error())
+				return
+			}
+
+			fmt.Printf("\n%s %s\n", log.Text("Trace path:").Green().Bold().Plain(), path)
 		},
 	}
 
 	debugCmd.Flags().StringVar(&pwd, "pwd", "", "current working directory")
+	debugCmd.Flags().StringVar(&traceOut, "trace-out", "", "file to write a trace of the segments, commands, requests and templates to")
+	debugCmd.Flags().StringVar(&traceFormat, "trace-format", log.TraceChrome, "trace format: chrome (Perfetto) or otlp (OpenTelemetry JSON)")
+	_ = debugCmd.RegisterFlagCompletionFunc("trace-format", completeValues(log.TraceChrome, log.TraceOTLP))
 
 	// Deprecated flags, should be kept to avoid breaking CLI integration.
 	debugCmd.Flags().StringVar(&shellName, "shell", "", "the shell to print for")
@@ -92,3 +126,20 @@ func getDebugConfig(configpath string) *config.Config {
 	reload, _ := cache.Get[bool](cache.Device, config.RELOAD)
 	return config.Get(configpath, reload)
 }
+
+func writeTrace(output, format string) (string, error) {
+	output = cleanOutputPath(output)
+
+	file, err := os.Create(output)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	if err := log.WriteTrace(file, format); err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
@@ -0,0 +1,362 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	goruntime "runtime"
+	"slices"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/build"
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
+	"github.com/jandedobbeleer/oh-my-posh/src/prompt"
+	"github.com/jandedobbeleer/oh-my-posh/src/regex"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/shell"
+	"github.com/jandedobbeleer/oh-my-posh/src/template"
+	"github.com/jandedobbeleer/oh-my-posh/src/terminal"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
+)
+
+// A debug bundle is a recorded data document with two more top-level keys: the
+// config it was rendered with and what describes the render itself. Both are
+// ignored by config.LoadData, so a bundle still works as a --data file.
+const (
+	bundleKey       = "bundle"
+	bundleConfigKey = "config"
+)
+
+// bundleEnvironment are the variables read while rendering a template that no
+// .Env reference in the config gives away: the shell level, the SSH detection
+// behind the url helpers and the terminal identity.
+var bundleEnvironment = []string{"COLORTERM", "SHLVL", "SSH_CLIENT", "SSH_CONNECTION", "TERM", "TERM_PROGRAM"}
+
+// debugBundle describes the render a bundle was recorded from.
+type debugBundle struct {
+	Environment   map[string]string      `json:"environment,omitempty"`
+	Recorder      string                 `json:"recorder"`
+	OS            string                 `json:"os"`
+	Arch          string                 `json:"arch"`
+	Shell         string                 `json:"shell,omitempty"`
+	ShellVersion  string                 `json:"shell_version,omitempty"`
+	Segments      []prompt.SegmentTiming `json:"segments"`
+	Templates     []template.Timing      `json:"templates,omitempty"`
+	Duration      time.Duration          `json:"duration_ns"`
+	TerminalWidth int                    `json:"terminal_width,omitempty"`
+	Plain         bool                   `json:"plain,omitempty"`
+}
+
+var (
+	bundleOutput  string
+	replayTimings bool
+)
+
+var debugRecordCmd = &cmdtree.Command{
+	Use:   "record",
+	Short: "Record the prompt into a sanitized bundle",
+	Long: `Record the prompt into a sanitized bundle.
+
+Renders the primary prompt like debug does and records everything needed to
+render it again on another machine: the config, the template context, every
+segment's data and methods, the variables the templates read, the terminal
+width and the timings. Identity (username, hostname, paths, git/cloud identity,
+API keys and tokens) is scrubbed the same way config export data --sanitize does.
+
+Attach the bundle to an issue so the prompt can be reproduced with debug replay.
+
+Example usage:
+
+> oh-my-posh debug record --output ~/prompt.bundle.json`,
+	Args: cmdtree.NoArgs,
+	Run: func(_ *cmdtree.Command, _ []string) {
+		doc, err := recordBundle()
+		if err != nil {
+			exitcode = 666
+			fmt.Println(err.Error())
+			return
+		}
+
+		writeDataOutput(doc, bundleOutput)
+	},
+}
+
+var debugReplayCmd = &cmdtree.Command{
+	Use:   "replay <bundle>",
+	Short: "Print the prompt recorded in a bundle",
+	Long: `Print the prompt recorded in a bundle.
+
+Renders the prompt from the recorded config and data only: nothing is read
+from the machine, so the output is identical wherever it runs.
+
+Example usage:
+
+> oh-my-posh debug replay ~/prompt.bundle.json --timings`,
+	Args: cmdtree.ExactArgs(1),
+	Run: func(_ *cmdtree.Command, args []string) {
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			exitcode = 666
+			fmt.Println(err.Error())
+			return
+		}
+
+		output, bundle, err := replayBundle(raw)
+		if err != nil {
+			exitcode = 666
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Print(output)
+
+		if !replayTimings {
+			return
+		}
+
+		fmt.Print(bundleTimings(bundle))
+	},
+}
+
+func init() {
+	debugRecordCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "bundle file to write to")
+	debugRecordCmd.Flags().StringVar(&pwd, "pwd", "", "current working directory")
+	debugReplayCmd.Flags().BoolVar(&replayTimings, "timings", false, "print the recorded segment timings after the prompt")
+
+	debugCmd.AddCommand(debugRecordCmd)
+	debugCmd.AddCommand(debugReplayCmd)
+}
+
+func recordBundle() ([]byte, error) {
+	startTime := time.Now()
+
+	flags := &runtime.Flags{
+		PWD:          pwd,
+		Shell:        shell.GENERIC,
+		ShellVersion: os.Getenv("POSH_SHELL_VERSION"),
+		Plain:        plain,
+		Profile:      true,
+		IsPrimary:    true,
+	}
+
+	env := &runtime.Terminal{}
+	env.Init(flags)
+
+	cache.Init(os.Getenv("POSH_SHELL"))
+
+	cfg := getDebugConfig(configFlag)
+
+	template.Init(env, cfg.Var, cfg.Maps)
+	template.SetCapabilities(cfg.TemplateCapabilities)
+	template.SetPartials(cfg.Partials)
+	template.EnableProfiling()
+
+	defer func() {
+		template.SaveCache()
+		cache.Close()
+	}()
+
+	terminal.Init(shell.GENERIC)
+	terminal.BackgroundColor = cfg.TerminalBackground.ResolveTemplate()
+	terminal.Colors = cfg.MakeColors(env)
+	terminal.Plain = plain
+
+	eng := &prompt.Engine{
+		Config: cfg,
+		Env:    env,
+		Plain:  plain,
+	}
+
+	eng.Primary()
+
+	doc, err := buildDataDocument(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = sanitizeDataDocument(doc, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+
+	exported := cfg.Export(config.JSON)
+
+	var cfgDoc map[string]any
+	if err := json.Unmarshal([]byte(exported), &cfgDoc); err != nil {
+		return nil, fmt.Errorf("failed to export config: %w", err)
+	}
+
+	sanitizeConfigSecrets(cfgDoc)
+
+	environment := make(map[string]string)
+	for _, name := range bundleEnvironmentNames(exported) {
+		if value, ok := os.LookupEnv(name); ok {
+			environment[name] = value
+		}
+	}
+
+	sanitizeEnvironment(environment, env.Home())
+
+	width, _ := env.TerminalWidth()
+	profile := eng.Profile("")
+
+	root[bundleConfigKey] = cfgDoc
+	root[bundleKey] = &debugBundle{
+		Recorder:      build.Version,
+		OS:            env.GOOS(),
+		Arch:          goruntime.GOARCH,
+		Shell:         os.Getenv("POSH_SHELL"),
+		ShellVersion:  flags.ShellVersion,
+		TerminalWidth: width,
+		Plain:         plain,
+		Environment:   environment,
+		Segments:      profile.Segments,
+		Templates:     profile.Templates,
+		Duration:      time.Since(startTime),
+	}
+
+	// keep the templates readable, they are full of < and >
+	var buffer bytes.Buffer
+
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(buffer.Bytes()), nil
+}
+
+// bundleEnvironmentNames returns the variables the config's templates read,
+// through .Env.NAME or env "NAME", together with bundleEnvironment.
+func bundleEnvironmentNames(cfg string) []string {
+	names := slices.Clone(bundleEnvironment)
+
+	for _, pattern := range []string{
+		`\.Env\.(?P<name>[A-Za-z_][A-Za-z0-9_]*)`,
+		`\benv\s+\\?"(?P<name>[A-Za-z_][A-Za-z0-9_]*)\\?"`,
+	} {
+		for _, match := range regex.FindAllNamedRegexMatch(pattern, cfg) {
+			names = append(names, match["name"])
+		}
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// replayBundle renders the primary prompt of a bundle without touching the
+// machine it runs on, see runtime.Flags.DataOnly.
+func replayBundle(raw []byte) (string, *debugBundle, error) {
+	data, err := config.ParseData(raw)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return "", nil, err
+	}
+
+	cfgRaw, ok := root[bundleConfigKey]
+	if !ok {
+		return "", nil, errors.New("not a debug bundle: it holds no config, record one with oh-my-posh debug record")
+	}
+
+	bundle := &debugBundle{}
+
+	if bundleRaw, ok := root[bundleKey]; ok {
+		if err := json.Unmarshal(bundleRaw, bundle); err != nil {
+			return "", nil, fmt.Errorf("failed to parse bundle: %w", err)
+		}
+	}
+
+	cfg, err := config.ParseBytes(config.JSON, cfgRaw)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the recorded title and working directory would otherwise be applied to the terminal replaying them
+	cfg.ConsoleTitleTemplate = ""
+	cfg.PWD = ""
+	cfg.ShellIntegration = false
+
+	flags := &runtime.Flags{
+		Shell:         shell.GENERIC,
+		ShellVersion:  bundle.ShellVersion,
+		TerminalWidth: bundle.TerminalWidth,
+		Plain:         plain || bundle.Plain,
+		IsPrimary:     true,
+		DataOnly:      true,
+		EnvVars:       bundle.Environment,
+	}
+
+	if err := data.ApplyFlags(flags, nil); err != nil {
+		return "", nil, err
+	}
+
+	env := &runtime.Terminal{}
+	env.Init(flags)
+
+	// a new session keeps the replaying machine's cached prompt out of the render
+	cache.Init(shell.GENERIC, cache.NewSession)
+	defer cache.Close()
+
+	// only the recorded env section may end up in the template context
+	template.ResetCache()
+	template.Init(env, cfg.Var, cfg.Maps)
+	template.SetCapabilities(cfg.TemplateCapabilities)
+	template.SetPartials(cfg.Partials)
+
+	terminal.Init(shell.GENERIC)
+	terminal.BackgroundColor = cfg.TerminalBackground.ResolveTemplate()
+	terminal.Colors = cfg.MakeColors(env)
+	terminal.Plain = flags.Plain
+
+	eng := &prompt.Engine{
+		Config: cfg,
+		Env:    env,
+		Plain:  flags.Plain,
+	}
+
+	return eng.Primary(), bundle, nil
+}
+
+func bundleTimings(bundle *debugBundle) string {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "\n\nRecorded with %s on %s/%s", bundle.Recorder, bundle.OS, bundle.Arch)
+
+	if len(bundle.Shell) != 0 {
+		fmt.Fprintf(&buffer, " in %s", bundle.Shell)
+
+		if len(bundle.ShellVersion) != 0 {
+			fmt.Fprintf(&buffer, " (%s)", bundle.ShellVersion)
+		}
+	}
+
+	fmt.Fprintf(&buffer, ", run duration %s\n\n", bundle.Duration)
+
+	width := 0
+	for _, segment := range bundle.Segments {
+		width = max(width, len(segment.Name))
+	}
+
+	for _, segment := range bundle.Segments {
+		fmt.Fprintf(&buffer, "%-*s %-5t - %3d ms\n", width, segment.Name, segment.Enabled, segment.Duration.Milliseconds())
+	}
+
+	return buffer.String()
+}
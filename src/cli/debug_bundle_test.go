@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleEnvironmentNames(t *testing.T) {
+	cfg := `{"template": "{{ .Env.AWS_PROFILE }} {{ env \"KUBECONFIG\" }} {{ .Env.AWS_PROFILE }}"}`
+
+	names := bundleEnvironmentNames(cfg)
+
+	assert.Contains(t, names, "AWS_PROFILE")
+	assert.Contains(t, names, "KUBECONFIG")
+	assert.Contains(t, names, "TERM_PROGRAM")
+	assert.IsNonDecreasing(t, names)
+
+	count := 0
+	for _, name := range names {
+		if name == "AWS_PROFILE" {
+			count++
+		}
+	}
+
+	assert.Equal(t, 1, count)
+}
+
+func TestSanitizeConfigSecrets(t *testing.T) {
+	var cfg map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"blocks": [{"segments": [{"type": "owm", "options": {"api_key": "abc", "location": "Ghent", "cache_timeout": 5}}]}],
+		"tooltips": [{"type": "strava", "key": "git", "options": {"access_token": "def", "refresh_token": "ghi", "token": "jkl"}}]
+	}`), &cfg))
+
+	sanitizeConfigSecrets(cfg)
+
+	raw, err := json.Marshal(cfg)
+	require.NoError(t, err)
+
+	doc := string(raw)
+	for _, secret := range []string{"abc", "def", "ghi", "jkl"} {
+		assert.NotContains(t, doc, secret)
+	}
+
+	assert.Contains(t, doc, `"location":"Ghent"`)
+	assert.Contains(t, doc, `"key":"git"`)
+}
+
+func TestSanitizeEnvironment(t *testing.T) {
+	environment := map[string]string{
+		"KUBECONFIG":     "/home/jan/.kube/config",
+		"SSH_CONNECTION": "10.0.0.4 52000 10.0.0.5 22",
+		"TERM_PROGRAM":   "vscode",
+		"GITHUB_TOKEN":   "ghp_secret",
+		"OPENAI_API_KEY": "sk-secret",
+		"USER":           "jan",
+		"HOSTNAME":       "surface-pro",
+	}
+
+	sanitizeEnvironment(environment, "/home/jan")
+
+	assert.Equal(t, "~/.kube/config", environment["KUBECONFIG"])
+	assert.Equal(t, sanitizedSSH, environment["SSH_CONNECTION"])
+	assert.Equal(t, "vscode", environment["TERM_PROGRAM"])
+	assert.Empty(t, environment["GITHUB_TOKEN"])
+	assert.Empty(t, environment["OPENAI_API_KEY"])
+	assert.Equal(t, sanitizedUser, environment["USER"])
+	assert.Equal(t, sanitizedHost, environment["HOSTNAME"])
+}
+
+func TestReplayBundle(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Bundle   string
+		Expected string
+		Error    string
+	}{
+		{
+			Case: "Recorded data and environment",
+			Bundle: `{
+				"version": 1,
+				"env": {"UserName": "alice"},
+				"segments": {},
+				"config": {"blocks": [{"type": "prompt", "alignment": "left", "segments": [
+					{"type": "text", "style": "plain", "template": "{{ .UserName }} {{ .Env.GREETING }}"}
+				]}]},
+				"bundle": {"plain": true, "environment": {"GREETING": "hello"}}
+			}`,
+			Expected: "alice hello",
+		},
+		{
+			Case: "Variables that weren't recorded are empty",
+			Bundle: `{"config": {"blocks": [{"type": "prompt", "alignment": "left", "segments": [
+				{"type": "text", "style": "plain", "template": "[{{ .Env.HOME }}]"}
+			]}]}, "bundle": {"plain": true}}`,
+			Expected: "[]",
+		},
+		{
+			Case:   "Data file without a config",
+			Bundle: `{"version": 1, "env": {}, "segments": {}}`,
+			Error:  "not a debug bundle: it holds no config, record one with oh-my-posh debug record",
+		},
+	}
+
+	for _, tc := range cases {
+		output, _, err := replayBundle([]byte(tc.Bundle))
+		if len(tc.Error) != 0 {
+			assert.EqualError(t, err, tc.Error, tc.Case)
+			continue
+		}
+
+		require.NoError(t, err, tc.Case)
+		assert.Contains(t, output, tc.Expected, tc.Case)
+	}
+}
+
+func TestRecordBundleEnvironment(t *testing.T) {
+	t.Setenv("OMP_CACHE_DIR", t.TempDir())
+	t.Setenv("MY_TOKEN", "ghp_secret")
+	t.Setenv("USER", "jan")
+
+	source := filepath.Join(t.TempDir(), "bundle.omp.json")
+	require.NoError(t, os.WriteFile(source, []byte(`{"version": 4, "blocks": [{"type": "prompt", "alignment": "left", "segments": [
+		{"type": "text", "style": "plain", "template": "{{ .Env.MY_TOKEN }} {{ .Env.USER }}"}]}]}`), 0o644))
+
+	original := configFlag
+	t.Cleanup(func() { configFlag = original })
+	configFlag = source
+
+	raw, err := recordBundle()
+	require.NoError(t, err)
+
+	var root struct {
+		Bundle debugBundle `json:"bundle"`
+	}
+
+	require.NoError(t, json.Unmarshal(raw, &root))
+
+	assert.Equal(t, "", root.Bundle.Environment["MY_TOKEN"])
+	assert.Equal(t, sanitizedUser, root.Bundle.Environment["USER"])
+	assert.NotContains(t, string(raw), "ghp_secret")
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
@@ -39,6 +40,10 @@ Prints one font name per line, so it can be searched or piped:
   oh-my-posh font list | grep -i mono`,
 		Args: cmdtree.NoArgs,
 		Run: func(_ *cmdtree.Command, _ []string) {
+			// keep the list around for shell completion
+			cache.Init(os.Getenv("POSH_SHELL"), cache.Persist)
+			defer cache.Close()
+
 			fonts, err := font.List()
 			if err != nil {
 				log.Error(err)
@@ -106,6 +111,7 @@ Takes a font name from ` + "`oh-my-posh font list`" + `, a URL, or the path to a
 
 func init() {
 	fontInstallCmd.Flags().StringVar(&zipFolder, "zip-folder", "", "the folder inside the zip file to install fonts from")
+	fontInstallCmd.ValidArgsFunction = completeFonts
 
 	fontCmd.AddCommand(fontListCmd)
 	fontCmd.AddCommand(fontInstallCmd)
@@ -13,6 +13,11 @@ func List() ([]*Asset, error) {
 	return fonts()
 }
 
+// Cached returns the font list cached by an earlier List or install, without going to the network.
+func Cached() ([]*Asset, error) {
+	return getCachedFontData()
+}
+
 // Install downloads and installs one font by name, URL, or local zip path, reporting each step on
 // a status line.
 //
@@ -1,18 +1,22 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
 	"github.com/jandedobbeleer/oh-my-posh/src/color"
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
 	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
 
 	color2 "github.com/gookit/color"
 	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
 )
 
+var getJSON bool
+
 var getCmd = &cmdtree.Command{
 	Use:   "get [shell|millis|accent|toggles|width]",
 	Short: "Get a value from oh-my-posh",
@@ -84,16 +88,7 @@ This command is used to get the value of the following variables:
 
 		switch args[0] {
 		case "toggles":
-			togglesMap, _ := cache.Get[map[string]bool](cache.Session, cache.TOGGLECACHE)
-			if len(togglesMap) == 0 {
-				fmt.Println("No segments are toggled off")
-				return
-			}
-
-			fmt.Println("Toggled off segments:")
-			for toggle := range togglesMap {
-				fmt.Println("- " + toggle)
-			}
+			printToggles(config.Toggles(time.Now()))
 		case cache.TTL:
 			fmt.Print(cache.GetTTL())
 		default:
@@ -102,6 +97,42 @@ This command is used to get the value of the following variables:
 	},
 }
 
+func printToggles(toggles []*config.Toggle) {
+	if getJSON {
+		if toggles == nil {
+			toggles = []*config.Toggle{}
+		}
+
+		data, _ := json.MarshalIndent(toggles, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(toggles) == 0 {
+		fmt.Println("No segments are toggled off")
+		return
+	}
+
+	fmt.Println("Toggled off segments:")
+	for _, toggle := range toggles {
+		fmt.Println("- " + formatToggle(toggle))
+	}
+}
+
+func formatToggle(toggle *config.Toggle) string {
+	scope := string(toggle.Scope)
+	if toggle.Scope == config.FolderScope {
+		scope += " " + toggle.Folder
+	}
+
+	if !toggle.Expires.IsZero() {
+		scope += ", until " + toggle.Expires.Local().Format(time.DateTime)
+	}
+
+	return fmt.Sprintf("%s (%s)", toggle.Segment, scope)
+}
+
 func init() {
+	getCmd.Flags().BoolVar(&getJSON, "json", false, "print the toggles as JSON")
 	RootCmd.AddCommand(getCmd)
 }
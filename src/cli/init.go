@@ -105,6 +105,8 @@ func runInit(sh, command string) {
 	env.Init(flags)
 
 	template.Init(env, cfg.Var, cfg.Maps)
+	template.SetCapabilities(cfg.TemplateCapabilities)
+	template.SetPartials(cfg.Partials)
 
 	defer func() {
 		cfg.Store()
@@ -117,6 +119,16 @@ func runInit(sh, command string) {
 
 	feats := cfg.Features(env)
 
+	if cfg.InfoPanel != nil {
+		shell.InfoPanelKey = cfg.InfoPanel.Key
+	}
+
+	shell.Bindings = cfg.KeyBindings
+
+	// the prompt itself never waits for the terminal to answer,
+	// so probing happens once here and the result is cached
+	cfg.DetectTerminal()
+
 	var output string
 
 	switch {
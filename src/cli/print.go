@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
@@ -32,8 +33,12 @@ var (
 	column       int
 	escape       bool
 	interrupted  bool
+	printFormat  string
+	keyChord     string
 )
 
+const jsonFormat = "json"
+
 var printCmd = createPrintCmd()
 
 func init() {
@@ -42,7 +47,7 @@ func init() {
 
 func createPrintCmd() *cmdtree.Command {
 	printCmd := &cmdtree.Command{
-		Use:   "print [debug|primary|secondary|transient|transient-right|right|tooltip|valid|error|preview|cursor]",
+		Use:   "print [debug|primary|secondary|transient|transient-right|finished|right|tooltip|info|valid|error|preview|cursor|key]",
 		Short: "Print the prompt/context",
 		Long:  "Print one of the prompts based on the location/use-case.",
 		ValidArgs: []string{
@@ -51,12 +56,15 @@ func createPrintCmd() *cmdtree.Command {
 			prompt.SECONDARY,
 			prompt.TRANSIENT,
 			prompt.TRANSIENT_RIGHT,
+			prompt.FINISHED,
 			prompt.RIGHT,
 			prompt.TOOLTIP,
+			prompt.INFO,
 			prompt.VALID,
 			prompt.ERROR,
 			prompt.PREVIEW,
 			prompt.CURSOR,
+			prompt.KEY,
 		},
 		Args: NoArgsOrOneValidArg,
 		Run: func(cmd *cmdtree.Command, args []string) {
@@ -93,6 +101,19 @@ func createPrintCmd() *cmdtree.Command {
 				Interrupted:   interrupted,
 			}
 
+			switch printFormat {
+			case "":
+			case jsonFormat:
+				// template timings need the profiled func maps from the very first render
+				template.EnableProfiling()
+				flags.Profile = true
+			default:
+				// usage error
+				fmt.Printf("print format must be %s\n", jsonFormat)
+				exitcode = 2
+				return
+			}
+
 			if err := applyDataFile(flags, cmd.Flags().Changed); err != nil {
 				exitcode = 666
 				fmt.Println(err.Error())
@@ -113,32 +134,55 @@ func createPrintCmd() *cmdtree.Command {
 				cache.Close()
 			}()
 
+			var output string
+
 			switch args[0] {
 			case prompt.DEBUG:
-				fmt.Print(eng.ExtraPrompt(prompt.Debug))
+				output = eng.ExtraPrompt(prompt.Debug)
 			case prompt.PRIMARY:
-				fmt.Print(eng.Primary())
+				output = eng.Primary()
 			case prompt.SECONDARY:
-				fmt.Print(eng.ExtraPrompt(prompt.Secondary))
+				output = eng.ExtraPrompt(prompt.Secondary)
 			case prompt.TRANSIENT:
-				fmt.Print(eng.ExtraPrompt(prompt.Transient))
+				output = eng.ExtraPrompt(prompt.Transient)
 			case prompt.TRANSIENT_RIGHT:
-				fmt.Print(eng.TransientRPrompt())
+				output = eng.TransientRPrompt()
+			case prompt.FINISHED:
+				output = eng.Finished()
 			case prompt.RIGHT:
-				fmt.Print(eng.RPrompt())
+				output = eng.RPrompt()
 			case prompt.TOOLTIP:
-				fmt.Print(eng.Tooltip(command))
+				output = eng.Tooltip(command)
+			case prompt.INFO:
+				output = eng.InfoPanel(command)
 			case prompt.VALID:
-				fmt.Print(eng.ExtraPrompt(prompt.Valid))
+				output = eng.ExtraPrompt(prompt.Valid)
 			case prompt.ERROR:
-				fmt.Print(eng.ExtraPrompt(prompt.Error))
+				output = eng.ExtraPrompt(prompt.Error)
 			case prompt.PREVIEW:
-				fmt.Print(eng.Preview())
+				output = eng.Preview()
 			case prompt.CURSOR:
-				fmt.Print(eng.CursorStyle())
+				output = eng.CursorStyle()
+			case prompt.KEY:
+				output = eng.KeyBinding(keyChord)
 			default:
 				_ = cmd.Help()
+				return
+			}
+
+			if printFormat != jsonFormat {
+				fmt.Print(output)
+				return
+			}
+
+			profile, err := json.MarshalIndent(eng.Profile(output), "", "  ")
+			if err != nil {
+				exitcode = 666
+				fmt.Println(err.Error())
+				return
 			}
+
+			fmt.Println(string(profile))
 		},
 	}
 
@@ -152,7 +196,7 @@ func createPrintCmd() *cmdtree.Command {
 	printCmd.Flags().Float64Var(&timing, "execution-time", 0, "timing of the last command")
 	printCmd.Flags().IntVarP(&stackCount, "stack-count", "s", 0, "number of locations on the stack")
 	printCmd.Flags().IntVarP(&terminalWidth, "terminal-width", "w", 0, "width of the terminal")
-	printCmd.Flags().StringVar(&command, "command", "", "tooltip command")
+	printCmd.Flags().StringVar(&command, "command", "", "tooltip or info panel command line")
 	printCmd.Flags().BoolVar(&cleared, "cleared", false, "do we have a clear terminal or not")
 	printCmd.Flags().BoolVar(&eval, "eval", false, "output the prompt for eval")
 	printCmd.Flags().IntVar(&column, "column", 0, "the column position of the cursor")
@@ -162,6 +206,8 @@ func createPrintCmd() *cmdtree.Command {
 	printCmd.Flags().BoolVarP(&force, "force", "f", false, "force rendering the segments")
 	printCmd.Flags().StringVar(&dataPath, "data", "", "path to a template data file (json/yaml/toml) to render with")
 	printCmd.Flags().BoolVar(&interrupted, "interrupted", false, "the command was interrupted")
+	printCmd.Flags().StringVar(&keyChord, "key", "", "key chord of the key binding to render")
+	printCmd.Flags().StringVar(&printFormat, "format", "", "output format, json includes segment and template timings")
 
 	// Hide flags that are for internal use only.
 	_ = printCmd.Flags().MarkHidden("save-cache")
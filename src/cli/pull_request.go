@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/segments"
+)
+
+var pullRequestQuery segments.PullRequestQuery
+
+// The git segment starts this in the background when its cached pull request is outdated.
+var pullRequestCmd = &cmdtree.Command{
+	Use:    "pull-request",
+	Short:  "Refresh the cached pull request of a branch",
+	Hidden: true,
+	Args:   cmdtree.NoArgs,
+	Run: func(_ *cmdtree.Command, _ []string) {
+		env := &runtime.Terminal{}
+		env.Init(&runtime.Flags{
+			Shell: os.Getenv("POSH_SHELL"),
+		})
+
+		cache.Init(env.Shell(), cache.Persist, cache.NoSession)
+
+		defer func() {
+			cache.Close()
+		}()
+
+		if err := segments.RefreshPullRequest(env, &pullRequestQuery); err != nil {
+			log.Error(err)
+			exitcode = 1
+		}
+	},
+}
+
+func init() {
+	flags := pullRequestCmd.Flags()
+	flags.StringVar(&pullRequestQuery.Forge, "forge", "", "forge kind: github, gitlab or gitea")
+	flags.StringVar(&pullRequestQuery.API, "api", "", "base URL of the forge API")
+	flags.StringVar(&pullRequestQuery.Host, "host", "", "host of the repository")
+	flags.StringVar(&pullRequestQuery.Repo, "repo", "", "path of the repository on the forge")
+	flags.StringVar(&pullRequestQuery.Branch, "branch", "", "branch on the remote")
+	flags.IntVar(&pullRequestQuery.Timeout, "timeout", 5000, "timeout of a single request in milliseconds")
+	flags.BoolVar(&pullRequestQuery.Trusted, "trusted", false, "send the forge token from the environment")
+	RootCmd.AddCommand(pullRequestCmd)
+}
@@ -53,20 +53,28 @@ on getting started, have a look at the docs at https://ohmyposh.dev`,
 			configFlag = configEnv
 		}
 
-		traceEnv := os.Getenv("POSH_TRACE")
-		if traceEnv == "" && !trace {
-			return
+		err := configureLog()
+
+		if os.Getenv("POSH_TRACE") != "" || trace {
+			trace = true
+			log.Enable(true)
 		}
 
-		trace = true
+		if !log.Enabled() {
+			return
+		}
 
-		log.Enable(true)
+		if err != nil {
+			log.Error(err)
+		}
 
 		log.Debug("version:", build.Version)
 		log.Debug("command:", getFullCommand(cmd, args))
 	},
 	PersistentPostRun: func(cmd *cmdtree.Command, args []string) {
 		defer func() {
+			_ = log.Close()
+
 			if exitcode != 0 {
 				os.Exit(exitcode)
 			}
@@ -98,6 +106,23 @@ on getting started, have a look at the docs at https://ohmyposh.dev`,
 	},
 }
 
+// configureLog applies the OMP_LOG_* variables. A broken setting is logged
+// rather than failing the command, the prompt must render regardless.
+func configureLog() error {
+	settings := log.Settings{
+		File:       os.Getenv("OMP_LOG_FILE"),
+		Level:      os.Getenv("OMP_LOG_LEVEL"),
+		Format:     os.Getenv("OMP_LOG_FORMAT"),
+		Subsystems: os.Getenv("OMP_LOG_SUBSYSTEMS"),
+	}
+
+	if len(settings.File) != 0 {
+		settings.File = cleanOutputPath(settings.File)
+	}
+
+	return log.Configure(settings)
+}
+
 func Execute() {
 	// The Explorer-launch guard walks the full Windows process table on
 	// every invocation to detect a double-click launch from Explorer,
@@ -127,6 +152,5 @@ func init() {
 	// Hide flags that are deprecated or for internal use only.
 	_ = RootCmd.PersistentFlags().MarkHidden("silent")
 
-	// Disable completions
-	RootCmd.CompletionOptions.DisableDefaultCmd = true
+	_ = RootCmd.RegisterFlagCompletionFunc("config", completeThemes)
 }
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/config"
 )
@@ -16,6 +17,9 @@ const (
 	// sanitizedGUID replaces az subscription/tenant IDs, which are otherwise
 	// real GUIDs identifying the recording machine's Azure account.
 	sanitizedGUID = "00000000-0000-0000-0000-000000000000"
+
+	sanitizedUser = "alice"
+	sanitizedHost = "contoso-devbox"
 )
 
 // sanitizeEnv overwrites the identity-carrying keys of a recorded env section in
@@ -40,8 +44,8 @@ func sanitizeEnv(env map[string]json.RawMessage) {
 		env[key] = raw
 	}
 
-	set("UserName", "alice")
-	set("HostName", "contoso-devbox")
+	set("UserName", sanitizedUser)
+	set("HostName", sanitizedHost)
 	set("PWD", sanitizedPath)
 	set("PSWD", sanitizedPath)
 	set("Folder", "oh-my-posh")
@@ -50,10 +54,15 @@ func sanitizeEnv(env map[string]json.RawMessage) {
 
 // sanitizeSegmentText clears Segment.Text - the fully rendered string every
 // writer embeds via segments.Base (e.g. " jande@surface-pro " for a session
-// segment) - on any writer shaped generic map. Safe to zero unconditionally:
+// segment) - on any writer shaped generic map, together with the recorded
+// result of Base.Text() that returns it. Safe to zero unconditionally:
 // Render recomputes it from the segment's template on every prompt, so a
 // replayed fixture never reads the stale value back.
 func sanitizeSegmentText(data map[string]any) {
+	if _, ok := data["Text"].(string); ok {
+		data["Text"] = ""
+	}
+
 	segment, ok := data["Segment"].(map[string]any)
 	if !ok {
 		return
@@ -274,3 +283,82 @@ func sanitizeDataDocument(doc []byte, cfg *config.Config) ([]byte, error) {
 
 	return json.MarshalIndent(root, "", "  ")
 }
+
+// sanitizedSSH replaces the client and server addresses SSH_CONNECTION and
+// SSH_CLIENT carry with documentation-only addresses (RFC 5737). A template
+// only ever checks whether they are set.
+const sanitizedSSH = "192.0.2.1 50000 192.0.2.2 22"
+
+// sanitizeEnvironment scrubs the OS variables a debug bundle recorded in
+// place: credentials are blanked like secret segment options, the user and
+// host names get the same synthetic identity as sanitizeEnv, SSH addresses
+// are replaced outright and the home folder is folded into "~", the same way
+// the template cache already shortens PWD.
+func sanitizeEnvironment(environment map[string]string, home string) {
+	for key, value := range environment {
+		switch {
+		case isSecretName(key):
+			environment[key] = ""
+		case key == "USER" || key == "USERNAME" || key == "LOGNAME":
+			environment[key] = sanitizedUser
+		case key == "HOSTNAME" || key == "COMPUTERNAME":
+			environment[key] = sanitizedHost
+		case key == "SSH_CONNECTION" || key == "SSH_CLIENT":
+			environment[key] = sanitizedSSH
+		case home != "" && strings.Contains(value, home):
+			environment[key] = strings.ReplaceAll(value, home, "~")
+		}
+	}
+}
+
+// secretOptions are the segment option names that hold credentials (api_key,
+// access_token, refresh_token, ...). A replayed bundle never fetches anything,
+// so blanking them costs the replay nothing.
+var secretOptions = []string{"key", "token", "secret", "password"}
+
+// sanitizeConfigSecrets walks an exported config and blanks every string
+// segment option whose name is, or ends in, one of secretOptions. Only
+// options are touched: a tooltip's or key binding's "key" is a keystroke.
+func sanitizeConfigSecrets(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			if options, ok := nested.(map[string]any); ok && key == "options" {
+				sanitizeOptions(options)
+				continue
+			}
+
+			sanitizeConfigSecrets(nested)
+		}
+	case []any:
+		for _, nested := range v {
+			sanitizeConfigSecrets(nested)
+		}
+	}
+}
+
+func sanitizeOptions(options map[string]any) {
+	for name, value := range options {
+		if _, isString := value.(string); !isString {
+			continue
+		}
+
+		if isSecretName(name) {
+			options[name] = ""
+		}
+	}
+}
+
+// isSecretName tells whether an option or variable name is, or ends in, one of
+// secretOptions, like api_key or GITHUB_TOKEN.
+func isSecretName(name string) bool {
+	lower := strings.ToLower(name)
+
+	for _, secret := range secretOptions {
+		if lower == secret || strings.HasSuffix(lower, "_"+secret) {
+			return true
+		}
+	}
+
+	return false
+}
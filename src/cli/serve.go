@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
 	"github.com/jandedobbeleer/oh-my-posh/src/prompt"
 	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
 	"github.com/jandedobbeleer/oh-my-posh/src/shell"
@@ -41,6 +43,7 @@ type serveRequest struct {
 	StackCount    int               `json:"stack-count"`
 	TerminalWidth int               `json:"terminal-width"`
 	JobCount      int               `json:"job-count"`
+	Line          string            `json:"line"`
 	ExecutionTime float64           `json:"execution-time"`
 	NoStatus      bool              `json:"no-status"`
 	Cleared       bool              `json:"cleared"`
@@ -60,8 +63,15 @@ const (
 	serveCommandAbort = "abort"
 	// serveCommandQuit asks the daemon to flush caches and exit cleanly.
 	serveCommandQuit = "quit"
+	// serveCommandInfo asks the daemon to render the info panel for the
+	// command line in the request's line field.
+	serveCommandInfo = "info"
 )
 
+// slowServeCycle is how long a cycle may take to produce its first record
+// before it's logged as a warning, the moment the shell shows a prompt.
+const slowServeCycle = 500 * time.Millisecond
+
 // serveIDMarker separates the cycle id prefix from the record payload on
 // stdout: "<id>\x1f<payload>\x00". \x1f is the ASCII unit separator.
 const serveIDMarker = "\x1f"
@@ -204,12 +214,15 @@ func runServeLoop(in, out *os.File) bool {
 		var req serveRequest
 		if err := json.Unmarshal(line, &req); err != nil {
 			// Malformed line: ignore for forward/backward compatibility.
+			log.Debugf("ignoring malformed request: %v", err)
 			continue
 		}
 
 		switch req.Command {
-		case serveCommandRender:
+		case serveCommandRender, serveCommandInfo:
 			// A new render request implicitly aborts whatever is running.
+			// For the info panel, that only costs the late updates of a
+			// primary prompt the user is already typing after.
 			stopActiveCycle()
 			// A nil cycle means setup panicked before prompt.New completed -
 			// template.Init may never have run, in which case the shutdown
@@ -221,6 +234,7 @@ func runServeLoop(in, out *os.File) bool {
 		case serveCommandAbort:
 			stopActiveCycle()
 		case serveCommandQuit:
+			log.Info("quit requested, shutting down")
 			stopActiveCycle()
 			return renderedAtLeastOnce
 		default:
@@ -230,6 +244,7 @@ func runServeLoop(in, out *os.File) bool {
 
 	// EOF (or a scanner error) on stdin: behave like an explicit quit so
 	// caches are still flushed by the caller's deferred cleanup.
+	log.Info("input closed, shutting down")
 	stopActiveCycle()
 
 	return renderedAtLeastOnce
@@ -262,10 +277,15 @@ func applyEnvOverlay(env map[string]string, keys map[string]struct{}) {
 func startRenderCycle(req *serveRequest, out *os.File, envKeys map[string]struct{}) (cycle *serveActiveCycle) {
 	defer func() {
 		if r := recover(); r != nil {
+			log.Errorf("cycle %d: failed to start: %v", req.ID, r)
 			cycle = nil
 		}
 	}()
 
+	start := time.Now()
+
+	log.Infof("cycle %d: %s request (wait: %t)", req.ID, req.Command, req.Wait)
+
 	// The daemon keeps its caches in memory for its whole lifetime (see the
 	// comment on copyRecords below) and only reads the on-disk files once,
 	// at startup. Refresh picks up writes from other processes that landed
@@ -329,18 +349,28 @@ func startRenderCycle(req *serveRequest, out *os.File, envKeys map[string]struct
 		Streaming:     !req.Wait,
 	}
 
+	if req.Command == serveCommandInfo {
+		flags.Type = prompt.INFO
+		flags.IsPrimary = false
+		flags.Escape = false
+		flags.Streaming = false
+	}
+
 	eng := prompt.New(flags)
 
 	var records <-chan string
-	if req.Wait {
+	switch {
+	case req.Command == serveCommandInfo:
+		records = renderInfoPanel(eng, req.Line)
+	case req.Wait:
 		records = renderComplete(eng)
-	} else {
+	default:
 		records = eng.StreamPrimary()
 	}
 
 	return &serveActiveCycle{
 		engine:     eng,
-		copierDone: copyRecords(req.ID, records, out),
+		copierDone: copyRecords(req.ID, start, records, out),
 	}
 }
 
@@ -384,15 +414,47 @@ func renderComplete(eng *prompt.Engine) <-chan string {
 	return records
 }
 
+// renderInfoPanel produces the single record of an info request: the info
+// panel prefixed with prompt.InfoPanelMarker. The marker is sent on its own
+// when no tooltip matches or the render panics, so the client never waits for
+// a record that does not come.
+func renderInfoPanel(eng *prompt.Engine, line string) <-chan string {
+	records := make(chan string, 1)
+
+	go func() {
+		defer close(records)
+
+		panel := ""
+		defer func() {
+			_ = recover()
+			records <- prompt.InfoPanelMarker + panel
+		}()
+
+		panel = eng.InfoPanel(line)
+	}()
+
+	return records
+}
+
 // copyRecords copies prompt records to out prefixed with the cycle id and
-// closes the returned channel once the source channel is exhausted.
-func copyRecords(id int64, records <-chan string, out *os.File) chan struct{} {
+// closes the returned channel once the source channel is exhausted. The
+// timings it logs count from start, when the request came in.
+func copyRecords(id int64, start time.Time, records <-chan string, out *os.File) chan struct{} {
 	done := make(chan struct{})
 
 	go func() {
 		defer close(done)
 
+		var first time.Duration
+		count := 0
+
 		for record := range records {
+			if count == 0 {
+				first = time.Since(start)
+			}
+
+			count++
+
 			// Single Fprintf call so each record is written to stdout
 			// atomically with respect to other os.Stdout writers in this
 			// process (there are none today, but keep the invariant).
@@ -404,6 +466,12 @@ func copyRecords(id int64, records <-chan string, out *os.File) chan struct{} {
 			fmt.Fprintf(out, "%d%s%s\x00", id, serveIDMarker, record)
 		}
 
+		if first > slowServeCycle {
+			log.Warnf("cycle %d: slow first record after %s", id, first)
+		}
+
+		log.Infof("cycle %d: %d records, first after %s, done after %s", id, count, first, time.Since(start))
+
 		// Deliberately no cache persistence here: unlike stream/print
 		// (--save-cache on every prompt), serve keeps the segment and
 		// template caches in memory for the daemon's lifetime - that's the
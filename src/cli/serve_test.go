@@ -11,6 +11,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
 	"github.com/jandedobbeleer/oh-my-posh/src/prompt"
 
 	"github.com/stretchr/testify/assert"
@@ -477,6 +479,38 @@ func TestServeLoop_UnknownCommandIgnored(t *testing.T) {
 	h.quitAndWait()
 }
 
+func TestServeLoop_InfoRendersMatchingTooltips(t *testing.T) {
+	h := startServeHarness(t)
+	pwd := t.TempDir()
+
+	configPath := filepath.Join(t.TempDir(), "info.omp.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"version": 4,
+		"blocks": [{"type": "prompt", "alignment": "left", "segments": [{"type": "text", "template": "prompt"}]}],
+		"tooltips": [{"type": "text", "template": "upstream", "tips": ["git push"]}]
+	}`), 0o644))
+
+	// the daemon renders with the session's config, like after init
+	source, _ := cache.Get[string](cache.Session, config.SourceKey)
+	config.Load(configPath).Store()
+	t.Cleanup(func() { config.Load(source).Store() })
+
+	chdirBackToWD(t)
+
+	h.send(map[string]any{"command": "info", "id": 1, "shell": "zsh", "pwd": pwd, "line": "git push origin"})
+	records := h.recordsFor("1", 200*time.Millisecond)
+	require.Len(t, records, 1, "an info request emits a single record")
+	assert.Equal(t, prompt.InfoPanelMarker, records[0].payload[:1])
+	assert.Contains(t, records[0].payload, "upstream")
+
+	h.send(map[string]any{"command": "info", "id": 2, "shell": "zsh", "pwd": pwd, "line": "git pull"})
+	records = h.recordsFor("2", 200*time.Millisecond)
+	require.Len(t, records, 1, "an info request without a match still answers")
+	assert.Equal(t, prompt.InfoPanelMarker, records[0].payload)
+
+	h.quitAndWait()
+}
+
 // TestServeLoop_UTF8BOMOnFirstLine validates that a UTF-8 BOM prefixing the
 // very first request line (written by .NET's default UTF8 StreamWriter
 // encoding on its first write) does not make the daemon drop the request.
@@ -61,6 +61,8 @@ func statuslineRun[T any](shellConst, cacheKey string, sessionID func(*T) string
 		}
 
 		template.Init(env, cfg.Var, cfg.Maps)
+		template.SetCapabilities(cfg.TemplateCapabilities)
+		template.SetPartials(cfg.Partials)
 		terminal.Init(shellConst)
 		terminal.BackgroundColor = cfg.TerminalBackground.ResolveTemplate()
 		terminal.Colors = cfg.MakeColors(env)
@@ -1,25 +1,62 @@
 package cli
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
 	"github.com/jandedobbeleer/oh-my-posh/src/cmdtree"
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
 	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
 )
 
+var (
+	toggleScope    string
+	toggleDuration string
+)
+
 var toggleCmd = &cmdtree.Command{
 	Use:   "toggle segment1 segment2 ...",
 	Short: "Toggle one or more segments on/off",
-	Long:  "Toggle one or more segments on/off on the fly. Multiple segments can be specified separated by spaces.",
-	Args:  cmdtree.MinimumNArgs(1),
+	Long: `Toggle one or more segments on/off on the fly. Multiple segments can be specified separated by spaces.
+
+The scope defines where a segment is toggled:
+
+- session: the current shell session (default)
+- folder: the current directory and its subfolders, in every session
+- global: everywhere, in every session
+
+Use --for to toggle a segment off for a limited amount of time, like --for 1h.`,
+	Args: cmdtree.MinimumNArgs(1),
 	Run: func(cmd *cmdtree.Command, args []string) {
 		if len(args) == 0 {
 			_ = cmd.Help()
 			return
 		}
 
+		scope := config.ToggleScope(toggleScope)
+		switch scope {
+		case config.SessionScope, config.FolderScope, config.GlobalScope:
+		default:
+			// usage error
+			fmt.Printf("scope must be one of %s, %s or %s\n", config.SessionScope, config.FolderScope, config.GlobalScope)
+			exitcode = 2
+			return
+		}
+
+		var duration time.Duration
+		if len(toggleDuration) != 0 {
+			var err error
+			duration, err = time.ParseDuration(toggleDuration)
+			if err != nil || duration <= 0 {
+				fmt.Printf("invalid duration %q, use a positive duration like 30m or 1h\n", toggleDuration)
+				exitcode = 2
+				return
+			}
+		}
+
 		env := &runtime.Terminal{}
 		env.Init(&runtime.Flags{})
 
@@ -29,26 +66,7 @@ var toggleCmd = &cmdtree.Command{
 			cache.Close()
 		}()
 
-		// Get current toggles from cache as a map
-		currentToggleSet, _ := cache.Get[map[string]bool](cache.Session, cache.TOGGLECACHE)
-		if currentToggleSet == nil {
-			currentToggleSet = make(map[string]bool)
-		}
-
-		segmentsToToggle := parseSegments(args)
-
-		// Toggle segments: remove if present, add if not present
-		for _, segment := range segmentsToToggle {
-			if currentToggleSet[segment] {
-				delete(currentToggleSet, segment)
-				continue
-			}
-
-			currentToggleSet[segment] = true
-		}
-
-		// Store the map directly in cache
-		cache.Set(cache.Session, cache.TOGGLECACHE, currentToggleSet, cache.INFINITE)
+		config.ToggleSegments(parseSegments(args), scope, env.Pwd(), duration)
 	},
 }
 
@@ -64,5 +82,9 @@ func parseSegments(args []string) []string {
 }
 
 func init() {
+	toggleCmd.Flags().StringVar(&toggleScope, "scope", string(config.SessionScope), "where to toggle the segments: session, folder or global")
+	toggleCmd.Flags().StringVar(&toggleDuration, "for", "", "only toggle the segments off for this amount of time, like 1h")
+	toggleCmd.ValidArgsFunction = completeSegments
+	_ = toggleCmd.RegisterFlagCompletionFunc("scope", completeValues(string(config.SessionScope), string(config.FolderScope), string(config.GlobalScope)))
 	RootCmd.AddCommand(toggleCmd)
 }
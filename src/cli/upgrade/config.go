@@ -19,9 +19,15 @@ func init() {
 }
 
 type Config struct {
+	release       *Release
 	Source        Source         `json:"source" toml:"source" yaml:"source"`
 	Interval      cache.Duration `json:"interval" toml:"interval" yaml:"interval"`
 	Latest        string         `json:"-" toml:"-" yaml:"-"`
+	URL           string         `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	PublicKey     string         `json:"public_key,omitempty" toml:"public_key,omitempty" yaml:"public_key,omitempty"`
+	Channel       Channel        `json:"channel,omitempty" toml:"channel,omitempty" yaml:"channel,omitempty"`
+	Pin           string         `json:"pin,omitempty" toml:"pin,omitempty" yaml:"pin,omitempty"`
+	MaxVersion    string         `json:"max_version,omitempty" toml:"max_version,omitempty" yaml:"max_version,omitempty"`
 	Auto          bool           `json:"auto" toml:"auto" yaml:"auto"`
 	DisplayNotice bool           `json:"notice" toml:"notice" yaml:"notice"`
 	Force         bool           `json:"-" toml:"-" yaml:"-"`
@@ -32,6 +38,8 @@ type Source string
 const (
 	GitHub Source = "github"
 	CDN    Source = "cdn"
+	// Custom is a self-hosted source, serving a signed manifest.json at the config's url
+	Custom Source = "custom"
 )
 
 func (s Source) String() string {
@@ -40,12 +48,18 @@ func (s Source) String() string {
 		return "github.com"
 	case CDN:
 		return "cdn.ohmyposh.dev"
+	case Custom:
+		return "custom source"
 	default:
 		return "Unknown"
 	}
 }
 
 func (cfg *Config) FetchLatest() (string, error) {
+	if cfg.Source == Custom {
+		return cfg.fetchCustomLatest()
+	}
+
 	cfg.Latest = "latest"
 	v, err := cfg.DownloadAsset("version.txt")
 	if err != nil {
@@ -80,6 +94,8 @@ func (cfg *Config) DownloadAsset(asset string) ([]byte, error) {
 		}
 
 		return cfg.Download(url)
+	case Custom:
+		return cfg.Download(cfg.resolve(asset))
 	case CDN:
 		fallthrough
 	default:
@@ -0,0 +1,287 @@
+package upgrade
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	stdruntime "runtime"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+// Channel selects the releases a custom source upgrades to.
+type Channel string
+
+const (
+	// Stable only upgrades to stable releases
+	Stable Channel = "stable"
+	// Beta upgrades to the latest release, stable or beta
+	Beta Channel = "beta"
+)
+
+// A custom source serves manifest.json at its url, together with manifest.json.sig: the ed25519
+// signature of the manifest, made with the private key matching the config's public_key. Signing
+// works the same as for checksums.txt, see verify.go.
+const (
+	manifestFile          = "manifest.json"
+	manifestSignatureFile = "manifest.json.sig"
+)
+
+// Manifest lists the releases a custom source offers.
+type Manifest struct {
+	Releases []*Release `json:"releases"`
+}
+
+// Release is a version with a binary for every platform it supports.
+// A release without a channel is a stable one.
+type Release struct {
+	Version string   `json:"version"`
+	Channel Channel  `json:"channel,omitempty"`
+	Assets  []*Asset `json:"assets"`
+}
+
+// Asset is the binary for a single platform. A relative URL is resolved against the
+// source's url. Signature is optional: the base64 encoded ed25519 signature of the binary.
+type Asset struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// Host is where releases are downloaded from, for display.
+func (cfg *Config) Host() string {
+	if cfg.Source != Custom {
+		return cfg.Source.String()
+	}
+
+	if base, err := url.Parse(cfg.URL); err == nil && len(base.Host) != 0 {
+		return base.Host
+	}
+
+	return cfg.Source.String()
+}
+
+func (cfg *Config) fetchCustomLatest() (string, error) {
+	manifest, err := cfg.fetchManifest()
+	if err != nil {
+		return "", err
+	}
+
+	release, err := cfg.selectRelease(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	cfg.release = release
+	cfg.Latest = release.Version
+
+	version := strings.TrimPrefix(release.Version, "v")
+	log.Debugf("latest version: %s", version)
+
+	return version, nil
+}
+
+func (cfg *Config) fetchManifest() (*Manifest, error) {
+	if len(cfg.URL) == 0 {
+		return nil, errors.New("the custom upgrade source requires a url")
+	}
+
+	key, err := cfg.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := cfg.Download(cfg.resolve(manifestFile))
+	if err != nil {
+		log.Debug("failed to download manifest")
+		return nil, err
+	}
+
+	signature, err := cfg.Download(cfg.resolve(manifestSignatureFile))
+	if err != nil {
+		log.Debug("failed to download manifest signature")
+		return nil, err
+	}
+
+	if !ed25519.Verify(key, data, signature) {
+		return nil, errors.New("failed to verify manifest signature")
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+func (cfg *Config) publicKey() (ed25519.PublicKey, error) {
+	if len(cfg.PublicKey) == 0 {
+		return nil, errors.New("the custom upgrade source requires a public_key")
+	}
+
+	key, err := parsePublicKey([]byte(cfg.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return *key, nil
+}
+
+// selectRelease picks the highest version in the channel, up to MaxVersion.
+// A pinned version is picked regardless of its channel.
+func (cfg *Config) selectRelease(manifest *Manifest) (*Release, error) {
+	channel := cfg.Channel
+	if len(channel) == 0 {
+		channel = Stable
+	}
+
+	if channel != Stable && channel != Beta {
+		return nil, fmt.Errorf("unknown upgrade channel: %s", channel)
+	}
+
+	var pinned, maxVersion *semver.Version
+	var err error
+
+	if len(cfg.Pin) != 0 {
+		if pinned, err = semver.NewVersion(cfg.Pin); err != nil {
+			return nil, fmt.Errorf("invalid pin: %w", err)
+		}
+	}
+
+	if len(cfg.MaxVersion) != 0 {
+		if maxVersion, err = semver.NewVersion(cfg.MaxVersion); err != nil {
+			return nil, fmt.Errorf("invalid max_version: %w", err)
+		}
+	}
+
+	var selected *Release
+	var selectedVersion *semver.Version
+
+	for _, release := range manifest.Releases {
+		version, err := semver.NewVersion(release.Version)
+		if err != nil {
+			log.Debugf("skipping release with invalid version: %s", release.Version)
+			continue
+		}
+
+		if pinned != nil {
+			if version.Equal(pinned) {
+				return release, nil
+			}
+
+			continue
+		}
+
+		switch release.Channel {
+		case "", Stable:
+		case Beta:
+			if channel != Beta {
+				continue
+			}
+		default:
+			// a channel this version doesn't know, like nightly, is never picked
+			continue
+		}
+
+		if maxVersion != nil && version.GreaterThan(maxVersion) {
+			continue
+		}
+
+		if selected == nil || version.GreaterThan(selectedVersion) {
+			selected = release
+			selectedVersion = version
+		}
+	}
+
+	if pinned != nil {
+		return nil, fmt.Errorf("pinned version %s not found in manifest", cfg.Pin)
+	}
+
+	if selected == nil {
+		return nil, fmt.Errorf("no %s release found in manifest", channel)
+	}
+
+	return selected, nil
+}
+
+// downloadCustomAsset downloads the binary for this platform from the selected release and
+// verifies it against the checksum and signature the signed manifest holds for it.
+func (cfg *Config) downloadCustomAsset() ([]byte, error) {
+	if cfg.release == nil {
+		if _, err := cfg.FetchLatest(); err != nil {
+			return nil, err
+		}
+	}
+
+	var asset *Asset
+	for _, candidate := range cfg.release.Assets {
+		if candidate.OS == stdruntime.GOOS && candidate.Arch == stdruntime.GOARCH {
+			asset = candidate
+			break
+		}
+	}
+
+	if asset == nil {
+		return nil, fmt.Errorf("release %s has no asset for %s/%s", cfg.release.Version, stdruntime.GOOS, stdruntime.GOARCH)
+	}
+
+	log.Debug("downloading asset:", asset.URL)
+
+	data, err := cfg.Download(cfg.resolve(asset.URL))
+	if err != nil {
+		log.Debug("failed to download asset")
+		return nil, err
+	}
+
+	setState(StageVerifying)
+
+	checksum := fmt.Sprintf("%x", sha256.Sum256(data))
+	if !strings.EqualFold(checksum, asset.SHA256) {
+		log.Debugf("checksum mismatch, expected: %s, got: %s", asset.SHA256, checksum)
+		return nil, errors.New("checksum mismatch")
+	}
+
+	if len(asset.Signature) == 0 {
+		return data, nil
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset signature: %w", err)
+	}
+
+	key, err := cfg.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if !ed25519.Verify(key, data, signature) {
+		return nil, errors.New("failed to verify asset signature")
+	}
+
+	return data, nil
+}
+
+// resolve returns the URL of a file relative to the source's url.
+func (cfg *Config) resolve(file string) string {
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/") + "/")
+	if err != nil {
+		return file
+	}
+
+	reference, err := url.Parse(file)
+	if err != nil {
+		return file
+	}
+
+	return base.ResolveReference(reference).String()
+}
@@ -0,0 +1,206 @@
+package upgrade
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	stdruntime "runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type customSource struct {
+	files     map[string][]byte
+	publicKey string
+	private   ed25519.PrivateKey
+	binary    []byte
+}
+
+func newCustomSource(t *testing.T, releases []*Release) (*customSource, *httptest.Server) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+
+	source := &customSource{
+		files:     make(map[string][]byte),
+		publicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		private:   private,
+		binary:    []byte("oh-my-posh binary"),
+	}
+
+	source.files["/releases/bin/posh"] = source.binary
+	source.sign(t, &Manifest{Releases: releases})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, OK := source.files[r.URL.Path]
+		if !OK {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write(data)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return source, server
+}
+
+func (s *customSource) sign(t *testing.T, manifest *Manifest) {
+	t.Helper()
+
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	s.files["/releases/"+manifestFile] = data
+	s.files["/releases/"+manifestSignatureFile] = ed25519.Sign(s.private, data)
+}
+
+func (s *customSource) asset() *Asset {
+	return &Asset{
+		OS:        stdruntime.GOOS,
+		Arch:      stdruntime.GOARCH,
+		URL:       "bin/posh",
+		SHA256:    fmt.Sprintf("%x", sha256.Sum256(s.binary)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.private, s.binary)),
+	}
+}
+
+func TestCustomFetchLatest(t *testing.T) {
+	releases := []*Release{
+		{Version: "26.0.0"},
+		{Version: "26.2.0", Channel: Stable},
+		{Version: "27.0.0-beta.1", Channel: Beta},
+		{Version: "28.0.0", Channel: "nightly"},
+		{Version: "25.9.1", Channel: Stable},
+		{Version: "not a version"},
+	}
+
+	source, server := newCustomSource(t, releases)
+
+	cases := []struct {
+		Case        string
+		Channel     Channel
+		Pin         string
+		MaxVersion  string
+		Expected    string
+		Error       string
+		NoPublicKey bool
+	}{
+		{Case: "Stable by default", Expected: "26.2.0"},
+		{Case: "Beta includes stable releases", Channel: Beta, Expected: "27.0.0-beta.1"},
+		{Case: "Maximum version", MaxVersion: "26.1.0", Expected: "26.0.0"},
+		{Case: "Pinned beta on the stable channel", Pin: "27.0.0-beta.1", Expected: "27.0.0-beta.1"},
+		{Case: "Pinned version not in the manifest", Pin: "24.0.0", Error: "pinned version 24.0.0 not found in manifest"},
+		{Case: "Nothing below the maximum", MaxVersion: "1.0.0", Error: "no stable release found in manifest"},
+		{Case: "Unknown channel", Channel: "nightly", Error: "unknown upgrade channel: nightly"},
+		{Case: "No public key", NoPublicKey: true, Error: "the custom upgrade source requires a public_key"},
+	}
+
+	for _, tc := range cases {
+		cfg := &Config{
+			Source:     Custom,
+			URL:        server.URL + "/releases",
+			PublicKey:  source.publicKey,
+			Channel:    tc.Channel,
+			Pin:        tc.Pin,
+			MaxVersion: tc.MaxVersion,
+		}
+
+		if tc.NoPublicKey {
+			cfg.PublicKey = ""
+		}
+
+		latest, err := cfg.FetchLatest()
+		if len(tc.Error) != 0 {
+			assert.EqualError(t, err, tc.Error, tc.Case)
+			continue
+		}
+
+		require.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, latest, tc.Case)
+		assert.Equal(t, tc.Expected, cfg.Latest, tc.Case)
+	}
+}
+
+func TestCustomManifestSignature(t *testing.T) {
+	source, server := newCustomSource(t, []*Release{{Version: "26.2.0"}})
+
+	// a manifest changed after signing
+	source.files["/releases/"+manifestFile] = []byte(`{"releases": [{"version": "99.0.0"}]}`)
+
+	cfg := &Config{Source: Custom, URL: server.URL + "/releases/", PublicKey: source.publicKey}
+
+	_, err := cfg.FetchLatest()
+	assert.EqualError(t, err, "failed to verify manifest signature")
+}
+
+func TestCustomDownloadAndVerify(t *testing.T) {
+	cases := []struct {
+		Case   string
+		Modify func(source *customSource, asset *Asset)
+		Error  string
+	}{
+		{Case: "Valid asset"},
+		{
+			Case:   "Checksum mismatch",
+			Modify: func(source *customSource, _ *Asset) { source.files["/releases/bin/posh"] = []byte("tampered") },
+			Error:  "checksum mismatch",
+		},
+		{
+			Case: "Signature mismatch",
+			Modify: func(source *customSource, asset *Asset) {
+				asset.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(source.private, []byte("other")))
+			},
+			Error: "failed to verify asset signature",
+		},
+		{
+			Case:   "No asset for this platform",
+			Modify: func(_ *customSource, asset *Asset) { asset.OS = "plan9" },
+			Error:  fmt.Sprintf("release 26.2.0 has no asset for %s/%s", stdruntime.GOOS, stdruntime.GOARCH),
+		},
+	}
+
+	for _, tc := range cases {
+		source, server := newCustomSource(t, nil)
+
+		asset := source.asset()
+		if tc.Modify != nil {
+			tc.Modify(source, asset)
+		}
+
+		source.sign(t, &Manifest{Releases: []*Release{{Version: "26.2.0", Assets: []*Asset{asset}}}})
+
+		cfg := &Config{Source: Custom, URL: server.URL + "/releases", PublicKey: source.publicKey}
+
+		data, err := downloadAndVerify(cfg)
+		if len(tc.Error) != 0 {
+			assert.EqualError(t, err, tc.Error, tc.Case)
+			continue
+		}
+
+		require.NoError(t, err, tc.Case)
+		assert.Equal(t, source.binary, data, tc.Case)
+	}
+}
+
+func TestCustomHost(t *testing.T) {
+	cfg := &Config{Source: Custom, URL: "https://releases.example.com/omp"}
+	assert.Equal(t, "releases.example.com", cfg.Host())
+
+	cfg = &Config{Source: CDN}
+	assert.Equal(t, "cdn.ohmyposh.dev", cfg.Host())
+}
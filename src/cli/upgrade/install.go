@@ -2,11 +2,15 @@ package upgrade
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/log"
 )
@@ -52,11 +56,20 @@ func Install(cfg *Config) error {
 		return err
 	}
 
+	return swapExecutable(executable, newPath, cfg.Latest)
+}
+
+// swapExecutable moves the new binary in place of executable. The previous binary is
+// restored when the new one can't be moved in place, or doesn't start and report version.
+func swapExecutable(executable, newPath, version string) error {
+	targetDir := filepath.Dir(executable)
+	fileName := filepath.Base(executable)
+
 	oldPath := filepath.Join(targetDir, fmt.Sprintf(".%s.old", fileName))
 
 	_ = os.Remove(oldPath)
 
-	err = os.Rename(executable, oldPath)
+	err := os.Rename(executable, oldPath)
 	if err != nil {
 		log.Debug("failed to rename old file")
 		return err
@@ -76,6 +89,20 @@ func Install(cfg *Config) error {
 		return err
 	}
 
+	if err := checkExecutable(executable, version); err != nil {
+		log.Error(err)
+		log.Debug("new executable is broken, rolling back")
+
+		_ = os.Remove(executable)
+
+		if rerr := os.Rename(oldPath, executable); rerr != nil {
+			log.Debug("failed to rollback old file")
+			return errors.Join(err, rerr)
+		}
+
+		return err
+	}
+
 	removeErr := os.Remove(oldPath)
 
 	// hide the old executable if we can't remove it
@@ -87,3 +114,27 @@ func Install(cfg *Config) error {
 
 	return nil
 }
+
+// checkExecutable runs the installed binary to make sure it starts and reports the version
+// that was installed, a corrupt download or a binary for another platform fails here.
+func checkExecutable(executable, version string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, executable, "version").Output()
+	if err != nil {
+		return fmt.Errorf("the new executable failed to start: %w", err)
+	}
+
+	version = strings.TrimPrefix(version, "v")
+	if len(version) == 0 || version == "latest" {
+		return nil
+	}
+
+	installed := strings.TrimPrefix(strings.TrimSpace(string(output)), "v")
+	if installed != version {
+		return fmt.Errorf("the new executable reports version %s instead of %s", installed, version)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build !windows
+
+package upgrade
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapExecutable(t *testing.T) {
+	const current = "#!/bin/sh\necho 26.0.0\n"
+
+	cases := []struct {
+		Case     string
+		New      string
+		Version  string
+		Expected string
+		Error    string
+	}{
+		{Case: "Upgrade", New: "#!/bin/sh\necho 26.2.0\n", Version: "v26.2.0", Expected: "#!/bin/sh\necho 26.2.0\n"},
+		{
+			Case:     "Broken binary",
+			New:      "#!/bin/sh\nexit 1\n",
+			Version:  "26.2.0",
+			Expected: current,
+			Error:    "the new executable failed to start: exit status 1",
+		},
+		{
+			Case:     "Wrong version",
+			New:      "#!/bin/sh\necho 25.0.0\n",
+			Version:  "26.2.0",
+			Expected: current,
+			Error:    "the new executable reports version 25.0.0 instead of 26.2.0",
+		},
+	}
+
+	for _, tc := range cases {
+		dir := t.TempDir()
+		executable := filepath.Join(dir, "oh-my-posh")
+		newPath := filepath.Join(dir, ".oh-my-posh.new")
+
+		require.NoError(t, os.WriteFile(executable, []byte(current), 0o755))
+		require.NoError(t, os.WriteFile(newPath, []byte(tc.New), 0o755))
+
+		err := swapExecutable(executable, newPath, tc.Version)
+		if len(tc.Error) != 0 {
+			assert.EqualError(t, err, tc.Error, tc.Case)
+		} else {
+			assert.NoError(t, err, tc.Case)
+		}
+
+		content, err := os.ReadFile(executable)
+		require.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, string(content), tc.Case)
+		assert.NoFileExists(t, filepath.Join(dir, ".oh-my-posh.old"), tc.Case)
+	}
+}
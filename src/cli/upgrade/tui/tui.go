@@ -23,7 +23,7 @@ func stageMessage(cfg *upgrade.Config, stage upgrade.Stage) string {
 	case upgrade.StageValidating:
 		return "Validating current installation"
 	case upgrade.StageDownloading:
-		return fmt.Sprintf("Downloading %s from %s", cfg.Latest, cfg.Source.String())
+		return fmt.Sprintf("Downloading %s from %s", cfg.Latest, cfg.Host())
 	case upgrade.StageVerifying:
 		return "Verifying download"
 	case upgrade.StageInstalling:
@@ -34,6 +34,10 @@ import (
 var publicKey []byte
 
 func downloadAndVerify(cfg *Config) ([]byte, error) {
+	if cfg.Source == Custom {
+		return cfg.downloadCustomAsset()
+	}
+
 	extension := ""
 	if stdruntime.GOOS == runtime.WINDOWS {
 		extension = ".exe"
@@ -83,7 +87,7 @@ func verify(cfg *Config, asset string, binary []byte) error {
 }
 
 func validateSignature(data, signature []byte) bool {
-	ed25519PublicKey, err := loadPublicKey()
+	ed25519PublicKey, err := parsePublicKey(publicKey)
 	if err != nil {
 		log.Debug("failed to load public key")
 		log.Error(err)
@@ -93,8 +97,8 @@ func validateSignature(data, signature []byte) bool {
 	return ed25519.Verify(*ed25519PublicKey, data, signature)
 }
 
-func loadPublicKey() (*ed25519.PublicKey, error) {
-	block, _ := pem.Decode(publicKey)
+func parsePublicKey(key []byte) (*ed25519.PublicKey, error) {
+	block, _ := pem.Decode(key)
 	if block == nil {
 		log.Debug("failed to decode PEM block")
 		return nil, fmt.Errorf("error parsing PEM block: key not found")
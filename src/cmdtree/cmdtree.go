@@ -38,9 +38,11 @@ type Command struct {
 	Long              string
 	Short             string
 	Use               string
+	ValidArgsFunction CompletionFunc
 	ValidArgs         []string
 	commands          []*Command
 	requiredFlags     []string
+	flagCompletions   map[string]CompletionFunc
 	Aliases           []string
 	setArgs           []string
 	Hidden            bool
@@ -211,6 +213,7 @@ func (c *Command) Execute() error {
 	if c.parent == nil {
 		checkExplorerLaunch()
 		c.ensureHelpCommand()
+		c.ensureCompletionCommand()
 	}
 
 	args := c.setArgs
@@ -218,6 +221,11 @@ func (c *Command) Execute() error {
 		args = os.Args[1:]
 	}
 
+	if c.parent == nil && !c.CompletionOptions.DisableDefaultCmd && len(args) != 0 && args[0] == completeCmdName {
+		c.printCompletions(c.outWriter(), args[1:])
+		return nil
+	}
+
 	cmd, remaining, err := c.route(args)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -131,3 +131,77 @@ func TestShorthandShadowing(t *testing.T) {
 	assert.Equal(t, 3, stack)
 	assert.Equal(t, "", shell)
 }
+
+func TestComplete(t *testing.T) {
+	root, _ := testTree()
+
+	var config, format, output string
+	root.PersistentFlags().StringVarP(&config, "config", "c", "", "config file path")
+	_ = root.RegisterFlagCompletionFunc("config", func(_ *Command, _ []string, _ string) []string {
+		return []string{"agnoster", "atomic"}
+	})
+
+	export := &Command{Use: "export", Short: "Export your config", ValidArgs: []string{"json", "yaml"}}
+	export.Flags().StringVarP(&format, "format", "f", "", "config format")
+	export.Flags().StringVarP(&output, "output", "o", "", "output file")
+	_ = export.RegisterFlagCompletionFunc("format", func(_ *Command, _ []string, _ string) []string {
+		return []string{"json", "toml"}
+	})
+
+	var seen []string
+	toggle := &Command{
+		Use:    "toggle",
+		Hidden: true,
+		ValidArgsFunction: func(_ *Command, args []string, _ string) []string {
+			seen = args
+			return []string{"git\tgit", "path\tpath"}
+		},
+	}
+
+	root.AddCommand(export, toggle)
+
+	cases := []struct {
+		Case     string
+		Args     []string
+		Expected []string
+		Seen     []string
+	}{
+		{Case: "Commands", Args: []string{"ex"}, Expected: []string{"export\tExport your config"}},
+		{Case: "Hidden commands", Args: []string{"tog"}},
+		{Case: "Valid args", Args: []string{"export", ""}, Expected: []string{"json", "yaml"}},
+		{Case: "Flags", Args: []string{"export", "--"}, Expected: []string{"--config\tconfig file path", "--format\tconfig format", "--output\toutput file"}},
+		{Case: "Shorthands", Args: []string{"export", "-"}, Expected: []string{
+			"--config\tconfig file path", "--format\tconfig format", "--output\toutput file", "-c\tconfig file path", "-f\tconfig format", "-o\toutput file",
+		}},
+		{Case: "Flag value", Args: []string{"export", "--format", "t"}, Expected: []string{"toml"}},
+		{Case: "Shorthand flag value", Args: []string{"export", "-f", ""}, Expected: []string{"json", "toml"}},
+		{Case: "Inline flag value", Args: []string{"export", "--format=j"}, Expected: []string{"--format=json"}},
+		{Case: "Persistent flag value", Args: []string{"child", "--config", "AT"}, Expected: []string{"atomic"}},
+		{Case: "Flag without values", Args: []string{"export", "--output", ""}},
+		{Case: "Dynamic args", Args: []string{"toggle", "--config", "x", "git", "p"}, Expected: []string{"path\tpath"}, Seen: []string{"git"}},
+		{Case: "Unknown command", Args: []string{"bogus", ""}},
+	}
+
+	for _, tc := range cases {
+		seen = nil
+		assert.Equal(t, tc.Expected, root.Complete(tc.Args), tc.Case)
+		assert.Equal(t, tc.Seen, seen, tc.Case)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	root := &Command{Use: "oh-my-posh"}
+
+	for _, shell := range completionShells {
+		script, err := root.CompletionScript(shell)
+		assert.NoError(t, err, shell)
+		assert.Contains(t, script, "__complete", shell)
+		assert.NotContains(t, script, "{{", shell)
+	}
+
+	bash, _ := root.CompletionScript("bash")
+	assert.Contains(t, bash, "complete -o default -F _oh_my_posh_completions oh-my-posh")
+
+	_, err := root.CompletionScript("cmd")
+	assert.Error(t, err)
+}
@@ -0,0 +1,412 @@
+package cmdtree
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cmdflag"
+)
+
+// completeCmdName is the command the completion scripts call with the words on the command line,
+// the last one being the word to complete. It's handled before routing, so its flags are never parsed.
+const completeCmdName = "__complete"
+
+// CompletionFunc returns the candidates for the word being completed. args are the positional
+// arguments before it. A candidate can carry a description after a tab.
+type CompletionFunc func(cmd *Command, args []string, toComplete string) []string
+
+// RegisterFlagCompletionFunc sets the candidates for the value of a flag of this command,
+// or of a persistent flag of this command and its children.
+func (c *Command) RegisterFlagCompletionFunc(name string, fn CompletionFunc) error {
+	if c.Flags().Lookup(name) == nil && c.PersistentFlags().Lookup(name) == nil {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+
+	if c.flagCompletions == nil {
+		c.flagCompletions = make(map[string]CompletionFunc)
+	}
+
+	c.flagCompletions[name] = fn
+	return nil
+}
+
+func (c *Command) flagCompletion(name string) CompletionFunc {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if fn, ok := cmd.flagCompletions[name]; ok {
+			return fn
+		}
+	}
+
+	return nil
+}
+
+// Complete returns the candidates for the last word in args, which holds the words after the
+// program name. An empty result lets the shell fall back to completing file names.
+func (c *Command) Complete(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	toComplete := args[len(args)-1]
+	words := args[:len(args)-1]
+
+	cmd, remaining, err := c.route(words)
+	if err != nil {
+		return nil
+	}
+
+	flags := cmd.mergedFlags()
+
+	// bind the flag values so completion functions can use them, like the config file
+	_ = flags.Parse(remaining)
+
+	positionals, pending := completionState(flags, remaining)
+
+	if pending != nil {
+		return cmd.completeFlagValue(pending, positionals, toComplete, "")
+	}
+
+	if strings.HasPrefix(toComplete, "-") {
+		if name, value, found := strings.Cut(strings.TrimLeft(toComplete, "-"), "="); found {
+			flag := flags.Lookup(name)
+			if flag == nil {
+				return nil
+			}
+
+			return cmd.completeFlagValue(flag, positionals, value, toComplete[:len(toComplete)-len(value)])
+		}
+
+		return filterCandidates(flagCandidates(flags, toComplete == "-"), toComplete)
+	}
+
+	var candidates []string
+
+	if len(positionals) == 0 {
+		for _, child := range cmd.commands {
+			if child.Hidden {
+				continue
+			}
+
+			candidates = append(candidates, child.Name()+"\t"+child.Short)
+		}
+
+		candidates = append(candidates, cmd.ValidArgs...)
+	}
+
+	if cmd.ValidArgsFunction != nil {
+		candidates = append(candidates, cmd.ValidArgsFunction(cmd, positionals, toComplete)...)
+	}
+
+	return filterCandidates(candidates, toComplete)
+}
+
+func (c *Command) completeFlagValue(flag *cmdflag.Flag, positionals []string, toComplete, prefix string) []string {
+	if flag.Value.Type() == "bool" {
+		return filterCandidates([]string{prefix + "true", prefix + "false"}, prefix+toComplete)
+	}
+
+	fn := c.flagCompletion(flag.Name)
+	if fn == nil {
+		return nil
+	}
+
+	candidates := filterCandidates(fn(c, positionals, toComplete), toComplete)
+	for i, candidate := range candidates {
+		candidates[i] = prefix + candidate
+	}
+
+	return candidates
+}
+
+// completionState returns the positional arguments in words and the flag still waiting for
+// its value, when the last word is one.
+func completionState(flags *cmdflag.FlagSet, words []string) ([]string, *cmdflag.Flag) {
+	var positionals []string
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		var flag *cmdflag.Flag
+
+		switch {
+		case word == "--":
+			return append(positionals, words[i+1:]...), nil
+		case strings.HasPrefix(word, "--"):
+			if strings.Contains(word, "=") {
+				continue
+			}
+
+			flag = flags.Lookup(word[2:])
+		case strings.HasPrefix(word, "-") && len(word) > 1:
+			if strings.Contains(word, "=") {
+				continue
+			}
+
+			flag = flags.ShorthandLookup(word[len(word)-1:])
+		default:
+			positionals = append(positionals, word)
+			continue
+		}
+
+		if flag == nil || flag.Value.Type() == "bool" {
+			continue
+		}
+
+		if i == len(words)-1 {
+			return positionals, flag
+		}
+
+		i++ // skip the flag's value
+	}
+
+	return positionals, nil
+}
+
+func flagCandidates(flags *cmdflag.FlagSet, shorthands bool) []string {
+	var candidates []string
+
+	flags.VisitAll(func(flag *cmdflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+
+		candidates = append(candidates, "--"+flag.Name+"\t"+flag.Usage)
+
+		if shorthands && len(flag.Shorthand) != 0 {
+			candidates = append(candidates, "-"+flag.Shorthand+"\t"+flag.Usage)
+		}
+	})
+
+	sort.Strings(candidates)
+
+	return candidates
+}
+
+// filterCandidates keeps the unique candidates starting with toComplete, ignoring case.
+func filterCandidates(candidates []string, toComplete string) []string {
+	var filtered []string
+
+	prefix := strings.ToLower(toComplete)
+
+	for _, candidate := range candidates {
+		value, _, _ := strings.Cut(candidate, "\t")
+		if !strings.HasPrefix(strings.ToLower(value), prefix) {
+			continue
+		}
+
+		if slices.ContainsFunc(filtered, func(existing string) bool {
+			existingValue, _, _ := strings.Cut(existing, "\t")
+			return existingValue == value
+		}) {
+			continue
+		}
+
+		filtered = append(filtered, candidate)
+	}
+
+	return filtered
+}
+
+func (c *Command) printCompletions(w io.Writer, args []string) {
+	for _, candidate := range c.Complete(args) {
+		fmt.Fprintln(w, candidate)
+	}
+}
+
+// ensureCompletionCommand adds the completion command if completions aren't disabled.
+func (c *Command) ensureCompletionCommand() {
+	if c.CompletionOptions.DisableDefaultCmd || c.findChild("completion") != nil {
+		return
+	}
+
+	name := c.Name()
+
+	c.AddCommand(&Command{
+		Use:   "completion [" + strings.Join(completionShells, "|") + "]",
+		Short: "Generate the autocompletion script for the specified shell",
+		Long: `Generate the autocompletion script for ` + name + ` for the specified shell.
+Load it in your shell's profile to complete commands, flags and their values:
+
+- bash: source <(` + name + ` completion bash)
+- zsh: source <(` + name + ` completion zsh)
+- fish: ` + name + ` completion fish | source
+- powershell: ` + name + ` completion powershell | Out-String | Invoke-Expression
+- nu: ` + name + ` completion nu | save -f ~/` + name + `-completion.nu
+  and add "source ~/` + name + `-completion.nu" to your config.nu
+- elvish: eval (` + name + ` completion elvish | slurp)`,
+		ValidArgs: completionShells,
+		Args:      ExactArgs(1),
+		Run: func(cmd *Command, args []string) {
+			script, err := c.CompletionScript(args[0])
+			if err != nil {
+				fmt.Fprintln(cmd.outWriter(), err.Error())
+				return
+			}
+
+			fmt.Fprint(cmd.outWriter(), script)
+		},
+	})
+}
+
+var completionShells = []string{"bash", "zsh", "fish", "powershell", "nu", "elvish"}
+
+// CompletionScript returns the script that wires the shell's completion to the program.
+func (c *Command) CompletionScript(shell string) (string, error) {
+	var script string
+
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	case "powershell", "pwsh":
+		script = powershellCompletion
+	case "nu":
+		script = nuCompletion
+	case "elvish":
+		script = elvishCompletion
+	default:
+		return "", fmt.Errorf("unsupported shell: %s", shell)
+	}
+
+	name := c.Root().Name()
+	function := strings.NewReplacer("-", "_", ".", "_").Replace(name)
+
+	return strings.NewReplacer("{{name}}", name, "{{function}}", function, "{{complete}}", completeCmdName).Replace(script), nil
+}
+
+const bashCompletion = `# bash completion for {{name}}
+_{{function}}_completions() {
+    local line
+    local out
+    out=$({{name}} {{complete}} "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)
+
+    COMPREPLY=()
+    while IFS= read -r line; do
+        [[ -n "$line" ]] && COMPREPLY+=("${line%%$'\t'*}")
+    done <<< "$out"
+}
+
+complete -o default -F _{{function}}_completions {{name}}
+`
+
+const zshCompletion = `#compdef {{name}}
+# zsh completion for {{name}}
+_{{function}}() {
+    local line
+    local -a completions
+
+    for line in "${(@f)$({{name}} {{complete}} "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -z "$line" ]] && continue
+        if [[ "$line" == *$'\t'* ]]; then
+            completions+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            completions+=("${line//:/\\:}")
+        fi
+    done
+
+    if (( ${#completions} )); then
+        _describe '{{name}}' completions
+    else
+        _files
+    fi
+}
+
+compdef _{{function}} {{name}}
+`
+
+const fishCompletion = `# fish completion for {{name}}
+function __{{function}}_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    set -l current (commandline -ct)
+    set -l completions ({{name}} {{complete}} $args "$current" 2>/dev/null)
+
+    if test (count $completions) -eq 0
+        __fish_complete_path "$current"
+        return
+    end
+
+    printf '%s\n' $completions
+end
+
+complete -c {{name}} -f -a '(__{{function}}_complete)'
+`
+
+const powershellCompletion = `# powershell completion for {{name}}
+Register-ArgumentCompleter -Native -CommandName '{{name}}' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $arguments = @($commandAst.CommandElements |
+        Select-Object -Skip 1 |
+        Where-Object { $_.Extent.StartOffset -lt $cursorPosition } |
+        ForEach-Object { "'" + ($_.Extent.Text -replace "'", "''") + "'" })
+
+    if ($wordToComplete -eq '') {
+        # older versions drop empty arguments to native commands
+        if ($PSVersionTable.PSVersion -lt [version]'7.3.0' -or $PSNativeCommandArgumentPassing -eq 'Legacy') {
+            $arguments += '` + "`\"`\"" + `'
+        } else {
+            $arguments += '""'
+        }
+    }
+
+    $output = Invoke-Expression "& '{{name}}' {{complete}} $($arguments -join ' ') 2>` + "`" + `$null"
+
+    $output | Where-Object { $_ } | ForEach-Object {
+        $value, $description = $_ -split "` + "`" + `t", 2
+        if (-not $description) {
+            $description = $value
+        }
+
+        [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+    }
+}
+`
+
+const nuCompletion = `# nu completion for {{name}}
+let {{function}}_completer = {|spans|
+    ^{{name}} {{complete}} ...($spans | skip 1) | lines | where {|line| $line != "" } | each {|line|
+        let parts = ($line | split row "\t")
+        {
+            value: $parts.0
+            description: (if ($parts | length) > 1 { $parts.1 } else { "" })
+        }
+    }
+}
+
+let {{function}}_previous_completer = $env.config.completions.external.completer
+
+$env.config.completions.external.enable = true
+$env.config.completions.external.completer = {|spans|
+    if ($spans.0 == "{{name}}") {
+        do ${{function}}_completer $spans
+    } else if ${{function}}_previous_completer != null {
+        do ${{function}}_previous_completer $spans
+    }
+}
+`
+
+const elvishCompletion = `# elvish completion for {{name}}
+use str
+
+set edit:completion:arg-completer[{{name}}] = {|@words|
+    {{name}} {{complete}} $@words[1..] | from-lines | each {|line|
+        if (==s $line '') {
+            continue
+        }
+
+        var parts = [(str:split "\t" $line)]
+        if (> (count $parts) 1) {
+            edit:complex-candidate $parts[0] &display=$parts[0]' '$parts[1]
+        } else {
+            edit:complex-candidate $parts[0]
+        }
+    }
+}
+`
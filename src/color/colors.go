@@ -376,15 +376,25 @@ func (d *Defaults) ToAnsi(ansiColor Ansi, isBackground bool) Ansi {
 			return emptyColor
 		}
 
+		accent := d.accent.Foreground
 		if isBackground {
-			return d.accent.Background
+			accent = d.accent.Background
 		}
 
-		return d.accent.Foreground
+		// the accent color is stored as true color, see SetAccentColor
+		if clr, ok := parseTrueColor(accent); ok && depth() != TrueColorDepth {
+			return fromColorful(clr, isBackground)
+		}
+
+		return accent
 	}
 
 	colorFromName, err := getAnsiColorFromName(ansiColor, isBackground)
 	if err == nil {
+		if depth() == NoColorDepth {
+			return defaultCode(isBackground)
+		}
+
 		return colorFromName
 	}
 
@@ -396,17 +406,17 @@ func (d *Defaults) ToAnsi(ansiColor Ansi, isBackground bool) Ansi {
 			return emptyColor
 		}
 
-		c256 := color.C256(uint8(val), isBackground)
-		return Ansi(c256.String())
+		return fromIndex(uint8(val), isBackground)
 	}
 
 	style := color.HEX(colorString, isBackground)
 	if !style.IsEmpty() {
-		if TrueColor {
+		if depth() == TrueColorDepth {
 			return Ansi(style.String())
 		}
 
-		return Ansi(style.C256().String())
+		values := style.Values()
+		return fromColorful(rgb(uint8(values[0]), uint8(values[1]), uint8(values[2])), isBackground)
 	}
 
 	if colorInt, err := strconv.ParseInt(colorString, 10, 8); err == nil {
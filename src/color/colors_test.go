@@ -29,7 +29,7 @@ func TestGetAnsiFromColorString(t *testing.T) {
 		{Case: "Base 8 background", Expected: Ansi("41"), Color: "red", Background: true},
 		{Case: "Base 16 foreground", Expected: Ansi("91"), Color: "lightRed", Background: false},
 		{Case: "Base 16 background", Expected: Ansi("101"), Color: "lightRed", Background: true},
-		{Case: "Non true color TERM", Expected: Ansi("38;5;146"), Color: "#AABBCC", Color256: true},
+		{Case: "Non true color TERM", Expected: Ansi("38;5;110"), Color: "#AABBCC", Color256: true},
 	}
 
 	origTrueColor := TrueColor
@@ -0,0 +1,96 @@
+package color
+
+import (
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// WCAGAA is the contrast ratio WCAG 2 level AA requires for normal text.
+const WCAGAA = 4.5
+
+// contrastStep is how much the lightness changes per step while looking for enough contrast.
+const contrastStep = 0.01
+
+// parseHex parses a #RGB or #RRGGBB color.
+func parseHex(c Ansi) (colorful.Color, bool) {
+	if !strings.HasPrefix(c.String(), "#") {
+		return colorful.Color{}, false
+	}
+
+	clr, err := colorful.Hex(c.String())
+	if err != nil {
+		return colorful.Color{}, false
+	}
+
+	return clr, true
+}
+
+func toRGB(c colorful.Color) RGB {
+	r, g, b := c.Clamped().RGB255()
+	return RGB{R: r, G: g, B: b}
+}
+
+// ContrastRatio returns the WCAG 2 contrast ratio of two colors, from 1 to 21.
+func ContrastRatio(a, b RGB) float64 {
+	lighter, darker := a.Luminance(), b.Luminance()
+	if darker > lighter {
+		lighter, darker = darker, lighter
+	}
+
+	return (lighter + 0.05) / (darker + 0.05)
+}
+
+// HexContrastRatio returns the contrast ratio of two hex colors, ok is false
+// when either one is not a hex color.
+func HexContrastRatio(foreground, background Ansi) (float64, bool) {
+	fg, ok := parseHex(foreground)
+	if !ok {
+		return 0, false
+	}
+
+	bg, ok := parseHex(background)
+	if !ok {
+		return 0, false
+	}
+
+	return ContrastRatio(toRGB(fg), toRGB(bg)), true
+}
+
+// EnsureContrast adjusts the lightness of a hex foreground color until its contrast ratio with the
+// hex background color reaches the minimum, keeping its hue. It moves away from the background,
+// ending at black or white when the minimum can't be reached otherwise. Other colors are returned as is.
+func EnsureContrast(foreground, background Ansi, minimum float64) Ansi {
+	fg, ok := parseHex(foreground)
+	if !ok {
+		return foreground
+	}
+
+	bg, ok := parseHex(background)
+	if !ok {
+		return foreground
+	}
+
+	backgroundRGB := toRGB(bg)
+	if ContrastRatio(toRGB(fg), backgroundRGB) >= minimum {
+		return foreground
+	}
+
+	step := contrastStep
+	limit := colorful.Color{R: 1, G: 1, B: 1}
+	if backgroundRGB.IsLight() {
+		step = -contrastStep
+		limit = colorful.Color{}
+	}
+
+	h, c, l := fg.Hcl()
+
+	for l = l + step; l > 0 && l < 1; l += step {
+		candidate := colorful.Hcl(h, c, l).Clamped()
+		if ContrastRatio(toRGB(candidate), backgroundRGB) >= minimum {
+			return Ansi(candidate.Hex())
+		}
+	}
+
+	return Ansi(limit.Hex())
+}
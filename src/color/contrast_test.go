@@ -0,0 +1,65 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func TestContrastRatio(t *testing.T) {
+	assert.Equal(t, 21.0, ContrastRatio(RGB{}, RGB{R: 255, G: 255, B: 255}))
+	assert.Equal(t, 1.0, ContrastRatio(RGB{R: 12, G: 34, B: 56}, RGB{R: 12, G: 34, B: 56}))
+
+	ratio, ok := HexContrastRatio("#FFFFFF", "#777")
+	assert.True(t, ok)
+	assert.True(t, ratio > 4.47 && ratio < 4.49)
+
+	_, ok = HexContrastRatio("white", "#777777")
+	assert.False(t, ok)
+}
+
+func TestEnsureContrast(t *testing.T) {
+	cases := []struct {
+		Case       string
+		Foreground Ansi
+		Background Ansi
+		Expected   Ansi
+		Minimum    float64
+	}{
+		{Case: "Enough contrast", Foreground: "#FFFFFF", Background: "#000000", Minimum: 4.5, Expected: "#FFFFFF"},
+		{Case: "Not hex", Foreground: "white", Background: "#FFFFFF", Minimum: 4.5, Expected: "white"},
+		{Case: "Background not hex", Foreground: "#FFFFFF", Background: "p:white", Minimum: 4.5, Expected: "#FFFFFF"},
+		{Case: "Impossible on mid gray", Foreground: "#777777", Background: "#777777", Minimum: 21, Expected: "#000000"},
+		{Case: "Impossible on dark gray", Foreground: "#333333", Background: "#333333", Minimum: 21, Expected: "#ffffff"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, EnsureContrast(tc.Foreground, tc.Background, tc.Minimum), tc.Case)
+	}
+
+	adjusted := []struct {
+		Case       string
+		Foreground Ansi
+		Background Ansi
+		Lighter    bool
+	}{
+		{Case: "Yellow on white", Foreground: "#F3AE35", Background: "#FFFFFF"},
+		{Case: "Blue on navy", Foreground: "#1E3A8A", Background: "#0B1030", Lighter: true},
+		{Case: "Gray on transparent light", Foreground: "#AAAAAA", Background: "#EFF1F5"},
+	}
+
+	for _, tc := range adjusted {
+		got := EnsureContrast(tc.Foreground, tc.Background, WCAGAA)
+		assert.NotEqual(t, tc.Foreground, got, tc.Case)
+
+		ratio, ok := HexContrastRatio(got, tc.Background)
+		assert.True(t, ok, tc.Case)
+		assert.True(t, ratio >= WCAGAA, tc.Case)
+
+		before, _ := parseHex(tc.Foreground)
+		after, _ := parseHex(got)
+		_, _, lBefore := before.Hcl()
+		_, _, lAfter := after.Hcl()
+		assert.Equal(t, tc.Lighter, lAfter > lBefore, tc.Case)
+	}
+}
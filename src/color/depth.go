@@ -0,0 +1,231 @@
+package color
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Depth is the number of colors a terminal can show.
+type Depth string
+
+const (
+	// AutoDepth derives the depth from NO_COLOR, COLORTERM and TERM
+	AutoDepth Depth = "auto"
+	// TrueColorDepth shows 24-bit colors
+	TrueColorDepth Depth = "truecolor"
+	// Depth256 maps colors to the xterm 256 color palette
+	Depth256 Depth = "256"
+	// Depth16 maps colors to the 16 base ANSI colors
+	Depth16 Depth = "16"
+	// NoColorDepth uses the terminal's default colors only
+	NoColorDepth Depth = "none"
+)
+
+// ColorDepth is the depth colors are rendered in. When unset, TrueColor decides
+// between true color and the 256 color palette.
+var ColorDepth Depth
+
+// Resolve returns the depth to render in, looking at the environment for AutoDepth.
+// An unset depth does the same, except it resolves to "" where AutoDepth settles for
+// 256 colors, leaving it up to TrueColor. An unknown depth resolves to "" as well.
+func (d Depth) Resolve(getenv func(string) string) Depth {
+	switch d {
+	case TrueColorDepth, Depth256, Depth16, NoColorDepth:
+		return d
+	case AutoDepth:
+	case "":
+		// a 256 color TERM is set by plenty of true color terminals
+		if depth := AutoDepth.Resolve(getenv); depth != Depth256 {
+			return depth
+		}
+
+		return ""
+	default:
+		return ""
+	}
+
+	// https://no-color.org
+	if len(getenv("NO_COLOR")) != 0 {
+		return NoColorDepth
+	}
+
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		if TrueColor {
+			return TrueColorDepth
+		}
+
+		return Depth256
+	}
+
+	term := getenv("TERM")
+
+	switch {
+	case term == "dumb":
+		return NoColorDepth
+	case strings.Contains(term, "256color"):
+		return Depth256
+	case term == "linux", term == "ansi", strings.HasPrefix(term, "vt"), strings.HasSuffix(term, "16color"):
+		return Depth16
+	case strings.Contains(term, "direct"):
+		return TrueColorDepth
+	}
+
+	return Depth256
+}
+
+func depth() Depth {
+	if len(ColorDepth) != 0 {
+		return ColorDepth
+	}
+
+	if TrueColor {
+		return TrueColorDepth
+	}
+
+	return Depth256
+}
+
+// base16 holds the xterm default values of the 16 base ANSI colors, in order.
+var base16 = [16]colorful.Color{
+	rgb(0, 0, 0), rgb(205, 0, 0), rgb(0, 205, 0), rgb(205, 205, 0),
+	rgb(0, 0, 238), rgb(205, 0, 205), rgb(0, 205, 205), rgb(229, 229, 229),
+	rgb(127, 127, 127), rgb(255, 0, 0), rgb(0, 255, 0), rgb(255, 255, 0),
+	rgb(92, 92, 255), rgb(255, 0, 255), rgb(0, 255, 255), rgb(255, 255, 255),
+}
+
+var (
+	nearestColors sync.Map
+
+	// cubeLevels are the channel values of the 6x6x6 color cube (indices 16 to 231)
+	cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+)
+
+func rgb(r, g, b uint8) colorful.Color {
+	return colorful.Color{R: float64(r) / 255.0, G: float64(g) / 255.0, B: float64(b) / 255.0}
+}
+
+// palette256 returns the color of an xterm 256 color palette index.
+func palette256(index uint8) colorful.Color {
+	switch {
+	case index < 16:
+		return base16[index]
+	case index < 232:
+		index -= 16
+		return rgb(cubeLevels[index/36], cubeLevels[(index/6)%6], cubeLevels[index%6])
+	default:
+		level := 8 + 10*(index-232)
+		return rgb(level, level, level)
+	}
+}
+
+// nearest returns the index of the palette entry closest to the color, using the CIEDE2000
+// distance. The 256 color palette skips the 16 base colors, as themes redefine those.
+func nearest(c colorful.Color, palette Depth) uint8 {
+	r, g, b := c.RGB255()
+	key := string(palette) + ":" + strconv.Itoa(int(r)<<16|int(g)<<8|int(b))
+
+	if index, ok := nearestColors.Load(key); ok {
+		return index.(uint8)
+	}
+
+	first, last := 16, 255
+	if palette == Depth16 {
+		first, last = 0, 15
+	}
+
+	best := uint8(first)
+	bestDistance := c.DistanceCIEDE2000(palette256(best))
+
+	for index := first + 1; index <= last; index++ {
+		distance := c.DistanceCIEDE2000(palette256(uint8(index)))
+		if distance < bestDistance {
+			best, bestDistance = uint8(index), distance
+		}
+	}
+
+	nearestColors.Store(key, best)
+
+	return best
+}
+
+// base16Code returns the SGR code of a base ANSI color index.
+func base16Code(index uint8, isBackground bool) Ansi {
+	code := 30 + int(index)
+	if index >= 8 {
+		code = 90 + int(index) - 8
+	}
+
+	if isBackground {
+		code += 10
+	}
+
+	return Ansi(strconv.Itoa(code))
+}
+
+// defaultCode returns the SGR code of the terminal's default color.
+func defaultCode(isBackground bool) Ansi {
+	if isBackground {
+		return "49"
+	}
+
+	return "39"
+}
+
+// fromIndex converts a 256 color palette index to an SGR code within the current depth.
+func fromIndex(index uint8, isBackground bool) Ansi {
+	switch depth() { //nolint:exhaustive
+	case NoColorDepth:
+		return defaultCode(isBackground)
+	case Depth16:
+		if index >= 16 {
+			index = nearest(palette256(index), Depth16)
+		}
+
+		return base16Code(index, isBackground)
+	}
+
+	buf := make([]byte, 0, 10)
+	if isBackground {
+		buf = append(buf, "48;5;"...)
+	} else {
+		buf = append(buf, "38;5;"...)
+	}
+
+	return Ansi(strconv.AppendUint(buf, uint64(index), 10))
+}
+
+// fromColorful converts a color to an SGR code within the current depth, mapping it to the
+// perceptually closest palette entry when true color is not available. The true color payload
+// is built with strconv appends rather than gookit's fmt.Sprintf path: one allocation per cell
+// instead of four, on what is the gradient hot path's dominant allocation site.
+func fromColorful(c colorful.Color, isBackground bool) Ansi {
+	switch depth() { //nolint:exhaustive
+	case NoColorDepth:
+		return defaultCode(isBackground)
+	case Depth16:
+		return base16Code(nearest(c, Depth16), isBackground)
+	case Depth256:
+		return fromIndex(nearest(c, Depth256), isBackground)
+	}
+
+	r, g, b := c.RGB255()
+	buf := make([]byte, 0, 16)
+
+	if isBackground {
+		buf = append(buf, "48;2;"...)
+	} else {
+		buf = append(buf, "38;2;"...)
+	}
+
+	buf = strconv.AppendUint(buf, uint64(r), 10)
+	buf = append(buf, ';')
+	buf = strconv.AppendUint(buf, uint64(g), 10)
+	buf = append(buf, ';')
+	buf = strconv.AppendUint(buf, uint64(b), 10)
+
+	return Ansi(buf)
+}
@@ -0,0 +1,100 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func TestDepthResolve(t *testing.T) {
+	cases := []struct {
+		Env       map[string]string
+		Case      string
+		Depth     Depth
+		Expected  Depth
+		NoTrueClr bool
+	}{
+		{Case: "Unset", Expected: ""},
+		{Case: "Unset with NO_COLOR", Env: map[string]string{"NO_COLOR": "1"}, Expected: NoColorDepth},
+		{Case: "Unset with COLORTERM", Env: map[string]string{"COLORTERM": "truecolor", "TERM": "xterm-256color"}, Expected: TrueColorDepth},
+		{Case: "Unset in a Linux VT", Env: map[string]string{"TERM": "linux"}, Expected: Depth16},
+		{Case: "Unset with a 256 color TERM", Env: map[string]string{"TERM": "xterm-256color"}, Expected: ""},
+		{Case: "Unknown", Depth: "1024", Expected: ""},
+		{Case: "Fixed", Depth: Depth16, Env: map[string]string{"COLORTERM": "truecolor"}, Expected: Depth16},
+		{Case: "NO_COLOR", Depth: AutoDepth, Env: map[string]string{"NO_COLOR": "1", "COLORTERM": "truecolor"}, Expected: NoColorDepth},
+		{Case: "COLORTERM", Depth: AutoDepth, Env: map[string]string{"COLORTERM": "24bit", "TERM": "xterm-256color"}, Expected: TrueColorDepth},
+		{Case: "COLORTERM without true color", Depth: AutoDepth, Env: map[string]string{"COLORTERM": "truecolor"}, NoTrueClr: true, Expected: Depth256},
+		{Case: "256 color TERM", Depth: AutoDepth, Env: map[string]string{"TERM": "screen-256color"}, Expected: Depth256},
+		{Case: "Direct color TERM", Depth: AutoDepth, Env: map[string]string{"TERM": "xterm-direct"}, Expected: TrueColorDepth},
+		{Case: "Linux VT", Depth: AutoDepth, Env: map[string]string{"TERM": "linux"}, Expected: Depth16},
+		{Case: "VT220", Depth: AutoDepth, Env: map[string]string{"TERM": "vt220"}, Expected: Depth16},
+		{Case: "Dumb", Depth: AutoDepth, Env: map[string]string{"TERM": "dumb"}, Expected: NoColorDepth},
+		{Case: "Nothing known", Depth: AutoDepth, Expected: Depth256},
+	}
+
+	origTrueColor := TrueColor
+	t.Cleanup(func() { TrueColor = origTrueColor })
+
+	for _, tc := range cases {
+		TrueColor = !tc.NoTrueClr
+		got := tc.Depth.Resolve(func(key string) string {
+			return tc.Env[key]
+		})
+		assert.Equal(t, tc.Expected, got, tc.Case)
+	}
+}
+
+func TestToAnsiColorDepth(t *testing.T) {
+	cases := []struct {
+		Case       string
+		Color      Ansi
+		Depth      Depth
+		Expected   Ansi
+		Background bool
+	}{
+		{Case: "True color", Color: "#FF0000", Depth: TrueColorDepth, Expected: "38;2;255;0;0"},
+		{Case: "256, exact match", Color: "#FF0000", Depth: Depth256, Expected: "38;5;196"},
+		{Case: "256, gray", Color: "#303030", Depth: Depth256, Background: true, Expected: "48;5;236"},
+		{Case: "256, perceptual", Color: "#AABBCC", Depth: Depth256, Expected: "38;5;110"},
+		{Case: "256 index", Color: "99", Depth: Depth256, Expected: "38;5;99"},
+		{Case: "16, bright red", Color: "#FF1010", Depth: Depth16, Expected: "91"},
+		{Case: "16, dark blue background", Color: "#0000C0", Depth: Depth16, Background: true, Expected: "44"},
+		{Case: "16, base index", Color: "3", Depth: Depth16, Expected: "33"},
+		{Case: "16, 256 index", Color: "231", Depth: Depth16, Expected: "97"},
+		{Case: "16, named color", Color: "lightBlue", Depth: Depth16, Expected: "94"},
+		{Case: "None, hex", Color: "#FF0000", Depth: NoColorDepth, Expected: "39"},
+		{Case: "None, named background", Color: "red", Depth: NoColorDepth, Background: true, Expected: "49"},
+		{Case: "None, transparent", Color: Transparent, Depth: NoColorDepth, Expected: Transparent},
+	}
+
+	t.Cleanup(func() { ColorDepth = "" })
+
+	for _, tc := range cases {
+		ColorDepth = tc.Depth
+		ansiColors := &Defaults{}
+		assert.Equal(t, tc.Expected, ansiColors.ToAnsi(tc.Color, tc.Background), tc.Case)
+	}
+}
+
+func TestAccentColorDepth(t *testing.T) {
+	t.Cleanup(func() { ColorDepth = "" })
+
+	ansiColors := &Defaults{
+		accent: &Set{Foreground: "38;2;0;0;255", Background: "48;2;0;0;255"},
+	}
+
+	ColorDepth = Depth16
+	assert.Equal(t, Ansi("44"), ansiColors.ToAnsi(Accent, true))
+}
+
+func TestGradientCellsColorDepth(t *testing.T) {
+	t.Cleanup(func() { ColorDepth = "" })
+
+	ColorDepth = Depth16
+	result := GradientCells("linear-gradient(#000000, #FFFFFF)", 2, &Defaults{}, false, nil, nil)
+	assert.Equal(t, []Ansi{"30", "97"}, result)
+
+	ColorDepth = NoColorDepth
+	result = GradientCells("linear-gradient(#000000, #FFFFFF)", 2, &Defaults{}, true, nil, nil)
+	assert.Equal(t, []Ansi{"49", "49"}, result)
+}
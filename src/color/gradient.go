@@ -5,7 +5,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/gookit/color"
 	"github.com/jandedobbeleer/oh-my-posh/src/log"
 	"github.com/lucasb-eyer/go-colorful"
 )
@@ -236,7 +235,7 @@ func GradientCells(c Ansi, cells int, resolver String, isBackground bool, curren
 	}
 
 	if cells == 1 {
-		return cacheGradientCells(colors, cells, isBackground, []Ansi{ansiFromColorful(colors[0], isBackground)})
+		return cacheGradientCells(colors, cells, isBackground, []Ansi{fromColorful(colors[0], isBackground)})
 	}
 
 	segments := len(colors) - 1
@@ -251,7 +250,7 @@ func GradientCells(c Ansi, cells int, resolver String, isBackground bool, curren
 		}
 
 		blended := colors[segment].BlendHcl(colors[segment+1], position-float64(segment)).Clamped()
-		result[i] = ansiFromColorful(blended, isBackground)
+		result[i] = fromColorful(blended, isBackground)
 	}
 
 	return cacheGradientCells(colors, cells, isBackground, result)
@@ -408,7 +407,7 @@ func autoShade(base colorful.Color, dir shadeDirection, cells int) colorful.Colo
 
 // gradientCellCache memoizes interpolation results keyed on the RESOLVED stop colors
 // (keyword and palette stops resolve before the key is built, so context changes miss
-// the cache correctly), the cell count, the channel, and the TrueColor mode. Prompt
+// the cache correctly), the cell count, the channel, and the color depth. Prompt
 // rendering is single-threaded (see the terminal writer's package state), so a plain
 // map suffices. Bounded to keep long-lived daemons from growing it unchecked.
 var gradientCellCache = make(map[string][]Ansi)
@@ -434,9 +433,7 @@ func gradientKey(colors []colorful.Color, cells int, isBackground bool) string {
 		buf = append(buf, 'b')
 	}
 
-	if TrueColor {
-		buf = append(buf, 't')
-	}
+	buf = append(buf, depth()...)
 
 	return string(buf)
 }
@@ -462,31 +459,3 @@ func parseTrueColor(c Ansi) (colorful.Color, bool) {
 
 	return colorful.Color{R: float64(rgb.R) / 255.0, G: float64(rgb.G) / 255.0, B: float64(rgb.B) / 255.0}, true
 }
-
-// ansiFromColorful converts an interpolated HCL color to a ready-to-print ANSI code, honoring
-// the package-level TrueColor flag. The truecolor payload is built with strconv appends
-// rather than gookit's fmt.Sprintf path: one allocation per cell instead of four, on what
-// is the gradient hot path's dominant allocation site.
-func ansiFromColorful(c colorful.Color, isBackground bool) Ansi {
-	r, g, b := c.RGB255()
-
-	if !TrueColor {
-		return Ansi(color.RGB(r, g, b, isBackground).C256().String())
-	}
-
-	buf := make([]byte, 0, 16)
-
-	if isBackground {
-		buf = append(buf, "48;2;"...)
-	} else {
-		buf = append(buf, "38;2;"...)
-	}
-
-	buf = strconv.AppendUint(buf, uint64(r), 10)
-	buf = append(buf, ';')
-	buf = strconv.AppendUint(buf, uint64(g), 10)
-	buf = append(buf, ';')
-	buf = strconv.AppendUint(buf, uint64(b), 10)
-
-	return Ansi(buf)
-}
@@ -262,7 +262,7 @@ func TestGradientCellsColor256Fallback(t *testing.T) {
 
 	assert.Len(t, result, 3)
 	for i, cell := range result {
-		assert.Equal(t, Ansi("38;5;146"), cell, fmt.Sprintf("cell %d", i))
+		assert.Equal(t, Ansi("38;5;110"), cell, fmt.Sprintf("cell %d", i))
 	}
 }
 
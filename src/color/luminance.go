@@ -0,0 +1,25 @@
+package color
+
+import "math"
+
+// Luminance returns the relative luminance of the color, as defined by WCAG 2:
+// 0 for black and 1 for white.
+func (c RGB) Luminance() float64 {
+	linear := func(value uint8) float64 {
+		channel := float64(value) / 255
+		if channel <= 0.04045 {
+			return channel / 12.92
+		}
+
+		return math.Pow((channel+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// IsLight tells if black text contrasts better with the color than white text does.
+func (c RGB) IsLight() bool {
+	// the luminance where the contrast ratio with black equals the one with white:
+	// (L + 0.05) / 0.05 = 1.05 / (L + 0.05)
+	return c.Luminance() > math.Sqrt(1.05*0.05)-0.05
+}
@@ -0,0 +1,27 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func TestIsLight(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Color    RGB
+		Expected bool
+	}{
+		{Case: "White", Color: RGB{R: 255, G: 255, B: 255}, Expected: true},
+		{Case: "Black", Color: RGB{}},
+		{Case: "Catppuccin Latte", Color: RGB{R: 239, G: 241, B: 245}, Expected: true},
+		{Case: "Catppuccin Mocha", Color: RGB{R: 30, G: 30, B: 46}},
+		{Case: "Solarized Light", Color: RGB{R: 253, G: 246, B: 227}, Expected: true},
+		{Case: "Mid gray", Color: RGB{R: 128, G: 128, B: 128}, Expected: true},
+		{Case: "Pure blue", Color: RGB{B: 255}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, tc.Color.IsLight(), tc.Case)
+	}
+}
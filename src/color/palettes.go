@@ -2,5 +2,21 @@ package color
 
 type Palettes struct {
 	List     map[string]Palette `json:"list,omitempty" toml:"list,omitempty" yaml:"list,omitempty"`
+	Auto     *AutoPalettes      `json:"auto,omitempty" toml:"auto,omitempty" yaml:"auto,omitempty"`
 	Template string             `json:"template,omitempty" toml:"template,omitempty" yaml:"template,omitempty"`
 }
+
+// AutoPalettes names the palettes in the list to use for a light or a dark terminal background.
+type AutoPalettes struct {
+	Light string `json:"light,omitempty" toml:"light,omitempty" yaml:"light,omitempty"`
+	Dark  string `json:"dark,omitempty" toml:"dark,omitempty" yaml:"dark,omitempty"`
+}
+
+// Key returns the palette to use, depending on the terminal background.
+func (a *AutoPalettes) Key(light bool) string {
+	if light {
+		return a.Light
+	}
+
+	return a.Dark
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -11,22 +12,20 @@ import (
 	yaml "go.yaml.in/yaml/v3"
 )
 
-func (cfg *Config) Backup() {
+func (cfg *Config) Backup() error {
 	dst := cfg.Source + ".bak"
 	source, err := os.Open(cfg.Source)
 	if err != nil {
-		return
+		return err
 	}
 	defer source.Close()
 	destination, err := os.Create(dst)
 	if err != nil {
-		return
+		return err
 	}
 	defer destination.Close()
 	_, err = io.Copy(destination, source)
-	if err != nil {
-		return
-	}
+	return err
 }
 
 func (cfg *Config) Export(format string) string {
@@ -71,16 +70,15 @@ func (cfg *Config) Export(format string) string {
 	return ""
 }
 
-func (cfg *Config) Write(format string) {
+func (cfg *Config) Write(format string) error {
 	content := cfg.Export(format)
 	if content == "" {
-		// we are unable to perform the export
-		return
+		return fmt.Errorf("unable to export the config as %q", cfg.Format)
 	}
 
 	f, err := os.OpenFile(cfg.Source, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
-		return
+		return err
 	}
 
 	defer func() {
@@ -88,7 +86,5 @@ func (cfg *Config) Write(format string) {
 	}()
 
 	_, err = f.WriteString(content)
-	if err != nil {
-		return
-	}
+	return err
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
 	"github.com/jandedobbeleer/oh-my-posh/src/cli/upgrade"
 	"github.com/jandedobbeleer/oh-my-posh/src/color"
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
 	"github.com/jandedobbeleer/oh-my-posh/src/log"
 	"github.com/jandedobbeleer/oh-my-posh/src/maps"
 	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
@@ -59,17 +60,22 @@ const (
 )
 
 type Config struct {
-	Palette                 color.Palette      `json:"palette,omitempty" toml:"palette,omitempty" yaml:"palette,omitempty"`
-	DebugPrompt             *Segment           `json:"debug_prompt,omitempty" toml:"debug_prompt,omitempty" yaml:"debug_prompt,omitempty"`
-	Var                     map[string]any     `json:"var,omitempty" toml:"var,omitempty" yaml:"var,omitempty"`
-	Palettes                *color.Palettes    `json:"palettes,omitempty" toml:"palettes,omitempty" yaml:"palettes,omitempty"`
-	ValidLine               *Segment           `json:"valid_line,omitempty" toml:"valid_line,omitempty" yaml:"valid_line,omitempty"`
-	SecondaryPrompt         *Segment           `json:"secondary_prompt,omitempty" toml:"secondary_prompt,omitempty" yaml:"secondary_prompt,omitempty"`
-	TransientPrompt         *Segment           `json:"transient_prompt,omitempty" toml:"transient_prompt,omitempty" yaml:"transient_prompt,omitempty"`
-	ErrorLine               *Segment           `json:"error_line,omitempty" toml:"error_line,omitempty" yaml:"error_line,omitempty"`
-	Maps                    *maps.Config       `json:"maps,omitempty" toml:"maps,omitempty" yaml:"maps,omitempty"`
-	Upgrade                 *upgrade.Config    `json:"upgrade,omitempty" toml:"upgrade,omitempty" yaml:"upgrade,omitempty"`
-	TerminalFeatures        *terminal.Features `json:"terminal_features,omitempty" toml:"terminal_features,omitempty" yaml:"terminal_features,omitempty"`
+	Palette                 color.Palette          `json:"palette,omitempty" toml:"palette,omitempty" yaml:"palette,omitempty"`
+	DebugPrompt             *Segment               `json:"debug_prompt,omitempty" toml:"debug_prompt,omitempty" yaml:"debug_prompt,omitempty"`
+	Var                     map[string]any         `json:"var,omitempty" toml:"var,omitempty" yaml:"var,omitempty"`
+	Partials                map[string]string      `json:"partials,omitempty" toml:"partials,omitempty" yaml:"partials,omitempty"`
+	Palettes                *color.Palettes        `json:"palettes,omitempty" toml:"palettes,omitempty" yaml:"palettes,omitempty"`
+	ValidLine               *Segment               `json:"valid_line,omitempty" toml:"valid_line,omitempty" yaml:"valid_line,omitempty"`
+	SecondaryPrompt         *Segment               `json:"secondary_prompt,omitempty" toml:"secondary_prompt,omitempty" yaml:"secondary_prompt,omitempty"`
+	TransientPrompt         *Segment               `json:"transient_prompt,omitempty" toml:"transient_prompt,omitempty" yaml:"transient_prompt,omitempty"`
+	ErrorLine               *Segment               `json:"error_line,omitempty" toml:"error_line,omitempty" yaml:"error_line,omitempty"`
+	Maps                    *maps.Config           `json:"maps,omitempty" toml:"maps,omitempty" yaml:"maps,omitempty"`
+	Upgrade                 *upgrade.Config        `json:"upgrade,omitempty" toml:"upgrade,omitempty" yaml:"upgrade,omitempty"`
+	TerminalFeatures        *terminal.Features     `json:"terminal_features,omitempty" toml:"terminal_features,omitempty" yaml:"terminal_features,omitempty"`
+	TemplateCapabilities    *template.Capabilities `json:"template_capabilities,omitempty" toml:"template_capabilities,omitempty" yaml:"template_capabilities,omitempty"`
+	InfoPanel               *InfoPanel             `json:"info_panel,omitempty" toml:"info_panel,omitempty" yaml:"info_panel,omitempty"`
+	Frecency                *frecency.Config       `json:"frecency,omitempty" toml:"frecency,omitempty" yaml:"frecency,omitempty"`
+	KeyBindings             []*shell.KeyBinding    `json:"key_bindings,omitempty" toml:"key_bindings,omitempty" yaml:"key_bindings,omitempty"`
 	presentFields           map[string]bool
 	Extends                 string                 `json:"extends,omitempty" toml:"extends,omitempty" yaml:"extends,omitempty"`
 	PWD                     string                 `json:"pwd,omitempty" toml:"pwd,omitempty" yaml:"pwd,omitempty"`
@@ -80,6 +86,8 @@ type Config struct {
 	ConsoleTitleTemplate    string                 `json:"console_title_template,omitempty" toml:"console_title_template,omitempty" yaml:"console_title_template,omitempty"`
 	CursorStyle             string                 `json:"cursor_style,omitempty" toml:"cursor_style,omitempty" yaml:"cursor_style,omitempty"`
 	AccentColor             color.Ansi             `json:"accent_color,omitempty" toml:"accent_color,omitempty" yaml:"accent_color,omitempty"`
+	ColorDepth              color.Depth            `json:"color_depth,omitempty" toml:"color_depth,omitempty" yaml:"color_depth,omitempty"`
+	MinimumContrast         float64                `json:"minimum_contrast,omitempty" toml:"minimum_contrast,omitempty" yaml:"minimum_contrast,omitempty"`
 	Blocks                  []*Block               `json:"blocks,omitempty" toml:"blocks,omitempty" yaml:"blocks,omitempty"`
 	ITermFeatures           terminal.ITermFeatures `json:"iterm_features,omitempty" toml:"iterm_features,omitempty" yaml:"iterm_features,omitempty"`
 	Tooltips                []*Segment             `json:"tooltips,omitempty" toml:"tooltips,omitempty" yaml:"tooltips,omitempty"`
@@ -87,6 +95,7 @@ type Config struct {
 	hash                    uint64
 	Version                 int  `json:"version" toml:"version" yaml:"version"`
 	Streaming               int  `json:"streaming,omitempty" toml:"streaming,omitempty" yaml:"streaming,omitempty"`
+	LatencyBudget           int  `json:"latency_budget,omitempty" toml:"latency_budget,omitempty" yaml:"latency_budget,omitempty"`
 	Async                   bool `json:"async,omitempty" toml:"async,omitempty" yaml:"async,omitempty"`
 	ShellIntegration        bool `json:"shell_integration,omitempty" toml:"shell_integration,omitempty" yaml:"shell_integration,omitempty"`
 	FinalSpace              bool `json:"final_space,omitempty" toml:"final_space,omitempty" yaml:"final_space,omitempty"`
@@ -108,7 +117,7 @@ func (cfg *Config) getPalette() color.Palette {
 		return cfg.Palette
 	}
 
-	key, err := template.RenderTrusted(cfg.Palettes.Template, nil)
+	key, err := cfg.paletteKey()
 	if err != nil {
 		return cfg.Palette
 	}
@@ -129,6 +138,69 @@ func (cfg *Config) getPalette() color.Palette {
 	return palette
 }
 
+// paletteKey returns the name of the palette to use from the list: the template decides
+// when there is one, the terminal background otherwise.
+func (cfg *Config) paletteKey() (string, error) {
+	if len(cfg.Palettes.Template) != 0 || cfg.Palettes.Auto == nil {
+		return template.RenderTrusted(cfg.Palettes.Template, nil)
+	}
+
+	light := template.Cache != nil && template.Cache.TerminalIsLight
+	return cfg.Palettes.Auto.Key(light), nil
+}
+
+// DetectTerminal queries the terminal for its capabilities and background color, in a
+// single round trip, for what the config depends on.
+func (cfg *Config) DetectTerminal() {
+	probe := cfg.TerminalFeatures != nil && cfg.TerminalFeatures.Probe
+	terminal.Probe(probe, cfg.needsTerminalBackground())
+}
+
+// needsTerminalBackground tells if the config depends on the background color of the terminal.
+func (cfg *Config) needsTerminalBackground() bool {
+	if cfg.Palettes != nil && cfg.Palettes.Auto != nil {
+		return true
+	}
+
+	return slices.ContainsFunc(cfg.templates(), func(text string) bool {
+		return strings.Contains(text, ".TerminalIsLight")
+	})
+}
+
+// templates returns every template in the config.
+func (cfg *Config) templates() []string {
+	texts := []string{cfg.ConsoleTitleTemplate, cfg.CursorStyle, string(cfg.TerminalBackground)}
+
+	if cfg.Palettes != nil {
+		texts = append(texts, cfg.Palettes.Template)
+	}
+
+	for _, partial := range cfg.Partials {
+		texts = append(texts, partial)
+	}
+
+	segments := slices.Clone(cfg.Tooltips)
+	segments = append(segments, cfg.TransientPrompt, cfg.SecondaryPrompt, cfg.DebugPrompt, cfg.ValidLine, cfg.ErrorLine)
+
+	for _, block := range cfg.Blocks {
+		texts = append(texts, block.Filler)
+		segments = append(segments, block.Segments...)
+	}
+
+	for _, segment := range segments {
+		if segment == nil {
+			continue
+		}
+
+		texts = append(texts, segment.Template, segment.RightTemplate, segment.FallbackTemplate, segment.Link, segment.When, segment.FinishedTemplate)
+		texts = append(texts, segment.Templates...)
+		texts = append(texts, segment.ForegroundTemplates...)
+		texts = append(texts, segment.BackgroundTemplates...)
+	}
+
+	return texts
+}
+
 func (cfg *Config) Features(env runtime.Environment) shell.Features {
 	var feats shell.Features
 
@@ -147,6 +219,11 @@ func (cfg *Config) Features(env runtime.Environment) shell.Features {
 			log.Debug("transient right prompt enabled")
 			feats |= shell.TransientRPrompt
 		}
+
+		if len(cfg.TransientPrompt.FinishedTemplate) != 0 {
+			log.Debug("transient finished line enabled")
+			feats |= shell.TransientFinished
+		}
 	}
 
 	if cfg.Streaming > 0 {
@@ -158,8 +235,8 @@ func (cfg *Config) Features(env runtime.Environment) shell.Features {
 		feats |= shell.KeyHandlers
 	}
 
-	unsupportedShells := []string{shell.ELVISH, shell.XONSH}
-	if slices.Contains(unsupportedShells, env.Shell()) {
+	// Elvish can't render the marks in its prompt, its hooks emit them instead
+	if env.Shell() == shell.XONSH {
 		cfg.ShellIntegration = false
 	}
 
@@ -186,6 +263,20 @@ func (cfg *Config) Features(env runtime.Environment) shell.Features {
 	if len(cfg.Tooltips) > 0 {
 		log.Debug("tooltips enabled")
 		feats |= shell.Tooltips
+
+		if cfg.tooltipsNeedCommandLine() {
+			feats |= shell.TooltipsCommandLine
+		}
+	}
+
+	if cfg.InfoPanel != nil && len(cfg.InfoPanel.Key) != 0 {
+		log.Debug("info panel enabled")
+		feats |= shell.InfoPanel
+	}
+
+	if len(cfg.KeyBindings) != 0 {
+		log.Debug("key bindings enabled")
+		feats |= shell.KeyBindings
 	}
 
 	if env.Shell() == shell.FISH && cfg.ITermFeatures != nil && cfg.ITermFeatures.Contains(terminal.PromptMark) {
@@ -284,6 +375,64 @@ func (cfg *Config) migrateSegmentProperties() {
 	}
 }
 
+// breakWhenCycles stops segments whose when expressions refer to each other,
+// directly or through other segments, from waiting for one another. They would
+// otherwise each wait until the timeout, on every render.
+func (cfg *Config) breakWhenCycles() {
+	segments := make(map[string]*Segment)
+
+	for _, block := range cfg.Blocks {
+		for _, segment := range block.Segments {
+			if _, ok := segments[segment.Name()]; !ok {
+				segments[segment.Name()] = segment
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		for _, need := range segments[name].WhenNeeds() {
+			if _, ok := segments[need]; !ok {
+				continue
+			}
+
+			switch state[need] {
+			case visiting:
+				cycle := stack[slices.Index(stack, need):]
+				log.Errorf("when expressions refer to each other: %s", strings.Join(append(cycle, need), " -> "))
+
+				for _, member := range cycle {
+					segments[member].whenCycle = true
+				}
+			case 0:
+				visit(need)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+
+	for _, block := range cfg.Blocks {
+		for _, segment := range block.Segments {
+			if state[segment.Name()] == 0 {
+				visit(segment.Name())
+			}
+		}
+	}
+}
+
 func (cfg *Config) toggleSegments() {
 	currentToggleSet, _ := cache.Get[map[string]bool](cache.Session, cache.TOGGLECACHE)
 	if currentToggleSet == nil {
@@ -19,11 +19,19 @@ func TestGetPalette(t *testing.T) {
 		"blue": "#0000ff",
 	}
 
+	light := color.Palette{
+		"red":  "#d20f39",
+		"blue": "#1e66f5",
+	}
+
+	auto := &color.AutoPalettes{Light: "latte", Dark: "mocha"}
+
 	cases := []struct {
 		Palettes        *color.Palettes
 		Palette         color.Palette
 		ExpectedPalette color.Palette
 		Case            string
+		TerminalIsLight bool
 	}{
 		{
 			Case: "match",
@@ -89,6 +97,27 @@ func TestGetPalette(t *testing.T) {
 				"yellow": "#ffff00",
 			},
 		},
+		{
+			Case:            "auto, light background",
+			Palettes:        &color.Palettes{Auto: auto, List: map[string]color.Palette{"latte": light, "mocha": palette}},
+			TerminalIsLight: true,
+			ExpectedPalette: light,
+		},
+		{
+			Case:            "auto, dark background",
+			Palettes:        &color.Palettes{Auto: auto, List: map[string]color.Palette{"latte": light, "mocha": palette}},
+			ExpectedPalette: palette,
+		},
+		{
+			Case: "auto, template takes precedence",
+			Palettes: &color.Palettes{
+				Auto:     auto,
+				Template: "{{ .Shell }}",
+				List:     map[string]color.Palette{"latte": light, "bash": palette},
+			},
+			TerminalIsLight: true,
+			ExpectedPalette: palette,
+		},
 	}
 
 	for _, tc := range cases {
@@ -97,7 +126,8 @@ func TestGetPalette(t *testing.T) {
 
 		template.Cache = &cache.Template{
 			SimpleTemplate: cache.SimpleTemplate{
-				Shell: "bash",
+				Shell:           "bash",
+				TerminalIsLight: tc.TerminalIsLight,
 			},
 		}
 		template.Init(env, nil, nil)
@@ -136,6 +166,18 @@ func TestFeaturesShellIntegration(t *testing.T) {
 			ShellIntegration: true,
 			ExpectedFeats:    shell.FTCSMarks,
 		},
+		{
+			Case:             "elvish with shell integration enables FTCSMarks only",
+			Shell:            shell.ELVISH,
+			ShellIntegration: true,
+			ExpectedFeats:    shell.FTCSMarks,
+		},
+		{
+			Case:             "xonsh with shell integration enables nothing",
+			Shell:            shell.XONSH,
+			ShellIntegration: true,
+			ExpectedFeats:    0,
+		},
 		{
 			Case:             "pwsh without shell integration enables nothing",
 			Shell:            shell.PWSH,
@@ -167,9 +209,10 @@ func TestFeaturesShellIntegration(t *testing.T) {
 
 func TestFeaturesTransientRightPrompt(t *testing.T) {
 	cases := []struct {
-		Case          string
-		RightTemplate string
-		ExpectedFeats shell.Features
+		Case             string
+		RightTemplate    string
+		FinishedTemplate string
+		ExpectedFeats    shell.Features
 	}{
 		{
 			Case:          "transient prompt without right template",
@@ -180,6 +223,11 @@ func TestFeaturesTransientRightPrompt(t *testing.T) {
 			RightTemplate: "R>",
 			ExpectedFeats: shell.Transient | shell.TransientRPrompt | shell.KeyHandlers,
 		},
+		{
+			Case:             "transient prompt with finished template",
+			FinishedTemplate: "{{ .Duration }}",
+			ExpectedFeats:    shell.Transient | shell.TransientFinished | shell.KeyHandlers,
+		},
 	}
 
 	for _, tc := range cases {
@@ -187,7 +235,7 @@ func TestFeaturesTransientRightPrompt(t *testing.T) {
 		env.On("Shell").Return(shell.FISH)
 
 		cfg := &Config{
-			TransientPrompt: &Segment{RightTemplate: tc.RightTemplate},
+			TransientPrompt: &Segment{RightTemplate: tc.RightTemplate, FinishedTemplate: tc.FinishedTemplate},
 			Upgrade:         &upgrade.Config{},
 		}
 
@@ -196,6 +244,65 @@ func TestFeaturesTransientRightPrompt(t *testing.T) {
 	}
 }
 
+func TestFeaturesTooltips(t *testing.T) {
+	cases := []struct {
+		InfoPanel     *InfoPanel
+		Case          string
+		Tooltips      []*Segment
+		KeyBindings   []*shell.KeyBinding
+		ExpectedFeats shell.Features
+	}{
+		{
+			Case: "no tooltips",
+		},
+		{
+			Case:          "first word tooltips",
+			Tooltips:      []*Segment{{Tips: []string{"git", "kubectl"}}},
+			ExpectedFeats: shell.Tooltips,
+		},
+		{
+			Case:          "command prefix tooltip",
+			Tooltips:      []*Segment{{Tips: []string{"git"}}, {Tips: []string{"terraform apply"}}},
+			ExpectedFeats: shell.Tooltips | shell.TooltipsCommandLine,
+		},
+		{
+			Case:          "regex tooltip",
+			Tooltips:      []*Segment{{TipsRegex: []string{`^kubectl .*-n`}}},
+			ExpectedFeats: shell.Tooltips | shell.TooltipsCommandLine,
+		},
+		{
+			Case:          "info panel",
+			Tooltips:      []*Segment{{Tips: []string{"git"}}},
+			InfoPanel:     &InfoPanel{Key: "alt+i"},
+			ExpectedFeats: shell.Tooltips | shell.InfoPanel,
+		},
+		{
+			Case:      "info panel without a key",
+			InfoPanel: &InfoPanel{},
+		},
+		{
+			Case:          "key bindings",
+			KeyBindings:   []*shell.KeyBinding{{Key: "alt+g", Action: shell.CopyAction, Segment: "git"}},
+			ExpectedFeats: shell.KeyBindings,
+		},
+	}
+
+	for _, tc := range cases {
+		env := &mock.Environment{}
+		env.On("Shell").Return(shell.ZSH)
+
+		cfg := &Config{
+			Tooltips:    tc.Tooltips,
+			InfoPanel:   tc.InfoPanel,
+			KeyBindings: tc.KeyBindings,
+			Upgrade:     &upgrade.Config{},
+		}
+
+		got := cfg.Features(env)
+		assert.Equal(t, tc.ExpectedFeats, got, tc.Case)
+	}
+}
+
 func TestFeaturesVIMode(t *testing.T) {
 	cases := []struct {
 		Case          string
@@ -323,3 +430,33 @@ func TestUpgradeFeatures(t *testing.T) {
 		cache.DeleteAll(cache.Device)
 	}
 }
+
+func TestNeedsTerminalBackground(t *testing.T) {
+	cases := []struct {
+		Config   *Config
+		Case     string
+		Expected bool
+	}{
+		{Case: "Nothing", Config: &Config{}},
+		{Case: "Palettes template", Config: &Config{Palettes: &color.Palettes{Template: "{{ .Shell }}"}}},
+		{Case: "Auto palettes", Config: &Config{Palettes: &color.Palettes{Auto: &color.AutoPalettes{Light: "latte"}}}, Expected: true},
+		{
+			Case: "Segment template",
+			Config: &Config{Blocks: []*Block{{Segments: []*Segment{
+				{Type: TEXT, Template: "{{ if .TerminalIsLight }}light{{ end }}"},
+			}}}},
+			Expected: true,
+		},
+		{
+			Case:     "Tooltip background templates",
+			Config:   &Config{Tooltips: []*Segment{{Type: TEXT, BackgroundTemplates: []string{"{{ if .TerminalIsLight }}white{{ end }}"}}}},
+			Expected: true,
+		},
+		{Case: "Partial", Config: &Config{Partials: map[string]string{"theme": "{{ if .TerminalIsLight }}light{{ end }}"}}, Expected: true},
+		{Case: "Unrelated template", Config: &Config{ConsoleTitleTemplate: "{{ .Folder }}", TransientPrompt: &Segment{Template: "> "}}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, tc.Config.needsTerminalBackground(), tc.Case)
+	}
+}
@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+// Finished is the data the transient prompt's finished_template renders with,
+// describing the command that just finished. The exit code is available as
+// the global .Code property.
+type Finished struct {
+	Timestamp time.Time
+	Duration  time.Duration
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/gob"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+func init() {
+	gob.Register(&LatencyStats{})
+}
+
+// Demotion is the mode a segment is switched to when it keeps exceeding its share
+// of the latency budget.
+type Demotion string
+
+const (
+	// DemotedAsync renders the segment pending and resolves it in the background (streaming only)
+	DemotedAsync Demotion = "async"
+	// DemotedCached renders the segment from its cache, refreshed every latencyCacheDuration
+	DemotedCached Demotion = "cached"
+
+	// latencySamples is the number of executions the rolling stats cover, latencyStrikes how many
+	// of those may exceed the share before the segment is demoted. It's promoted again once
+	// every sample fits the share.
+	latencySamples = 5
+	latencyStrikes = 3
+
+	latencyCacheDuration = cache.Duration("1m")
+	latencyCacheKey      = "latency_budget_%s"
+)
+
+// LatencyStats are a segment's latest execution times, kept in the device cache
+// so they roll over from one prompt to the next.
+type LatencyStats struct {
+	Samples []time.Duration
+	Demoted bool
+}
+
+// add records duration and returns the stats with the demotion updated for share.
+func (stats LatencyStats) add(duration, share time.Duration) *LatencyStats {
+	samples := append(slices.Clone(stats.Samples), duration)
+	if len(samples) > latencySamples {
+		samples = samples[len(samples)-latencySamples:]
+	}
+
+	exceeded := 0
+	for _, sample := range samples {
+		if sample > share {
+			exceeded++
+		}
+	}
+
+	demoted := stats.Demoted
+	switch {
+	case !demoted && exceeded >= latencyStrikes:
+		demoted = true
+	case demoted && exceeded == 0 && len(samples) == latencySamples:
+		demoted = false
+	}
+
+	return &LatencyStats{
+		Samples: samples,
+		Demoted: demoted,
+	}
+}
+
+func (segment *Segment) latencyKey() string {
+	return fmt.Sprintf(latencyCacheKey, segment.Name())
+}
+
+// ApplyLatencyBudget sets share as the segment's part of the latency budget and demotes it
+// when its stats say it keeps exceeding that share. Streaming turns it into an async segment
+// by lowering its timeout to the share, otherwise it's cached unless it already is.
+// A demotion from an earlier prompt is undone first, the stats decide again every time.
+func (segment *Segment) ApplyLatencyBudget(share time.Duration, streaming bool) {
+	if segment.Demoted == DemotedCached {
+		segment.Cache = nil
+	}
+
+	segment.Demoted = ""
+	segment.latencyShare = share
+
+	if share <= 0 {
+		return
+	}
+
+	stats, OK := cache.Get[*LatencyStats](cache.Device, segment.latencyKey())
+	if !OK || !stats.Demoted {
+		return
+	}
+
+	if streaming {
+		timeout := max(int(share.Milliseconds()), 1)
+		if segment.Timeout == 0 || segment.Timeout > timeout {
+			segment.Timeout = timeout
+		}
+
+		segment.Demoted = DemotedAsync
+		return
+	}
+
+	// a cached segment already is as fast as it gets
+	if segment.hasCache() {
+		return
+	}
+
+	segment.Cache = &Cache{
+		Duration: latencyCacheDuration,
+		Strategy: Folder,
+	}
+
+	segment.Demoted = DemotedCached
+}
+
+// recordLatency adds the time since start to the segment's stats. Restored segments
+// did not run, so they tell nothing about how long the segment takes.
+func (segment *Segment) recordLatency(start time.Time) {
+	if segment.restored {
+		return
+	}
+
+	duration := time.Since(start)
+	key := segment.latencyKey()
+
+	stats, _ := cache.Get[*LatencyStats](cache.Device, key)
+	if stats == nil {
+		stats = &LatencyStats{}
+	}
+
+	updated := stats.add(duration, segment.latencyShare)
+
+	if updated.Demoted != stats.Demoted {
+		log.Debugf("segment %s latency budget exceeded: %t (share: %s, samples: %v)", segment.Name(), updated.Demoted, segment.latencyShare, updated.Samples)
+	}
+
+	cache.Set(cache.Device, key, updated, cache.ONEWEEK)
+}
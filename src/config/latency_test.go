@@ -0,0 +1,113 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyStatsAdd(t *testing.T) {
+	share := 10 * time.Millisecond
+	slow := 20 * time.Millisecond
+	fast := 5 * time.Millisecond
+
+	cases := []struct {
+		Case     string
+		Samples  []time.Duration
+		Add      time.Duration
+		Demoted  bool
+		Expected bool
+	}{
+		{Case: "First slow sample", Add: slow},
+		{Case: "Third slow sample", Samples: []time.Duration{slow, fast, slow}, Add: slow, Expected: true},
+		{Case: "Two slow samples out of five", Samples: []time.Duration{slow, fast, slow, fast, fast}, Add: fast},
+		{Case: "Stays demoted on a single slow sample", Samples: []time.Duration{fast, fast, fast, fast, slow}, Add: fast, Demoted: true, Expected: true},
+		{Case: "Promoted once every sample fits", Samples: []time.Duration{slow, fast, fast, fast, fast}, Add: fast, Demoted: true},
+		{Case: "Stays demoted until the window is full", Samples: []time.Duration{fast}, Add: fast, Demoted: true, Expected: true},
+	}
+
+	for _, tc := range cases {
+		stats := LatencyStats{Samples: tc.Samples, Demoted: tc.Demoted}
+		updated := stats.add(tc.Add, share)
+
+		assert.Equal(t, tc.Expected, updated.Demoted, tc.Case)
+		assert.LessOrEqual(t, len(updated.Samples), latencySamples, tc.Case)
+		assert.Equal(t, tc.Add, updated.Samples[len(updated.Samples)-1], tc.Case)
+	}
+}
+
+func TestApplyLatencyBudget(t *testing.T) {
+	defer cache.DeleteAll(cache.Device)
+
+	share := 25 * time.Millisecond
+	userCache := &Cache{Duration: cache.Duration("10m"), Strategy: Session}
+
+	cases := []struct {
+		Cache           *Cache
+		ExpectedCache   *Cache
+		Case            string
+		Demoted         Demotion
+		Timeout         int
+		ExpectedTimeout int
+		Streaming       bool
+		Slow            bool
+	}{
+		{Case: "Within budget", Timeout: 100, ExpectedTimeout: 100, Streaming: true},
+		{Case: "Async when streaming", Slow: true, Streaming: true, Timeout: 100, ExpectedTimeout: 25, Demoted: DemotedAsync},
+		{Case: "Keeps a shorter timeout", Slow: true, Streaming: true, Timeout: 10, ExpectedTimeout: 10, Demoted: DemotedAsync},
+		{Case: "Cached without streaming", Slow: true, ExpectedCache: &Cache{Duration: latencyCacheDuration, Strategy: Folder}, Demoted: DemotedCached},
+		{Case: "Already cached", Slow: true, Cache: userCache, ExpectedCache: userCache},
+	}
+
+	for _, tc := range cases {
+		cache.DeleteAll(cache.Device)
+
+		segment := &Segment{Type: TEXT, Timeout: tc.Timeout, Cache: tc.Cache}
+		cache.Set(cache.Device, segment.latencyKey(), &LatencyStats{Demoted: tc.Slow}, cache.ONEWEEK)
+
+		segment.ApplyLatencyBudget(share, tc.Streaming)
+
+		assert.Equal(t, tc.Demoted, segment.Demoted, tc.Case)
+		assert.Equal(t, tc.ExpectedTimeout, segment.Timeout, tc.Case)
+		assert.Equal(t, tc.ExpectedCache, segment.Cache, tc.Case)
+	}
+}
+
+func TestApplyLatencyBudgetPromotion(t *testing.T) {
+	defer cache.DeleteAll(cache.Device)
+
+	segment := &Segment{Type: TEXT}
+	cache.Set(cache.Device, segment.latencyKey(), &LatencyStats{Demoted: true}, cache.ONEWEEK)
+
+	segment.ApplyLatencyBudget(time.Millisecond, false)
+	assert.Equal(t, DemotedCached, segment.Demoted)
+
+	cache.Set(cache.Device, segment.latencyKey(), &LatencyStats{}, cache.ONEWEEK)
+
+	segment.ApplyLatencyBudget(time.Millisecond, false)
+	assert.Empty(t, segment.Demoted)
+	assert.Nil(t, segment.Cache, "the cache a demotion added is removed again")
+}
+
+func TestRecordLatency(t *testing.T) {
+	defer cache.DeleteAll(cache.Device)
+
+	segment := &Segment{Type: TEXT, latencyShare: time.Millisecond}
+
+	for range latencyStrikes {
+		segment.recordLatency(time.Now().Add(-time.Second))
+	}
+
+	stats, OK := cache.Get[*LatencyStats](cache.Device, segment.latencyKey())
+	assert.True(t, OK)
+	assert.True(t, stats.Demoted)
+	assert.Len(t, stats.Samples, latencyStrikes)
+
+	segment.restored = true
+	segment.recordLatency(time.Now())
+
+	stats, _ = cache.Get[*LatencyStats](cache.Device, segment.latencyKey())
+	assert.Len(t, stats.Samples, latencyStrikes, "a restored segment did not run")
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+	"github.com/jandedobbeleer/oh-my-posh/src/template"
+)
+
+// LintIssue is a template issue found at a specific location in the config,
+// expressed as the path of the offending field (e.g. blocks[0].segments[1].template).
+type LintIssue struct {
+	Location string
+	template.Issue
+}
+
+type linter struct {
+	template.Linter
+	issues []LintIssue
+}
+
+func (l *linter) lint(location, text string, context any) {
+	for _, issue := range l.Lint(text, context) {
+		l.issues = append(l.issues, LintIssue{Location: location, Issue: issue})
+	}
+}
+
+func (l *linter) lintList(location string, list template.List, context any) {
+	for i, text := range list {
+		l.lint(fmt.Sprintf("%s[%d]", location, i), text, context)
+	}
+}
+
+// Lint statically checks every template in the config against the data it
+// is rendered with, without executing a single segment. Segment templates
+// are checked against their writer's type, all others against the global
+// template properties only.
+func (cfg *Config) Lint() []LintIssue {
+	l := &linter{
+		Linter: template.Linter{
+			Segments: make(map[string]any),
+		},
+	}
+
+	writers := make(map[*Segment]SegmentWriter)
+
+	addWriter := func(location string, segment *Segment) {
+		writer, err := newSegmentWriter(segment.Type)
+		if err != nil {
+			l.issues = append(l.issues, LintIssue{
+				Location: location + ".type",
+				Issue: template.Issue{
+					Severity: template.SeverityError,
+					Message:  fmt.Sprintf("unknown segment type %q", segment.Type),
+				},
+			})
+			return
+		}
+
+		writers[segment] = writer
+		l.Segments[segment.Name()] = writer
+	}
+
+	for i, block := range cfg.Blocks {
+		for j, segment := range block.Segments {
+			addWriter(fmt.Sprintf("blocks[%d].segments[%d]", i, j), segment)
+		}
+	}
+
+	for i, tooltip := range cfg.Tooltips {
+		addWriter(fmt.Sprintf("tooltips[%d]", i), tooltip)
+	}
+
+	l.lint("console_title_template", cfg.ConsoleTitleTemplate, nil)
+
+	// partials are called from templates of different segments, an untyped
+	// map accepts every property so only the syntax and functions are checked
+	var unknown map[string]any
+
+	names := make([]string, 0, len(cfg.Partials))
+	for name := range cfg.Partials {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		l.lint(fmt.Sprintf("partials.%s", name), cfg.Partials[name], unknown)
+	}
+
+	if cfg.Palettes != nil {
+		l.lint("palettes.template", cfg.Palettes.Template, nil)
+	}
+
+	// contrast is only checked against a minimum the config asks for
+	lintContrast := cfg.MinimumContrast > 0
+
+	for i, block := range cfg.Blocks {
+		for j, segment := range block.Segments {
+			location := fmt.Sprintf("blocks[%d].segments[%d]", i, j)
+			l.lintSegment(location, segment, writers[segment])
+
+			if lintContrast {
+				l.lintContrast(location, segment, cfg.Palette, cfg.MinimumContrast)
+			}
+		}
+	}
+
+	for i, tooltip := range cfg.Tooltips {
+		location := fmt.Sprintf("tooltips[%d]", i)
+		l.lintSegment(location, tooltip, writers[tooltip])
+
+		if lintContrast {
+			l.lintContrast(location, tooltip, cfg.Palette, cfg.MinimumContrast)
+		}
+	}
+
+	extras := []struct {
+		segment  *Segment
+		location string
+	}{
+		{cfg.TransientPrompt, "transient_prompt"},
+		{cfg.SecondaryPrompt, "secondary_prompt"},
+		{cfg.DebugPrompt, "debug_prompt"},
+		{cfg.ValidLine, "valid_line"},
+		{cfg.ErrorLine, "error_line"},
+	}
+
+	for _, extra := range extras {
+		if extra.segment == nil {
+			continue
+		}
+
+		l.lintSegment(extra.location, extra.segment, nil)
+	}
+
+	return l.issues
+}
+
+func (l *linter) lintSegment(location string, segment *Segment, context SegmentWriter) {
+	l.lint(location+".template", segment.Template, context)
+	l.lint(location+".right_template", segment.RightTemplate, context)
+	l.lint(location+".fallback_template", segment.FallbackTemplate, context)
+	l.lint(location+".link", segment.Link, context)
+	// when is evaluated before the segment executes, only global properties are available
+	l.lint(location+".when", segment.When, nil)
+	l.lint(location+".finished_template", segment.FinishedTemplate, &Finished{})
+	l.lintList(location+".templates", segment.Templates, context)
+	l.lintList(location+".foreground_templates", segment.ForegroundTemplates, context)
+	l.lintList(location+".background_templates", segment.BackgroundTemplates, context)
+}
+
+// lintContrast warns about every combination of the colors a segment can show that does not
+// reach the minimum contrast. Next to foreground and background, the colors are taken from the
+// literal text in foreground_templates and background_templates; only hex colors, directly or
+// through the palette, can be checked.
+func (l *linter) lintContrast(location string, segment *Segment, palette color.Palette, minimum float64) {
+	foregrounds := segmentColors(segment.Foreground, segment.ForegroundTemplates, palette)
+	backgrounds := segmentColors(segment.Background, segment.BackgroundTemplates, palette)
+
+	for _, foreground := range foregrounds {
+		for _, background := range backgrounds {
+			ratio, ok := color.HexContrastRatio(foreground.resolved, background.resolved)
+			if !ok || ratio >= minimum {
+				continue
+			}
+
+			l.issues = append(l.issues, LintIssue{
+				Location: location,
+				Issue: template.Issue{
+					Severity: template.SeverityWarning,
+					Message: fmt.Sprintf("foreground %s on background %s has a contrast ratio of %.2f, below %g",
+						foreground.name, background.name, ratio, minimum),
+				},
+			})
+		}
+	}
+}
+
+type segmentColor struct {
+	name     color.Ansi
+	resolved color.Ansi
+}
+
+func segmentColors(fixed color.Ansi, templates template.List, palette color.Palette) []segmentColor {
+	names := []color.Ansi{fixed}
+
+	for _, text := range templates {
+		names = append(names, literalColors(text)...)
+	}
+
+	var colors []segmentColor
+
+	for _, name := range names {
+		if len(name) == 0 || slices.ContainsFunc(colors, func(c segmentColor) bool { return c.name == name }) {
+			continue
+		}
+
+		resolved := name
+		if palette != nil {
+			resolved = palette.MaybeResolveColor(name)
+		}
+
+		colors = append(colors, segmentColor{name: name, resolved: resolved})
+	}
+
+	return colors
+}
+
+// literalColors returns the text between the actions of a template, like #FF0000 and p:blue in
+// {{ if .Error }}#FF0000{{ else }}p:blue{{ end }}.
+func literalColors(text string) []color.Ansi {
+	var colors []color.Ansi
+
+	for len(text) != 0 {
+		literal, rest, found := strings.Cut(text, "{{")
+		if literal = strings.TrimSpace(literal); len(literal) != 0 {
+			colors = append(colors, color.Ansi(literal))
+		}
+
+		if !found {
+			break
+		}
+
+		_, text, found = strings.Cut(rest, "}}")
+		if !found {
+			break
+		}
+	}
+
+	return colors
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+	"github.com/jandedobbeleer/oh-my-posh/src/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigLint(t *testing.T) {
+	cfg := &Config{
+		ConsoleTitleTemplate: "{{ .Folder }} {{ .Nope }}",
+		Partials: map[string]string{
+			"lang":    "{{ .Full }} {{ nope .Full }}",
+			"version": "{{ .Major }}.{{ .Minor }}",
+		},
+		Blocks: []*Block{
+			{
+				Segments: []*Segment{
+					{
+						Type:     GIT,
+						Template: "{{ .HEAD }} {{ .Working.Modified }}",
+						ForegroundTemplates: template.List{
+							"{{ if .Working.Nope }}red{{ end }}",
+						},
+					},
+					{
+						Type:     PATH,
+						Template: "{{ .Path }} {{ .Segments.Git.UpstreamIcon }}",
+					},
+					{
+						Type: "bogus",
+					},
+				},
+			},
+		},
+		TransientPrompt: &Segment{
+			Template:         "{{ .Shell }} {{ .HEAD }}",
+			FinishedTemplate: "{{ .Code }} {{ .Duration.Seconds }} {{ .Command }}",
+		},
+	}
+
+	expected := []LintIssue{
+		{
+			Location: "blocks[0].segments[2].type",
+			Issue:    template.Issue{Severity: template.SeverityError, Message: `unknown segment type "bogus"`},
+		},
+		{
+			Location: "console_title_template",
+			Issue:    template.Issue{Severity: template.SeverityError, Message: "unknown property .Nope"},
+		},
+		{
+			Location: "partials.lang",
+			Issue:    template.Issue{Severity: template.SeverityError, Message: `function "nope" is not defined`},
+		},
+		{
+			Location: "blocks[0].segments[0].foreground_templates[0]",
+			Issue:    template.Issue{Severity: template.SeverityError, Message: "unknown property .Working.Nope (segments.GitStatus has no field or method Nope)"},
+		},
+		{
+			Location: "transient_prompt.template",
+			Issue:    template.Issue{Severity: template.SeverityError, Message: "unknown property .HEAD"},
+		},
+		{
+			Location: "transient_prompt.finished_template",
+			Issue:    template.Issue{Severity: template.SeverityError, Message: "unknown property .Command"},
+		},
+	}
+
+	assert.Equal(t, expected, cfg.Lint())
+}
+
+func TestConfigLintContrast(t *testing.T) {
+	cfg := &Config{
+		Palette: color.Palette{
+			"yellow": "#F3AE35",
+			"white":  "#FFFFFF",
+		},
+		Blocks: []*Block{
+			{
+				Segments: []*Segment{
+					{
+						Type:       TEXT,
+						Foreground: "#000000",
+						Background: "p:yellow",
+						ForegroundTemplates: template.List{
+							"{{ if .Env.ERROR }}p:white{{ end }}",
+							"{{- if .Env.WARNING -}} #777777 {{- else -}} lightBlue {{- end -}}",
+						},
+					},
+					{
+						Type:       TEXT,
+						Foreground: "#FFFFFF",
+						Background: "#0000FF",
+					},
+				},
+			},
+		},
+	}
+
+	assert.Empty(t, cfg.Lint(), "no minimum_contrast")
+
+	cfg.MinimumContrast = color.WCAGAA
+
+	expected := []LintIssue{
+		{
+			Location: "blocks[0].segments[0]",
+			Issue:    template.Issue{Severity: template.SeverityWarning, Message: "foreground p:white on background p:yellow has a contrast ratio of 1.92, below 4.5"},
+		},
+		{
+			Location: "blocks[0].segments[0]",
+			Issue:    template.Issue{Severity: template.SeverityWarning, Message: "foreground #777777 on background p:yellow has a contrast ratio of 2.33, below 4.5"},
+		},
+	}
+
+	assert.Equal(t, expected, cfg.Lint())
+
+	cfg.MinimumContrast = 9
+	issues := cfg.Lint()
+	assert.Len(t, issues, 3)
+	assert.Equal(t, "foreground #FFFFFF on background #0000FF has a contrast ratio of 8.59, below 9", issues[2].Message)
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	runtimelib "runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -161,6 +162,7 @@ func Parse(configFile string) (*Config, error) {
 	cfg.migrateSegmentProperties()
 
 	cfg.toggleSegments()
+	cfg.breakWhenCycles()
 
 	if cfg.Upgrade == nil {
 		cfg.Upgrade = &upgrade.Config{
@@ -544,6 +546,18 @@ var themes = map[string]string{
 	"zash":                     "zash.omp.json",
 }
 
+// Themes returns the names of the built-in themes, which can be used as the config.
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 func isTheme(config string) (string, bool) {
 	themeFile, OK := themes[config]
 	if !OK {
@@ -0,0 +1,517 @@
+package config
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+
+	toml "github.com/pelletier/go-toml/v2"
+	yaml "go.yaml.in/yaml/v3"
+)
+
+// SchemeFormat is a terminal color scheme format a palette can be imported from.
+type SchemeFormat string
+
+const (
+	SchemeITerm2          SchemeFormat = "iterm2"
+	SchemeWindowsTerminal SchemeFormat = "windows-terminal"
+	SchemeAlacritty       SchemeFormat = "alacritty"
+	SchemeKitty           SchemeFormat = "kitty"
+	SchemeGhostty         SchemeFormat = "ghostty"
+	SchemeWezTerm         SchemeFormat = "wezterm"
+	SchemeBase16          SchemeFormat = "base16"
+)
+
+var errUnknownScheme = errors.New("unable to detect the color scheme format, use --format to set it")
+
+// ansiColorNames are the palette keys of the 16 ANSI colors, in order.
+var ansiColorNames = [16]color.Ansi{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"brightBlack", "brightRed", "brightGreen", "brightYellow", "brightBlue", "brightMagenta", "brightCyan", "brightWhite",
+}
+
+const (
+	backgroundColor = "background"
+	foregroundColor = "foreground"
+	cursorColor     = "cursor"
+)
+
+// ImportScheme reads a terminal color scheme and converts it into a palette with the 16 ANSI
+// colors, background, foreground and cursor. An empty format is detected from the file.
+func ImportScheme(file string, format SchemeFormat) (color.Palette, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(format) == 0 {
+		format = DetectSchemeFormat(file, data)
+	}
+
+	return ParseScheme(format, data)
+}
+
+// DetectSchemeFormat guesses the format of a color scheme from its extension and content.
+func DetectSchemeFormat(file string, data []byte) SchemeFormat {
+	content := string(data)
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".itermcolors":
+		return SchemeITerm2
+	case ".json":
+		return SchemeWindowsTerminal
+	case ".toml":
+		var scheme map[string]any
+		if err := toml.Unmarshal(data, &scheme); err != nil {
+			return ""
+		}
+
+		colors, _ := scheme["colors"].(map[string]any)
+		if _, ok := colors["ansi"]; ok {
+			return SchemeWezTerm
+		}
+
+		return SchemeAlacritty
+	case ".yaml", ".yml":
+		if strings.Contains(content, "base00") {
+			return SchemeBase16
+		}
+
+		return SchemeAlacritty
+	}
+
+	switch {
+	case strings.Contains(content, "<plist"):
+		return SchemeITerm2
+	case strings.HasPrefix(strings.TrimSpace(content), "{"):
+		return SchemeWindowsTerminal
+	}
+
+	// Ghostty uses key = value, kitty key value
+	for line := range strings.Lines(content) {
+		line = strings.TrimSpace(line)
+		switch {
+		case len(line) == 0, strings.HasPrefix(line, "#"):
+			continue
+		case strings.Contains(line, "="):
+			return SchemeGhostty
+		}
+
+		key, _, _ := strings.Cut(line, " ")
+		if key == backgroundColor || key == foregroundColor || strings.HasPrefix(key, "color") {
+			return SchemeKitty
+		}
+	}
+
+	return ""
+}
+
+// ParseScheme converts a color scheme in the given format into a palette.
+func ParseScheme(format SchemeFormat, data []byte) (color.Palette, error) {
+	var palette color.Palette
+	var err error
+
+	switch format {
+	case SchemeITerm2:
+		palette, err = parseITerm2(data)
+	case SchemeWindowsTerminal:
+		palette, err = parseWindowsTerminal(data)
+	case SchemeAlacritty:
+		palette, err = parseAlacritty(data)
+	case SchemeKitty:
+		palette, err = parseKitty(data)
+	case SchemeGhostty:
+		palette, err = parseGhostty(data)
+	case SchemeWezTerm:
+		palette, err = parseWezTerm(data)
+	case SchemeBase16:
+		palette, err = parseBase16(data)
+	case "":
+		return nil, errUnknownScheme
+	default:
+		return nil, fmt.Errorf("unsupported color scheme format: %s", format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(palette) == 0 {
+		return nil, fmt.Errorf("no colors found in the %s color scheme", format)
+	}
+
+	return palette, nil
+}
+
+// ImportPalette adds the colors to the palette, or to the named palette in palettes.list,
+// overwriting the keys that already exist.
+func (cfg *Config) ImportPalette(palette color.Palette, name string) {
+	target := cfg.Palette
+
+	if len(name) != 0 {
+		if cfg.Palettes == nil {
+			cfg.Palettes = &color.Palettes{}
+		}
+
+		if cfg.Palettes.List == nil {
+			cfg.Palettes.List = make(map[string]color.Palette)
+		}
+
+		target = cfg.Palettes.List[name]
+	}
+
+	if target == nil {
+		target = make(color.Palette, len(palette))
+	}
+
+	for key, value := range palette {
+		target[key] = value
+	}
+
+	if len(name) != 0 {
+		cfg.Palettes.List[name] = target
+		return
+	}
+
+	cfg.Palette = target
+}
+
+// ReadSource reads a local config file as is, without resolving what it extends,
+// so it can be written back.
+func ReadSource(configFile string) (*Config, error) {
+	configFile = resolveConfigLocation(configFile)
+	if strings.HasPrefix(configFile, "https://") {
+		return nil, fmt.Errorf("unable to write to a remote config: %s", configFile)
+	}
+
+	return read(configFile, fnv.New64a())
+}
+
+// hexColor normalizes #RGB, #RRGGBB, RRGGBB and 0xRRGGBB values to #rrggbb.
+func hexColor(value string) (color.Ansi, bool) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "#")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+
+	// drop the alpha channel
+	if len(value) == 8 {
+		value = value[:6]
+	}
+
+	if len(value) != 6 {
+		return "", false
+	}
+
+	if _, err := strconv.ParseUint(value, 16, 32); err != nil {
+		return "", false
+	}
+
+	return color.Ansi("#" + strings.ToLower(value)), true
+}
+
+// setColor adds a color to the palette when the value is a valid hex color.
+func setColor(palette color.Palette, key color.Ansi, value any) {
+	str, ok := value.(string)
+	if !ok {
+		return
+	}
+
+	if hex, ok := hexColor(str); ok {
+		palette[key] = hex
+	}
+}
+
+// setColors adds a list of ANSI colors to the palette, starting at the given offset.
+func setColors(palette color.Palette, offset int, values any) {
+	list, ok := values.([]any)
+	if !ok {
+		return
+	}
+
+	for i, value := range list {
+		if offset+i >= len(ansiColorNames) {
+			return
+		}
+
+		setColor(palette, ansiColorNames[offset+i], value)
+	}
+}
+
+// plistValue is any element of an XML property list, a dict holds alternating key and value children.
+type plistValue struct {
+	XMLName  xml.Name
+	Value    string       `xml:",chardata"`
+	Children []plistValue `xml:",any"`
+}
+
+func (p *plistValue) dict() map[string]plistValue {
+	entries := make(map[string]plistValue)
+
+	for i := 0; i+1 < len(p.Children); i += 2 {
+		if p.Children[i].XMLName.Local != "key" {
+			continue
+		}
+
+		entries[strings.TrimSpace(p.Children[i].Value)] = p.Children[i+1]
+	}
+
+	return entries
+}
+
+func (p *plistValue) rgb() (color.Ansi, bool) {
+	components := p.dict()
+
+	var channels [3]uint8
+
+	for i, name := range []string{"Red Component", "Green Component", "Blue Component"} {
+		component, ok := components[name]
+		if !ok {
+			return "", false
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(component.Value), 64)
+		if err != nil {
+			return "", false
+		}
+
+		channels[i] = uint8(math.Round(math.Max(0, math.Min(1, value)) * 255))
+	}
+
+	return color.Ansi(fmt.Sprintf("#%02x%02x%02x", channels[0], channels[1], channels[2])), true
+}
+
+func parseITerm2(data []byte) (color.Palette, error) {
+	var plist struct {
+		Dict plistValue `xml:"dict"`
+	}
+
+	if err := xml.Unmarshal(data, &plist); err != nil {
+		return nil, fmt.Errorf("invalid iTerm2 color scheme: %w", err)
+	}
+
+	keys := map[string]color.Ansi{
+		"Background Color": backgroundColor,
+		"Foreground Color": foregroundColor,
+		"Cursor Color":     cursorColor,
+	}
+
+	for i, name := range ansiColorNames {
+		keys[fmt.Sprintf("Ansi %d Color", i)] = name
+	}
+
+	palette := make(color.Palette)
+
+	for entry, value := range plist.Dict.dict() {
+		key, ok := keys[entry]
+		if !ok {
+			continue
+		}
+
+		if hex, ok := value.rgb(); ok {
+			palette[key] = hex
+		}
+	}
+
+	return palette, nil
+}
+
+func parseWindowsTerminal(data []byte) (color.Palette, error) {
+	var scheme map[string]any
+	if err := json.Unmarshal(data, &scheme); err != nil {
+		return nil, fmt.Errorf("invalid Windows Terminal color scheme: %w", err)
+	}
+
+	// Windows Terminal uses purple for magenta
+	keys := map[color.Ansi]string{
+		"magenta":       "purple",
+		"brightMagenta": "brightPurple",
+	}
+
+	palette := make(color.Palette)
+
+	for _, name := range ansiColorNames {
+		key, ok := keys[name]
+		if !ok {
+			key = string(name)
+		}
+
+		setColor(palette, name, scheme[key])
+	}
+
+	setColor(palette, backgroundColor, scheme["background"])
+	setColor(palette, foregroundColor, scheme["foreground"])
+	setColor(palette, cursorColor, scheme["cursorColor"])
+
+	return palette, nil
+}
+
+func parseAlacritty(data []byte) (color.Palette, error) {
+	var scheme map[string]any
+
+	// the TOML format replaced YAML in Alacritty 0.13, both are still around
+	if err := toml.Unmarshal(data, &scheme); err != nil {
+		if err := yaml.Unmarshal(data, &scheme); err != nil {
+			return nil, fmt.Errorf("invalid Alacritty color scheme: %w", err)
+		}
+	}
+
+	colors, _ := scheme["colors"].(map[string]any)
+	primary, _ := colors["primary"].(map[string]any)
+	normal, _ := colors["normal"].(map[string]any)
+	bright, _ := colors["bright"].(map[string]any)
+	cursor, _ := colors["cursor"].(map[string]any)
+
+	palette := make(color.Palette)
+
+	for i, name := range ansiColorNames[:8] {
+		setColor(palette, name, normal[string(name)])
+		setColor(palette, ansiColorNames[i+8], bright[string(name)])
+	}
+
+	setColor(palette, backgroundColor, primary["background"])
+	setColor(palette, foregroundColor, primary["foreground"])
+	setColor(palette, cursorColor, cursor["cursor"])
+
+	return palette, nil
+}
+
+func parseKitty(data []byte) (color.Palette, error) {
+	palette := make(color.Palette)
+
+	for line := range strings.Lines(string(data)) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		key, value := fields[0], fields[1]
+
+		switch key {
+		case backgroundColor, foregroundColor, cursorColor:
+			setColor(palette, color.Ansi(key), value)
+			continue
+		}
+
+		index, found := strings.CutPrefix(key, "color")
+		if !found {
+			continue
+		}
+
+		if i, err := strconv.Atoi(index); err == nil && i >= 0 && i < len(ansiColorNames) {
+			setColor(palette, ansiColorNames[i], value)
+		}
+	}
+
+	return palette, nil
+}
+
+func parseGhostty(data []byte) (color.Palette, error) {
+	palette := make(color.Palette)
+
+	for line := range strings.Lines(string(data)) {
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+
+		switch key {
+		case backgroundColor, foregroundColor:
+			setColor(palette, color.Ansi(key), value)
+		case "cursor-color":
+			setColor(palette, cursorColor, value)
+		case "palette":
+			// palette = 0=#1d1f21
+			index, hex, found := strings.Cut(value, "=")
+			if !found {
+				continue
+			}
+
+			if i, err := strconv.Atoi(strings.TrimSpace(index)); err == nil && i >= 0 && i < len(ansiColorNames) {
+				setColor(palette, ansiColorNames[i], hex)
+			}
+		}
+	}
+
+	return palette, nil
+}
+
+func parseWezTerm(data []byte) (color.Palette, error) {
+	var scheme map[string]any
+	if err := toml.Unmarshal(data, &scheme); err != nil {
+		return nil, fmt.Errorf("invalid WezTerm color scheme: %w", err)
+	}
+
+	colors, _ := scheme["colors"].(map[string]any)
+
+	palette := make(color.Palette)
+
+	setColors(palette, 0, colors["ansi"])
+	setColors(palette, 8, colors["brights"])
+	setColor(palette, backgroundColor, colors["background"])
+	setColor(palette, foregroundColor, colors["foreground"])
+	setColor(palette, cursorColor, colors["cursor_bg"])
+
+	return palette, nil
+}
+
+// base16ANSI maps the ANSI colors to base16 colors, the way base16-shell does.
+var base16ANSI = [16]string{
+	"base00", "base08", "base0B", "base0A", "base0D", "base0E", "base0C", "base05",
+	"base03", "base08", "base0B", "base0A", "base0D", "base0E", "base0C", "base07",
+}
+
+func parseBase16(data []byte) (color.Palette, error) {
+	var scheme map[string]any
+	if err := yaml.Unmarshal(data, &scheme); err != nil {
+		return nil, fmt.Errorf("invalid base16 color scheme: %w", err)
+	}
+
+	// the tinted-theming format nests the colors under palette
+	if nested, ok := scheme["palette"].(map[string]any); ok {
+		scheme = nested
+	}
+
+	palette := make(color.Palette)
+
+	for i := range 16 {
+		key := fmt.Sprintf("base%02X", i)
+		setColor(palette, color.Ansi(key), scheme[key])
+	}
+
+	// base16 schemes without the base keys aren't base16 schemes
+	if len(palette) == 0 {
+		return palette, nil
+	}
+
+	for i, name := range ansiColorNames {
+		if value, ok := palette[color.Ansi(base16ANSI[i])]; ok {
+			palette[name] = value
+		}
+	}
+
+	if value, ok := palette["base00"]; ok {
+		palette[backgroundColor] = value
+	}
+
+	if value, ok := palette["base05"]; ok {
+		palette[foregroundColor] = value
+		palette[cursorColor] = value
+	}
+
+	return palette, nil
+}
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const iTerm2Scheme = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Ansi 1 Color</key>
+	<dict>
+		<key>Alpha Component</key>
+		<real>1</real>
+		<key>Blue Component</key>
+		<real>0.33333333333333331</real>
+		<key>Color Space</key>
+		<string>sRGB</string>
+		<key>Green Component</key>
+		<real>0.33333333333333331</real>
+		<key>Red Component</key>
+		<real>1</real>
+	</dict>
+	<key>Background Color</key>
+	<dict>
+		<key>Blue Component</key>
+		<real>0.21176470588235294</real>
+		<key>Green Component</key>
+		<real>0.16470588235294117</real>
+		<key>Red Component</key>
+		<real>0.15686274509803921</real>
+	</dict>
+	<key>Bold Color</key>
+	<dict>
+		<key>Blue Component</key>
+		<real>1</real>
+		<key>Green Component</key>
+		<real>1</real>
+		<key>Red Component</key>
+		<real>1</real>
+	</dict>
+</dict>
+</plist>`
+
+func TestParseScheme(t *testing.T) {
+	cases := []struct {
+		Expected color.Palette
+		Case     string
+		Format   SchemeFormat
+		Data     string
+		Error    bool
+	}{
+		{
+			Case:     "iTerm2",
+			Format:   SchemeITerm2,
+			Data:     iTerm2Scheme,
+			Expected: color.Palette{"red": "#ff5555", "background": "#282a36"},
+		},
+		{
+			Case:   "Windows Terminal",
+			Format: SchemeWindowsTerminal,
+			Data: `{"name": "Campbell", "black": "#0C0C0C", "purple": "#881798", "brightPurple": "#B4009E",
+				"background": "#0C0C0C", "foreground": "#CCCCCC", "cursorColor": "#FFFFFF", "selectionBackground": "#FFFFFF"}`,
+			Expected: color.Palette{
+				"black":         "#0c0c0c",
+				"magenta":       "#881798",
+				"brightMagenta": "#b4009e",
+				"background":    "#0c0c0c",
+				"foreground":    "#cccccc",
+				"cursor":        "#ffffff",
+			},
+		},
+		{
+			Case:   "Alacritty TOML",
+			Format: SchemeAlacritty,
+			Data: `[colors.primary]
+background = '#1d1f21'
+foreground = '0xc5c8c6'
+
+[colors.normal]
+red = '#cc6666'
+
+[colors.bright]
+red = '#d54e53'
+`,
+			Expected: color.Palette{"background": "#1d1f21", "foreground": "#c5c8c6", "red": "#cc6666", "brightRed": "#d54e53"},
+		},
+		{
+			Case:   "Alacritty YAML",
+			Format: SchemeAlacritty,
+			Data: `colors:
+  primary:
+    background: '0x1d1f21'
+  cursor:
+    cursor: '#fff'
+`,
+			Expected: color.Palette{"background": "#1d1f21", "cursor": "#ffffff"},
+		},
+		{
+			Case:   "kitty",
+			Format: SchemeKitty,
+			Data: `# Nord
+foreground            #D8DEE9
+background            #2E3440
+selection_foreground  #000000
+color0   #3B4252
+color15  #ECEFF4
+color16  #ECEFF4
+`,
+			Expected: color.Palette{"foreground": "#d8dee9", "background": "#2e3440", "black": "#3b4252", "brightWhite": "#eceff4"},
+		},
+		{
+			Case:   "Ghostty",
+			Format: SchemeGhostty,
+			Data: `palette = 0=#1d1f21
+palette = 9=#d54e53
+background = 1d1f21
+cursor-color = c5c8c6
+`,
+			Expected: color.Palette{"black": "#1d1f21", "brightRed": "#d54e53", "background": "#1d1f21", "cursor": "#c5c8c6"},
+		},
+		{
+			Case:   "WezTerm",
+			Format: SchemeWezTerm,
+			Data: `[colors]
+ansi = ["#000000", "#cc0000"]
+brights = ["#555753"]
+background = "#300a24"
+cursor_bg = "#ffffff"
+
+[metadata]
+name = "Ubuntu"
+`,
+			Expected: color.Palette{"black": "#000000", "red": "#cc0000", "brightBlack": "#555753", "background": "#300a24", "cursor": "#ffffff"},
+		},
+		{
+			Case:   "base16",
+			Format: SchemeBase16,
+			Data: `scheme: "Tomorrow Night"
+base00: "1d1f21"
+base05: "c5c8c6"
+base08: "cc6666"
+`,
+			Expected: color.Palette{
+				"base00":     "#1d1f21",
+				"base05":     "#c5c8c6",
+				"base08":     "#cc6666",
+				"black":      "#1d1f21",
+				"white":      "#c5c8c6",
+				"red":        "#cc6666",
+				"brightRed":  "#cc6666",
+				"background": "#1d1f21",
+				"foreground": "#c5c8c6",
+				"cursor":     "#c5c8c6",
+			},
+		},
+		{
+			Case:     "tinted-theming base16",
+			Format:   SchemeBase16,
+			Data:     "system: base16\npalette:\n  base00: \"#1d1f21\"\n",
+			Expected: color.Palette{"base00": "#1d1f21", "black": "#1d1f21", "background": "#1d1f21"},
+		},
+		{Case: "No colors", Format: SchemeKitty, Data: "font_size 12", Error: true},
+		{Case: "Invalid JSON", Format: SchemeWindowsTerminal, Data: "{", Error: true},
+		{Case: "Unknown format", Format: "terminal.app", Data: "{}", Error: true},
+		{Case: "No format", Data: "{}", Error: true},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseScheme(tc.Format, []byte(tc.Data))
+		if tc.Error {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, got, tc.Case)
+	}
+}
+
+func TestDetectSchemeFormat(t *testing.T) {
+	cases := []struct {
+		Case     string
+		File     string
+		Data     string
+		Expected SchemeFormat
+	}{
+		{Case: "iTerm2", File: "Dracula.itermcolors", Expected: SchemeITerm2},
+		{Case: "iTerm2 by content", File: "Dracula", Data: iTerm2Scheme, Expected: SchemeITerm2},
+		{Case: "Windows Terminal", File: "campbell.json", Expected: SchemeWindowsTerminal},
+		{Case: "Alacritty TOML", File: "tomorrow.toml", Data: "[colors.primary]\nbackground = '#1d1f21'", Expected: SchemeAlacritty},
+		{Case: "WezTerm", File: "Ubuntu.toml", Data: "[colors]\nansi = [\"#000000\"]", Expected: SchemeWezTerm},
+		{Case: "Alacritty YAML", File: "tomorrow.yml", Data: "colors:\n  primary: {}", Expected: SchemeAlacritty},
+		{Case: "base16", File: "tomorrow.yaml", Data: "base00: \"1d1f21\"", Expected: SchemeBase16},
+		{Case: "kitty", File: "Nord.conf", Data: "# Nord\n\nforeground #D8DEE9", Expected: SchemeKitty},
+		{Case: "Ghostty", File: "nord", Data: "background = 2e3440", Expected: SchemeGhostty},
+		{Case: "Unknown", File: "scheme", Data: "font_size 12"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, DetectSchemeFormat(tc.File, []byte(tc.Data)), tc.Case)
+	}
+}
+
+func TestImportPalette(t *testing.T) {
+	cases := []struct {
+		Palette          color.Palette
+		Palettes         *color.Palettes
+		ExpectedPalette  color.Palette
+		ExpectedPalettes *color.Palettes
+		Case             string
+		Name             string
+	}{
+		{
+			Case:            "Empty palette",
+			ExpectedPalette: color.Palette{"red": "#ff0000"},
+		},
+		{
+			Case:            "Merge into palette",
+			Palette:         color.Palette{"red": "#cc0000", "git": "p:red"},
+			ExpectedPalette: color.Palette{"red": "#ff0000", "git": "p:red"},
+		},
+		{
+			Case:             "New palette in the list",
+			Name:             "dracula",
+			Palette:          color.Palette{"red": "#cc0000"},
+			ExpectedPalette:  color.Palette{"red": "#cc0000"},
+			ExpectedPalettes: &color.Palettes{List: map[string]color.Palette{"dracula": {"red": "#ff0000"}}},
+		},
+		{
+			Case:             "Existing palette in the list",
+			Name:             "dracula",
+			Palettes:         &color.Palettes{Template: "dracula", List: map[string]color.Palette{"dracula": {"os": "p:red"}}},
+			ExpectedPalettes: &color.Palettes{Template: "dracula", List: map[string]color.Palette{"dracula": {"os": "p:red", "red": "#ff0000"}}},
+		},
+	}
+
+	for _, tc := range cases {
+		cfg := &Config{Palette: tc.Palette, Palettes: tc.Palettes}
+		cfg.ImportPalette(color.Palette{"red": "#ff0000"}, tc.Name)
+
+		assert.Equal(t, tc.ExpectedPalette, cfg.Palette, tc.Case)
+		assert.Equal(t, tc.ExpectedPalettes, cfg.Palettes, tc.Case)
+	}
+}
+
+func TestImportSchemeIntoConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	scheme := filepath.Join(dir, "Tomorrow Night.conf")
+	require.NoError(t, os.WriteFile(scheme, []byte("background #1d1f21\ncolor1 #cc6666\n"), 0644))
+
+	configFile := filepath.Join(dir, "test.omp.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(`{"version": 4, "extends": "other.omp.json", "palette": {"os": "#ffffff"}}`), 0644))
+
+	palette, err := ImportScheme(scheme, "")
+	require.NoError(t, err)
+
+	cfg, err := ReadSource(configFile)
+	require.NoError(t, err)
+
+	cfg.ImportPalette(palette, "")
+	require.NoError(t, cfg.Backup())
+	require.NoError(t, cfg.Write(cfg.Format))
+
+	_, err = os.Stat(configFile + ".bak")
+	assert.NoError(t, err)
+
+	written, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+
+	got, err := ParseBytes(JSON, written)
+	require.NoError(t, err)
+
+	assert.Equal(t, "other.omp.json", got.Extends)
+	assert.Equal(t, color.Palette{"os": "#ffffff", "background": "#1d1f21", "red": "#cc6666"}, got.Palette)
+}
+
+func TestReadSourceRemote(t *testing.T) {
+	_, err := ReadSource("https://example.com/test.omp.json")
+	assert.Error(t, err)
+}
+
+func TestBackupAndWriteErrors(t *testing.T) {
+	cfg := &Config{Source: filepath.Join(t.TempDir(), "missing", "test.omp.json"), Format: JSON}
+
+	assert.Error(t, cfg.Backup())
+	assert.Error(t, cfg.Write(JSON))
+
+	cfg.Source = filepath.Join(t.TempDir(), "test.omp.json")
+	assert.EqualError(t, cfg.Write("ini"), `unable to export the config as "ini"`)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -70,8 +71,13 @@ type Segment struct {
 	Style                  SegmentStyle   `json:"style,omitempty" toml:"style,omitempty" yaml:"style,omitempty"`
 	LeadingPowerlineSymbol string         `json:"leading_powerline_symbol,omitempty" toml:"leading_powerline_symbol,omitempty" yaml:"leading_powerline_symbol,omitempty"`
 	Placeholder            string         `json:"placeholder,omitempty" toml:"placeholder,omitempty" yaml:"placeholder,omitempty"`
+	Demoted                Demotion       `json:"-" toml:"-" yaml:"-"`
 	FallbackTemplate       string         `json:"fallback_template,omitempty" toml:"fallback_template,omitempty" yaml:"fallback_template,omitempty"`
+	FinishedTemplate       string         `json:"finished_template,omitempty" toml:"finished_template,omitempty" yaml:"finished_template,omitempty"`
+	When                   string         `json:"when,omitempty" toml:"when,omitempty" yaml:"when,omitempty"`
+	Link                   string         `json:"link,omitempty" toml:"link,omitempty" yaml:"link,omitempty"`
 	Tips                   []string       `json:"tips,omitempty" toml:"tips,omitempty" yaml:"tips,omitempty"`
+	TipsRegex              []string       `json:"tips_regex,omitempty" toml:"tips_regex,omitempty" yaml:"tips_regex,omitempty"`
 	BackgroundTemplates    template.List  `json:"background_templates,omitempty" toml:"background_templates,omitempty" yaml:"background_templates,omitempty"`
 	Templates              template.List  `json:"templates,omitempty" toml:"templates,omitempty" yaml:"templates,omitempty"`
 	ExcludeFolders         []string       `json:"exclude_folders,omitempty" toml:"exclude_folders,omitempty" yaml:"exclude_folders,omitempty"`
@@ -82,6 +88,7 @@ type Segment struct {
 	Index                  int           `json:"index,omitempty" toml:"index,omitempty" yaml:"index,omitempty"`
 	MinWidth               int           `json:"min_width,omitempty" toml:"min_width,omitempty" yaml:"min_width,omitempty"`
 	Duration               time.Duration `json:"-" toml:"-" yaml:"-"`
+	latencyShare           time.Duration `json:"-" toml:"-" yaml:"-"`
 	NameLength             int           `json:"-" toml:"-" yaml:"-"`
 	MaxWidth               int           `json:"max_width,omitempty" toml:"max_width,omitempty" yaml:"max_width,omitempty"`
 	Timeout                int           `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
@@ -99,6 +106,7 @@ type Segment struct {
 	backgroundResolved     bool
 	needsEvaluated         bool
 	evaluated              bool
+	whenCycle              bool
 }
 
 // A nil presentFields map means presence was never recorded, in which case every
@@ -187,7 +195,7 @@ func (segment *Segment) Name() string {
 func (segment *Segment) Execute(env runtime.Environment) {
 	// segment timings for debug purposes
 	var start time.Time
-	if env.Flags().Debug {
+	if env.Flags().Debug || env.Flags().Profile {
 		start = time.Now()
 		segment.NameLength = len(segment.Name())
 		defer func() {
@@ -195,10 +203,18 @@ func (segment *Segment) Execute(env runtime.Environment) {
 		}()
 	}
 
+	if log.Tracing() {
+		defer log.Span(time.Now(), "segment", segment.Name())
+	}
+
+	if segment.latencyShare > 0 {
+		defer segment.recordLatency(time.Now())
+	}
+
 	defer segment.evaluateNeeds()
 
 	err := segment.MapSegmentWithWriter(env)
-	if err != nil || !segment.shouldIncludeFolder() {
+	if err != nil || !segment.shouldIncludeFolder() || !segment.whenSatisfied() {
 		return
 	}
 
@@ -336,7 +352,7 @@ func (segment *Segment) Render(index int, force bool) bool {
 		}
 	}
 
-	segment.SetText(rendered)
+	segment.SetText(segment.link(rendered))
 	segment.setCache()
 
 	// We do this to make `.Text` available for a cross-segment reference in an extra prompt.
@@ -385,6 +401,27 @@ func (segment *Segment) renderFallback(index int) bool {
 	return true
 }
 
+// link wraps the rendered text in a hyperlink to the URL the link template renders to. Text that
+// already holds a hyperlink is left as is, hyperlinks can't be nested.
+func (segment *Segment) link(text string) string {
+	if len(segment.Link) == 0 || segment.Pending || strings.Contains(text, "<LINK>") {
+		return text
+	}
+
+	url, err := template.RenderTrusted(segment.Link, segment.templateContext())
+	if err != nil {
+		log.Error(err)
+		return text
+	}
+
+	url = strings.TrimSpace(url)
+	if len(url) == 0 {
+		return text
+	}
+
+	return template.Hyperlink(text, url)
+}
+
 func (segment *Segment) Text() string {
 	if segment.writer == nil {
 		return segment.text
@@ -499,17 +536,17 @@ func (segment *Segment) Writer() SegmentWriter {
 }
 
 func (segment *Segment) isToggled() bool {
-	togglesMap, OK := cache.Get[map[string]bool](cache.Session, cache.TOGGLECACHE)
-	if !OK || len(togglesMap) == 0 {
-		log.Debug("no toggles found")
-		return false
-	}
-
+	togglesMap, _ := cache.Get[map[string]bool](cache.Session, cache.TOGGLECACHE)
 	if togglesMap[segment.DataKey()] {
 		log.Debugf("segment toggled off: %s", segment.Name())
 		return true
 	}
 
+	if toggledInScope(segment.env, segment.DataKey()) {
+		log.Debugf("segment toggled off in scope: %s", segment.Name())
+		return true
+	}
+
 	return false
 }
 
@@ -782,6 +819,12 @@ func (segment *Segment) folderKey() string {
 	return key
 }
 
+// RenderTemplate renders a template against the segment's data, like the
+// segment's own template does.
+func (segment *Segment) RenderTemplate(tpl string) (string, error) {
+	return template.RenderTrusted(tpl, segment.templateContext())
+}
+
 // templateContext is what a template evaluates against: the writer when one was constructed, the
 // recorded data map when none was (see Segment.data).
 func (segment *Segment) templateContext() any {
@@ -844,6 +887,59 @@ func (segment *Segment) cwdExcluded() bool {
 	return segment.env.DirMatchesOneOf(segment.env.Pwd(), segment.ExcludeFolders)
 }
 
+// whenSatisfied evaluates the when expression before anything is executed,
+// so a segment that is not needed never runs its (possibly expensive) logic.
+// The expression only has the global template properties at its disposal,
+// a non empty result other than false (or 0) counts as true.
+func (segment *Segment) whenSatisfied() bool {
+	if len(segment.When) == 0 {
+		return true
+	}
+
+	text, err := template.RenderTrusted(segment.When, nil)
+	if err != nil {
+		log.Errorf("segment %s: invalid when expression: %v", segment.Name(), err)
+		return false
+	}
+
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return false
+	}
+
+	if value, err := strconv.ParseBool(text); err == nil {
+		return value
+	}
+
+	return true
+}
+
+// WhenNeeds returns the other segments the when expression refers to, these
+// need to be executed first for their state to be known.
+func (segment *Segment) WhenNeeds() []string {
+	if segment.whenCycle || !strings.Contains(segment.When, ".Segments.") {
+		return nil
+	}
+
+	var needs []string
+
+	matches := regex.FindAllNamedRegexMatch(`\.Segments\.(?:Contains\s+"(?P<CONTAINS>[a-zA-Z0-9]+)"|(?P<NAME>[a-zA-Z0-9]+))`, segment.When)
+	for _, match := range matches {
+		name := match["NAME"]
+		if len(name) == 0 {
+			name = match["CONTAINS"]
+		}
+
+		if len(name) == 0 || name == segment.Name() || slices.Contains(needs, name) {
+			continue
+		}
+
+		needs = append(needs, name)
+	}
+
+	return needs
+}
+
 func (segment *Segment) evaluateNeeds() {
 	if segment.needsEvaluated {
 		return
@@ -872,6 +968,10 @@ func (segment *Segment) evaluateNeeds() {
 		value += segment.FallbackTemplate
 	}
 
+	if segment.Link != "" {
+		value += segment.Link
+	}
+
 	if !strings.Contains(value, ".Segments.") {
 		return
 	}
@@ -15,10 +15,11 @@ import (
 )
 
 // segmentCatalogAliases maps a registered SegmentType to the doc id it is published
-// under, for the two cases where the two disagree.
+// under, for the cases where the two disagree.
 var segmentCatalogAliases = map[SegmentType]string{
 	GOLANG:     "golang",
 	COPILOTCLI: "copilot-cli",
+	RECENTDIRS: "recent-dirs",
 }
 
 // segmentCatalogEntry is one row of the generated website/plugins/segments/registry.json.
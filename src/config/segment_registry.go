@@ -66,6 +66,8 @@ func init() {
 	gob.Register(&segments.Rebase{})
 	gob.Register(&segments.User{})
 	gob.Register(&segments.Commit{})
+	gob.Register(&segments.PullRequest{})
+	gob.Register(&segments.PullRequestCache{})
 	gob.Register(&segments.GitVersion{})
 	gob.Register(&segments.Golang{})
 	gob.Register(&segments.Gradle{})
@@ -223,6 +225,7 @@ var Segments = map[SegmentType]func() SegmentWriter{
 	R:               func() SegmentWriter { return segments.NewLanguage(string(R)) },
 	RAMADAN:         func() SegmentWriter { return &segments.Ramadan{} },
 	REACT:           func() SegmentWriter { return &segments.React{} },
+	RECENTDIRS:      func() SegmentWriter { return &segments.RecentDirs{} },
 	ROOT:            func() SegmentWriter { return &segments.Root{} },
 	RUBY:            func() SegmentWriter { return segments.NewLanguage(string(RUBY)) },
 	RUST:            func() SegmentWriter { return segments.NewLanguage(string(RUST)) },
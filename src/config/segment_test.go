@@ -319,6 +319,92 @@ func TestEvaluateNeeds(t *testing.T) {
 	}
 }
 
+func TestWhenSatisfied(t *testing.T) {
+	cases := []struct {
+		Case     string
+		When     string
+		Expected bool
+	}{
+		{Case: "No expression", Expected: true},
+		{Case: "True", When: `{{ eq .Shell "pwsh" }}`, Expected: true},
+		{Case: "False", When: `{{ eq .Shell "zsh" }}`},
+		{Case: "Environment variable set", When: "{{ .Env.KUBECONFIG }}", Expected: true},
+		{Case: "Environment variable not set", When: "{{ .Env.NOPE }}"},
+		{Case: "Zero", When: "{{ len .Env.NOPE }}"},
+		{Case: "Other segment disabled", When: `{{ .Segments.Contains "Git" }}`},
+		{Case: "Invalid expression", When: "{{ nope .Shell }}"},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Shell").Return("pwsh")
+		env.On("Getenv", "KUBECONFIG").Return("~/.kube/config")
+		env.On("Getenv", "NOPE").Return("")
+
+		template.Cache = &cache.Template{
+			SimpleTemplate: cache.SimpleTemplate{
+				Shell: "pwsh",
+			},
+			Segments: maps.NewConcurrent[any](),
+		}
+		template.Init(env, nil, nil)
+
+		segment := &Segment{
+			Type: TEXT,
+			When: tc.When,
+		}
+
+		assert.Equal(t, tc.Expected, segment.whenSatisfied(), tc.Case)
+	}
+}
+
+func TestWhenNeeds(t *testing.T) {
+	cases := []struct {
+		Case     string
+		When     string
+		Expected []string
+	}{
+		{Case: "No expression"},
+		{Case: "No segments", When: `{{ eq .Shell "pwsh" }}`},
+		{Case: "Property", When: "{{ .Segments.Git.Detached }}", Expected: []string{"Git"}},
+		{Case: "Contains", When: `{{ .Segments.Contains "Kubectl" }}`, Expected: []string{"Kubectl"}},
+		{Case: "Multiple", When: `{{ and (.Segments.Contains "Git") .Segments.Git.Detached (.Segments.Contains "Os") }}`, Expected: []string{"Git", "Os"}},
+		{Case: "Self", When: `{{ not (.Segments.Contains "Text") }}`},
+	}
+
+	for _, tc := range cases {
+		segment := &Segment{
+			Type: TEXT,
+			When: tc.When,
+		}
+
+		assert.Equal(t, tc.Expected, segment.WhenNeeds(), tc.Case)
+	}
+}
+
+func TestBreakWhenCycles(t *testing.T) {
+	git := &Segment{Type: GIT, When: `{{ .Segments.Contains "Path" }}`}
+	path := &Segment{Type: PATH, When: `{{ .Segments.Contains "Session" }}`}
+	session := &Segment{Type: SESSION, When: `{{ .Segments.Contains "Git" }}`}
+	platform := &Segment{Type: OS, When: `{{ .Segments.Contains "Git" }}`}
+	text := &Segment{Type: TEXT, When: `{{ .Segments.Contains "Os" }}`}
+
+	cfg := &Config{
+		Blocks: []*Block{
+			{Segments: []*Segment{platform, git, path}},
+			{Segments: []*Segment{session, text}},
+		},
+	}
+
+	cfg.breakWhenCycles()
+
+	assert.Empty(t, git.WhenNeeds(), "part of the cycle")
+	assert.Empty(t, path.WhenNeeds(), "part of the cycle")
+	assert.Empty(t, session.WhenNeeds(), "part of the cycle")
+	assert.Equal(t, []string{"Git"}, platform.WhenNeeds(), "refers to the cycle")
+	assert.Equal(t, []string{"Os"}, text.WhenNeeds(), "no cycle")
+}
+
 func TestSegment_NoCachingWhenPending(t *testing.T) {
 	env := new(mock.Environment)
 	env.On("Shell").Return("pwsh")
@@ -831,3 +917,75 @@ func TestSegment_FallbackTemplate(t *testing.T) {
 		assert.False(t, found, tc.Case)
 	}
 }
+
+func TestMatchesTip(t *testing.T) {
+	cases := []struct {
+		Case      string
+		Line      string
+		Tips      []string
+		TipsRegex []string
+		Expected  bool
+	}{
+		{Case: "first word", Tips: []string{"git"}, Line: "git push", Expected: true},
+		{Case: "first word only", Tips: []string{"git"}, Line: "git", Expected: true},
+		{Case: "other command", Tips: []string{"git"}, Line: "gitk --all"},
+		{Case: "command prefix", Tips: []string{"git push"}, Line: "git push origin", Expected: true},
+		{Case: "command prefix with extra spaces", Tips: []string{"git push"}, Line: "git   push", Expected: true},
+		{Case: "command prefix not typed yet", Tips: []string{"git push"}, Line: "git"},
+		{Case: "other subcommand", Tips: []string{"git push"}, Line: "git pull"},
+		{Case: "regex", TipsRegex: []string{`^kubectl .*-n\b`}, Line: "kubectl get pods -n kube-system", Expected: true},
+		{Case: "regex without a match", TipsRegex: []string{`^kubectl .*-n\b`}, Line: "kubectl get pods"},
+		{Case: "empty line", Tips: []string{"git"}, TipsRegex: []string{`.*`}},
+	}
+
+	for _, tc := range cases {
+		segment := &Segment{Tips: tc.Tips, TipsRegex: tc.TipsRegex}
+		assert.Equal(t, tc.Expected, segment.MatchesTip(tc.Line), tc.Case)
+	}
+}
+
+func TestSegment_Link(t *testing.T) {
+	cases := []struct {
+		Case         string
+		Shell        string
+		Template     string
+		Link         string
+		ExpectedText string
+	}{
+		{Case: "No link", Template: "docs", ExpectedText: "docs"},
+		{Case: "Link", Template: "docs", Link: "https://ohmyposh.dev", ExpectedText: "<LINK>https://ohmyposh.dev<TEXT>docs</TEXT></LINK>"},
+		{Case: "Link using the segment data", Template: "docs", Link: "https://ohmyposh.dev/{{ .Text }}", ExpectedText: "<LINK>https://ohmyposh.dev/docs<TEXT>docs</TEXT></LINK>"},
+		{Case: "Empty link", Template: "docs", Link: "{{ if false }}https://ohmyposh.dev{{ end }}", ExpectedText: "docs"},
+		{Case: "Invalid link template", Template: "docs", Link: "{{ .Unknown }}", ExpectedText: "docs"},
+		{
+			Case:         "Text with a link",
+			Template:     `{{ url "docs" "https://ohmyposh.dev" }}`,
+			Link:         "https://github.com",
+			ExpectedText: "<LINK>https://ohmyposh.dev<TEXT>docs</TEXT></LINK>",
+		},
+		{Case: "Shell without hyperlinks", Shell: "elvish", Template: "docs", Link: "https://ohmyposh.dev", ExpectedText: "docs"},
+	}
+
+	initTemplateCache(t)
+
+	for _, tc := range cases {
+		if len(tc.Shell) == 0 {
+			tc.Shell = "pwsh"
+		}
+
+		env := new(mock.Environment)
+		env.On("Shell").Return(tc.Shell)
+		template.Init(env, nil, nil)
+
+		segment := &Segment{
+			Type:     TEXT,
+			Template: tc.Template,
+			Link:     tc.Link,
+			Enabled:  true,
+			writer:   &fallbackWriter{enabled: true, template: tc.Template, text: "docs"},
+		}
+
+		assert.True(t, segment.Render(0, false), tc.Case)
+		assert.Equal(t, tc.ExpectedText, segment.Text(), tc.Case)
+	}
+}
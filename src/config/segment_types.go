@@ -188,6 +188,8 @@ const (
 	RAMADAN SegmentType = "ramadan"
 	// REACT writes the current react version
 	REACT SegmentType = "react"
+	// RECENTDIRS writes the most frecent or most recently visited folders
+	RECENTDIRS SegmentType = "recent_dirs"
 	// ROOT writes root symbol
 	ROOT SegmentType = "root"
 	// RUBY writes which ruby version is currently active
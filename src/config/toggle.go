@@ -0,0 +1,201 @@
+package config
+
+import (
+	"encoding/gob"
+	runtimelib "runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+)
+
+func init() {
+	gob.Register([]*Toggle{})
+}
+
+type ToggleScope string
+
+const (
+	// SessionScope hides a segment in the current shell session
+	SessionScope ToggleScope = "session"
+	// FolderScope hides a segment in a folder and its subfolders, in every session
+	FolderScope ToggleScope = "folder"
+	// GlobalScope hides a segment everywhere, in every session
+	GlobalScope ToggleScope = "global"
+)
+
+// Toggle hides a segment within a scope, until it expires when Expires is set.
+// The plain session toggles, without an expiry, live in cache.TOGGLECACHE instead.
+type Toggle struct {
+	Expires time.Time   `json:"expires,omitzero"`
+	Segment string      `json:"segment"`
+	Scope   ToggleScope `json:"scope"`
+	Folder  string      `json:"folder,omitempty"`
+}
+
+func (t *Toggle) expired(now time.Time) bool {
+	return !t.Expires.IsZero() && !now.Before(t.Expires)
+}
+
+// covers tells if the toggle applies to the given working directory.
+func (t *Toggle) covers(pwd string, caseInsensitive bool) bool {
+	if t.Scope != FolderScope {
+		return true
+	}
+
+	folder := strings.TrimRight(t.Folder, `/\`)
+	if caseInsensitive {
+		folder = strings.ToLower(folder)
+		pwd = strings.ToLower(pwd)
+	}
+
+	if !strings.HasPrefix(pwd, folder) {
+		return false
+	}
+
+	rest := pwd[len(folder):]
+	return len(rest) == 0 || rest[0] == '/' || rest[0] == '\\'
+}
+
+func (t *Toggle) same(other *Toggle) bool {
+	return t.Segment == other.Segment && t.Scope == other.Scope && t.Folder == other.Folder
+}
+
+// matching returns the index of the toggle that hides the same segment within
+// the same scope, or -1. A folder toggle also matches from its subfolders, when
+// several do the nearest folder wins.
+func (t *Toggle) matching(toggles []*Toggle, caseInsensitive bool) int {
+	if t.Scope != FolderScope {
+		return slices.IndexFunc(toggles, t.same)
+	}
+
+	match := -1
+
+	for i, toggle := range toggles {
+		if toggle.Segment != t.Segment || toggle.Scope != FolderScope || !toggle.covers(t.Folder, caseInsensitive) {
+			continue
+		}
+
+		if match == -1 || len(toggle.Folder) > len(toggles[match].Folder) {
+			match = i
+		}
+	}
+
+	return match
+}
+
+func (scope ToggleScope) store() cache.Store {
+	if scope == SessionScope {
+		return cache.Session
+	}
+
+	return cache.Device
+}
+
+// scopedToggles returns the toggles in the store that did not expire yet.
+func scopedToggles(store cache.Store, now time.Time) []*Toggle {
+	toggles, _ := cache.Get[[]*Toggle](store, cache.SCOPEDTOGGLECACHE)
+
+	return slices.DeleteFunc(slices.Clone(toggles), func(t *Toggle) bool {
+		return t.expired(now)
+	})
+}
+
+// Toggles returns every active toggle, the plain session ones included.
+func Toggles(now time.Time) []*Toggle {
+	var toggles []*Toggle
+
+	sessionToggles, _ := cache.Get[map[string]bool](cache.Session, cache.TOGGLECACHE)
+	for segment := range sessionToggles {
+		toggles = append(toggles, &Toggle{Segment: segment, Scope: SessionScope})
+	}
+
+	slices.SortFunc(toggles, func(a, b *Toggle) int {
+		return strings.Compare(a.Segment, b.Segment)
+	})
+
+	toggles = append(toggles, scopedToggles(cache.Session, now)...)
+	toggles = append(toggles, scopedToggles(cache.Device, now)...)
+
+	return toggles
+}
+
+// ToggleSegments toggles the segments on or off within the scope. A duration
+// other than 0 only hides a segment for that amount of time, folder is the
+// directory a folder scoped toggle applies to. Toggling from a subfolder turns
+// the segment back on when a parent folder hides it.
+func ToggleSegments(segments []string, scope ToggleScope, folder string, duration time.Duration) {
+	if scope == SessionScope && duration == 0 {
+		toggleSessionSegments(segments)
+		return
+	}
+
+	if scope != FolderScope {
+		folder = ""
+	}
+
+	now := time.Now()
+	store := scope.store()
+	toggles := scopedToggles(store, now)
+	caseInsensitive := runtimelib.GOOS == runtime.WINDOWS
+
+	for _, segment := range segments {
+		toggle := &Toggle{
+			Segment: segment,
+			Scope:   scope,
+			Folder:  folder,
+		}
+
+		if index := toggle.matching(toggles, caseInsensitive); index != -1 {
+			toggles = slices.Delete(toggles, index, index+1)
+			continue
+		}
+
+		if duration != 0 {
+			toggle.Expires = now.Add(duration).Truncate(time.Second)
+		}
+
+		toggles = append(toggles, toggle)
+	}
+
+	cache.Set(store, cache.SCOPEDTOGGLECACHE, toggles, cache.INFINITE)
+}
+
+func toggleSessionSegments(segments []string) {
+	currentToggleSet, _ := cache.Get[map[string]bool](cache.Session, cache.TOGGLECACHE)
+	if currentToggleSet == nil {
+		currentToggleSet = make(map[string]bool)
+	}
+
+	// Toggle segments: remove if present, add if not present
+	for _, segment := range segments {
+		if currentToggleSet[segment] {
+			delete(currentToggleSet, segment)
+			continue
+		}
+
+		currentToggleSet[segment] = true
+	}
+
+	cache.Set(cache.Session, cache.TOGGLECACHE, currentToggleSet, cache.INFINITE)
+}
+
+// toggledInScope tells if a scoped toggle hides the segment with the given key.
+func toggledInScope(env runtime.Environment, key string) bool {
+	now := time.Now()
+	toggles := append(scopedToggles(cache.Session, now), scopedToggles(cache.Device, now)...)
+
+	for _, toggle := range toggles {
+		if toggle.Segment != key {
+			continue
+		}
+
+		if toggle.covers(env.Pwd(), env.GOOS() == runtime.WINDOWS) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToggleCovers(t *testing.T) {
+	cases := []struct {
+		Case            string
+		Toggle          *Toggle
+		Pwd             string
+		CaseInsensitive bool
+		Expected        bool
+	}{
+		{Case: "global", Toggle: &Toggle{Scope: GlobalScope}, Pwd: "/tmp", Expected: true},
+		{Case: "session", Toggle: &Toggle{Scope: SessionScope}, Pwd: "/tmp", Expected: true},
+		{Case: "same folder", Toggle: &Toggle{Scope: FolderScope, Folder: "/home/jan/work"}, Pwd: "/home/jan/work", Expected: true},
+		{Case: "subfolder", Toggle: &Toggle{Scope: FolderScope, Folder: "/home/jan/work/"}, Pwd: "/home/jan/work/posh", Expected: true},
+		{Case: "sibling with the same prefix", Toggle: &Toggle{Scope: FolderScope, Folder: "/home/jan/work"}, Pwd: "/home/jan/workshop"},
+		{Case: "parent folder", Toggle: &Toggle{Scope: FolderScope, Folder: "/home/jan/work"}, Pwd: "/home/jan"},
+		{Case: "windows", Toggle: &Toggle{Scope: FolderScope, Folder: `C:\Work`}, Pwd: `c:\work\posh`, CaseInsensitive: true, Expected: true},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, tc.Toggle.covers(tc.Pwd, tc.CaseInsensitive), tc.Case)
+	}
+}
+
+func TestToggleSegments(t *testing.T) {
+	t.Cleanup(func() {
+		cache.Delete(cache.Session, cache.TOGGLECACHE)
+		cache.Delete(cache.Session, cache.SCOPEDTOGGLECACHE)
+		cache.Delete(cache.Device, cache.SCOPEDTOGGLECACHE)
+	})
+
+	ToggleSegments([]string{"aws", "git"}, SessionScope, "/home/jan", 0)
+	ToggleSegments([]string{"node"}, FolderScope, "/home/jan/work", 0)
+	ToggleSegments([]string{"kubectl"}, GlobalScope, "/home/jan", time.Hour)
+	ToggleSegments([]string{"git"}, SessionScope, "/home/jan", 0)
+
+	toggles := Toggles(time.Now())
+	assert.Len(t, toggles, 3)
+
+	assert.Equal(t, &Toggle{Segment: "aws", Scope: SessionScope}, toggles[0])
+	assert.Equal(t, &Toggle{Segment: "node", Scope: FolderScope, Folder: "/home/jan/work"}, toggles[1])
+
+	assert.Equal(t, "kubectl", toggles[2].Segment)
+	assert.Equal(t, GlobalScope, toggles[2].Scope)
+	assert.Empty(t, toggles[2].Folder, "only folder toggles keep the folder")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), toggles[2].Expires, time.Minute)
+
+	assert.Len(t, Toggles(time.Now().Add(2*time.Hour)), 2, "expired toggles are left out")
+
+	ToggleSegments([]string{"node"}, FolderScope, "/home/jan/work", 0)
+	assert.Len(t, Toggles(time.Now()), 2, "toggling again within the same scope turns the segment back on")
+}
+
+func TestSegmentIsToggledInScope(t *testing.T) {
+	t.Cleanup(func() {
+		cache.Delete(cache.Device, cache.SCOPEDTOGGLECACHE)
+	})
+
+	cache.Set(cache.Device, cache.SCOPEDTOGGLECACHE, []*Toggle{
+		{Segment: "node", Scope: FolderScope, Folder: "/home/jan/work"},
+		{Segment: "aws", Scope: GlobalScope, Expires: time.Now().Add(-time.Minute)},
+	}, cache.INFINITE)
+
+	cases := []struct {
+		Case     string
+		Segment  *Segment
+		Pwd      string
+		Expected bool
+	}{
+		{Case: "inside the folder", Segment: &Segment{Type: NODE}, Pwd: "/home/jan/work/posh", Expected: true},
+		{Case: "outside the folder", Segment: &Segment{Type: NODE}, Pwd: "/home/jan"},
+		{Case: "expired", Segment: &Segment{Type: AWS}, Pwd: "/home/jan"},
+		{Case: "alias", Segment: &Segment{Type: TEXT, Alias: "node"}, Pwd: "/home/jan/work", Expected: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return(tc.Pwd)
+		env.On("GOOS").Return(runtime.LINUX)
+
+		tc.Segment.env = env
+
+		assert.Equal(t, tc.Expected, tc.Segment.isToggled(), tc.Case)
+	}
+}
+
+func TestToggleSegmentsFromSubfolder(t *testing.T) {
+	t.Cleanup(func() {
+		cache.Delete(cache.Device, cache.SCOPEDTOGGLECACHE)
+	})
+
+	cache.Set(cache.Device, cache.SCOPEDTOGGLECACHE, []*Toggle{
+		{Segment: "node", Scope: FolderScope, Folder: "/home/jan"},
+		{Segment: "node", Scope: FolderScope, Folder: "/home/jan/work"},
+		{Segment: "git", Scope: FolderScope, Folder: "/home/jan/work/"},
+	}, cache.INFINITE)
+
+	ToggleSegments([]string{"node", "git"}, FolderScope, "/home/jan/work/posh", 0)
+
+	assert.Equal(t, []*Toggle{
+		{Segment: "node", Scope: FolderScope, Folder: "/home/jan"},
+	}, Toggles(time.Now()), "the nearest parent folder hiding the segment is turned back on")
+
+	ToggleSegments([]string{"node"}, FolderScope, "/home/jane", 0)
+
+	assert.Len(t, Toggles(time.Now()), 2, "a sibling with the same prefix is not a subfolder")
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/regex"
+)
+
+// InfoPanel renders the tooltips matching the command line on request,
+// rather than when typing a space.
+type InfoPanel struct {
+	// Key is the key chord that shows the panel, like alt+i or ctrl+x.
+	Key string `json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// MatchesTip reports whether the tooltip applies to the command line typed so
+// far. A tip matches the leading words of the command line (kubectl, or
+// terraform apply), a regex in tips_regex can match any part of it.
+func (segment *Segment) MatchesTip(line string) bool {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return false
+	}
+
+	for _, tip := range segment.Tips {
+		if matchesWords(words, strings.Fields(tip)) {
+			return true
+		}
+	}
+
+	return slices.ContainsFunc(segment.TipsRegex, func(pattern string) bool {
+		return regex.MatchString(pattern, line)
+	})
+}
+
+func matchesWords(words, tip []string) bool {
+	if len(tip) == 0 || len(tip) > len(words) {
+		return false
+	}
+
+	for i, word := range tip {
+		if words[i] != word {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tooltipsNeedCommandLine reports whether a tooltip can match more than the
+// first word, in which case the shell has to pass along the whole command line.
+func (cfg *Config) tooltipsNeedCommandLine() bool {
+	for _, tooltip := range cfg.Tooltips {
+		if len(tooltip.TipsRegex) != 0 {
+			return true
+		}
+
+		for _, tip := range tooltip.Tips {
+			if len(strings.Fields(tip)) > 1 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,289 @@
+// Package frecency keeps track of the folders visited at the prompt, ranked on
+// how often (frequency) and how long ago (recency) they were visited.
+package frecency
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+const (
+	FileName = "frecency.json"
+
+	defaultMaxEntries = 500
+
+	// lockTimeout is how long a visit waits for another shell to finish its
+	// update, a lock older than staleLock was left behind by a process that
+	// died while holding it.
+	lockTimeout = 500 * time.Millisecond
+	staleLock   = 5 * time.Second
+)
+
+// Config enables the frecency database, every folder the prompt renders in
+// gets recorded once the property is set.
+type Config struct {
+	// MaxEntries is the number of folders to remember, the ones with the
+	// lowest score are dropped first.
+	MaxEntries int `json:"max_entries,omitempty" toml:"max_entries,omitempty" yaml:"max_entries,omitempty"`
+}
+
+func (cfg *Config) maxEntries() int {
+	if cfg == nil || cfg.MaxEntries <= 0 {
+		return defaultMaxEntries
+	}
+
+	return cfg.MaxEntries
+}
+
+type Entry struct {
+	Path       string  `json:"path"`
+	Rank       float64 `json:"rank"`
+	LastAccess int64   `json:"last_access"`
+}
+
+// Score weighs the number of visits against the time of the last one, a
+// folder visited within the hour counts four times as much as one visited
+// within the week.
+func (e *Entry) Score(now time.Time) float64 {
+	age := now.Sub(time.Unix(e.LastAccess, 0))
+
+	switch {
+	case age < time.Hour:
+		return e.Rank * 4
+	case age < 24*time.Hour:
+		return e.Rank * 2
+	case age < 7*24*time.Hour:
+		return e.Rank / 2
+	default:
+		return e.Rank / 4
+	}
+}
+
+type DB struct {
+	Entries []*Entry `json:"entries"`
+}
+
+// File is the location of the database, next to the other cache files.
+func File() string {
+	return filepath.Join(cache.Path(), FileName)
+}
+
+// Parse reads a database, invalid content results in an empty one.
+func Parse(data []byte) *DB {
+	db := &DB{}
+	if len(data) == 0 {
+		return db
+	}
+
+	if err := json.Unmarshal(data, db); err != nil {
+		log.Error(err)
+		return &DB{}
+	}
+
+	return db
+}
+
+// Load reads the database from file, a missing file results in an empty one.
+func Load(file string) *DB {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return &DB{}
+	}
+
+	return Parse(data)
+}
+
+// Save writes the database to a temporary file first so a concurrent
+// prompt never reads a partially written one.
+func (db *DB) Save(file string) error {
+	data, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), FileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// Visit records a visit to dir, dropping the lowest scoring folders when
+// there are more than maxEntries.
+func (db *DB) Visit(dir string, now time.Time, maxEntries int) {
+	index := slices.IndexFunc(db.Entries, func(entry *Entry) bool {
+		return entry.Path == dir
+	})
+
+	if index == -1 {
+		db.Entries = append(db.Entries, &Entry{Path: dir})
+		index = len(db.Entries) - 1
+	}
+
+	db.Entries[index].Rank++
+	db.Entries[index].LastAccess = now.Unix()
+
+	if len(db.Entries) <= maxEntries {
+		return
+	}
+
+	db.sortByScore(now)
+	db.Entries = db.Entries[:maxEntries]
+}
+
+// Frecent returns at most n folders, highest score first.
+func (db *DB) Frecent(n int, now time.Time) []*Entry {
+	db.sortByScore(now)
+	return first(db.Entries, n)
+}
+
+// Recent returns at most n folders, most recently visited first.
+func (db *DB) Recent(n int) []*Entry {
+	slices.SortStableFunc(db.Entries, func(a, b *Entry) int {
+		return cmp.Compare(b.LastAccess, a.LastAccess)
+	})
+
+	return first(db.Entries, n)
+}
+
+// Query returns the folders matching every keyword, highest score first.
+// Keywords match case-insensitively and in order, the last one has to match
+// the last element of the path: foo bar matches /foo/baz/bar, not /bar/foo.
+func (db *DB) Query(keywords []string, now time.Time) []*Entry {
+	var matches []*Entry
+
+	for _, entry := range db.Entries {
+		if matchesKeywords(entry.Path, keywords) {
+			matches = append(matches, entry)
+		}
+	}
+
+	sortByScore(matches, now)
+
+	return matches
+}
+
+func (db *DB) sortByScore(now time.Time) {
+	sortByScore(db.Entries, now)
+}
+
+func sortByScore(entries []*Entry, now time.Time) {
+	slices.SortStableFunc(entries, func(a, b *Entry) int {
+		scoreA, scoreB := a.Score(now), b.Score(now)
+
+		switch {
+		case scoreA > scoreB:
+			return -1
+		case scoreA < scoreB:
+			return 1
+		default:
+			return cmp.Compare(b.LastAccess, a.LastAccess)
+		}
+	})
+}
+
+func matchesKeywords(dir string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+
+	dir = strings.ToLower(dir)
+	last := strings.ToLower(keywords[len(keywords)-1])
+
+	if !strings.Contains(filepath.Base(dir), last) {
+		return false
+	}
+
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+
+		index := strings.Index(dir, keyword)
+		if index == -1 {
+			return false
+		}
+
+		dir = dir[index+len(keyword):]
+	}
+
+	return true
+}
+
+func first(entries []*Entry, n int) []*Entry {
+	if n <= 0 || n >= len(entries) {
+		return entries
+	}
+
+	return entries[:n]
+}
+
+// Visit records a visit to dir in the database on disk. The database is
+// locked while it's updated so shells visiting folders at the same time
+// don't overwrite each other's visits.
+func Visit(dir string, cfg *Config) error {
+	return visit(File(), dir, time.Now(), cfg.maxEntries())
+}
+
+func visit(file, dir string, now time.Time, maxEntries int) error {
+	unlock, err := lock(file)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	db := Load(file)
+	db.Visit(dir, now, maxEntries)
+
+	return db.Save(file)
+}
+
+// lock takes the lock file next to file, the returned function releases it.
+func lock(file string) (func(), error) {
+	lockFile := file + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		fd, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			fd.Close()
+			return func() { _ = os.Remove(lockFile) }, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockFile); err == nil && time.Since(info.ModTime()) > staleLock {
+			_ = os.Remove(lockFile)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("timeout waiting for the frecency database lock")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,145 @@
+package frecency
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func paths(entries []*Entry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.Path)
+	}
+
+	return result
+}
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		Case     string
+		Age      time.Duration
+		Expected float64
+	}{
+		{Case: "within the hour", Age: time.Minute, Expected: 8},
+		{Case: "within the day", Age: 2 * time.Hour, Expected: 4},
+		{Case: "within the week", Age: 48 * time.Hour, Expected: 1},
+		{Case: "older", Age: 30 * 24 * time.Hour, Expected: 0.5},
+	}
+
+	for _, tc := range cases {
+		entry := &Entry{Rank: 2, LastAccess: now.Add(-tc.Age).Unix()}
+		assert.Equal(t, tc.Expected, entry.Score(now), tc.Case)
+	}
+}
+
+func TestVisit(t *testing.T) {
+	now := time.Now()
+	db := &DB{}
+
+	db.Visit("/a", now.Add(-48*time.Hour), 2)
+	db.Visit("/a", now.Add(-48*time.Hour), 2)
+	db.Visit("/b", now.Add(-time.Minute), 2)
+	db.Visit("/c", now, 2)
+
+	// /a scores 1 while /b and /c score 4, so /a gets dropped
+	assert.ElementsMatch(t, []string{"/b", "/c"}, paths(db.Entries))
+
+	db.Visit("/b", now, 2)
+	assert.Equal(t, []string{"/b", "/c"}, paths(db.Frecent(0, now)))
+	assert.Equal(t, float64(2), db.Entries[0].Rank)
+}
+
+func TestFrecentAndRecent(t *testing.T) {
+	now := time.Now()
+	db := &DB{
+		Entries: []*Entry{
+			{Path: "/often", Rank: 10, LastAccess: now.Add(-2 * time.Hour).Unix()},
+			{Path: "/latest", Rank: 1, LastAccess: now.Unix()},
+			{Path: "/old", Rank: 5, LastAccess: now.Add(-30 * 24 * time.Hour).Unix()},
+		},
+	}
+
+	assert.Equal(t, []string{"/often", "/latest"}, paths(db.Frecent(2, now)))
+	assert.Equal(t, []string{"/latest", "/often", "/old"}, paths(db.Recent(0)))
+}
+
+func TestQuery(t *testing.T) {
+	now := time.Now()
+	db := &DB{
+		Entries: []*Entry{
+			{Path: "/home/jan/Projects/oh-my-posh", Rank: 5, LastAccess: now.Unix()},
+			{Path: "/home/jan/projects/posh-git", Rank: 10, LastAccess: now.Unix()},
+			{Path: "/home/jan/posh/docs", Rank: 20, LastAccess: now.Unix()},
+		},
+	}
+
+	cases := []struct {
+		Case     string
+		Keywords []string
+		Expected []string
+	}{
+		{Case: "single keyword", Keywords: []string{"posh"}, Expected: []string{"/home/jan/projects/posh-git", "/home/jan/Projects/oh-my-posh"}},
+		{Case: "case insensitive", Keywords: []string{"OH-MY"}, Expected: []string{"/home/jan/Projects/oh-my-posh"}},
+		{Case: "keywords in order", Keywords: []string{"projects", "git"}, Expected: []string{"/home/jan/projects/posh-git"}},
+		{Case: "keywords out of order", Keywords: []string{"git", "projects"}},
+		{Case: "last keyword matches the last element", Keywords: []string{"posh", "docs"}, Expected: []string{"/home/jan/posh/docs"}},
+		{Case: "no keywords"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, paths(db.Query(tc.Keywords, now)), tc.Case)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), FileName)
+
+	assert.Empty(t, Load(file).Entries, "missing file")
+
+	db := &DB{}
+	db.Visit("/a", time.Now(), 10)
+	require.NoError(t, db.Save(file))
+
+	assert.Equal(t, []string{"/a"}, paths(Load(file).Entries))
+	assert.Empty(t, Parse([]byte("not json")).Entries, "invalid content")
+}
+
+func TestConcurrentVisits(t *testing.T) {
+	file := filepath.Join(t.TempDir(), FileName)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Go(func() {
+			assert.NoError(t, visit(file, fmt.Sprintf("/folder/%d", i), now, 100))
+		})
+	}
+
+	wg.Wait()
+
+	assert.Len(t, Load(file).Entries, 20, "no visit is lost")
+	assert.NoFileExists(t, file+".lock")
+}
+
+func TestStaleLock(t *testing.T) {
+	file := filepath.Join(t.TempDir(), FileName)
+	lockFile := file + ".lock"
+
+	require.NoError(t, os.WriteFile(lockFile, nil, 0o600))
+
+	stale := time.Now().Add(-2 * staleLock)
+	require.NoError(t, os.Chtimes(lockFile, stale, stale))
+
+	require.NoError(t, visit(file, "/a", time.Now(), 10))
+	assert.Equal(t, []string{"/a"}, paths(Load(file).Entries))
+}
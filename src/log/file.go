@@ -0,0 +1,102 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// maxFileSize is the size a log file rotates at, maxBackups the number of rotated
+	// files kept next to it: <file>.1 is the most recent one.
+	maxFileSize int64 = 10 << 20
+	maxBackups        = 3
+)
+
+// rotatingFile appends to a log file and moves it aside once it grows past maxSize, so a
+// long-running serve daemon can log for days without filling the disk.
+type rotatingFile struct {
+	file    *os.File
+	path    string
+	size    int64
+	maxSize int64
+}
+
+func openRotatingFile(path string) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:    path,
+		maxSize: maxFileSize,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+
+	// every prompt is a new process appending to the same file, O_APPEND keeps their lines whole.
+	// Only the user can read it, requests and responses of segments end up in there.
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+
+	return nil
+}
+
+func (r *rotatingFile) write(line string) {
+	if r.size > 0 && r.size+int64(len(line)) > r.maxSize {
+		r.rotate()
+	}
+
+	if r.file == nil {
+		return
+	}
+
+	n, _ := r.file.WriteString(line)
+	r.size += int64(n)
+}
+
+// rotate shifts <file>.1 up to <file>.<maxBackups>, dropping the oldest, and starts a new file.
+// Another process may have rotated the file already, so a missing file is no error.
+func (r *rotatingFile) rotate() {
+	_ = r.file.Close()
+	r.file = nil
+
+	backup := func(index int) string {
+		return fmt.Sprintf("%s.%d", r.path, index)
+	}
+
+	_ = os.Remove(backup(maxBackups))
+
+	for i := maxBackups - 1; i > 0; i-- {
+		_ = os.Rename(backup(i), backup(i+1))
+	}
+
+	_ = os.Rename(r.path, backup(1))
+
+	_ = r.open()
+}
+
+func (r *rotatingFile) close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	return r.file.Close()
+}
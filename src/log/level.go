@@ -0,0 +1,43 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of a log entry, entries below the configured level are dropped.
+type Level byte
+
+const (
+	TraceLevel Level = iota
+	DebugLevel
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levels = [...]struct {
+	name  string
+	color string
+}{
+	TraceLevel: {name: "trace", color: purple},
+	DebugLevel: {name: "debug", color: green},
+	InfoLevel:  {name: "info", color: yellow},
+	WarnLevel:  {name: "warn", color: orange},
+	ErrorLevel: {name: "error", color: red},
+}
+
+func (l Level) String() string {
+	return levels[l].name
+}
+
+// ParseLevel returns the level for a name like debug or warn, case insensitive.
+func ParseLevel(name string) (Level, error) {
+	for l, level := range levels {
+		if strings.EqualFold(level.name, name) {
+			return Level(l), nil
+		}
+	}
+
+	return TraceLevel, fmt.Errorf("unknown log level: %s", name)
+}
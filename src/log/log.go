@@ -1,27 +1,156 @@
 package log
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
 var (
-	enabled bool
-	raw     bool
+	enabled  bool
+	buffered bool
+	raw      bool
+
+	// level is the lowest level any output takes, see threshold
+	level       = TraceLevel
+	memoryLevel = TraceLevel
+	fileLevel   = InfoLevel
+	include     map[string]bool
+	exclude     map[string]bool
+	file        *rotatingFile
+	jsonOutput  bool
 
-	log strings.Builder
+	mutex sync.Mutex
+	log   strings.Builder
 )
 
+// Settings configure what gets logged and where, see Configure.
+type Settings struct {
+	// File is the path of a log file to append every entry to, it rotates at 10MB.
+	File string
+	// Level is the lowest level logged, trace, debug, info, warn or error. It defaults to
+	// trace in memory and to info in the file.
+	Level string
+	// Format is the format of the log file, text (the default) or json.
+	Format string
+	// Subsystems is a comma separated list of the subsystems to log, the package an entry
+	// comes from (gitstatus, cache, http, ...) or serve for the serve daemon.
+	// A subsystem prefixed with - is left out instead.
+	Subsystems string
+}
+
+// Enable keeps every entry in memory, for String.
 func Enable(plain bool) {
 	enabled = true
+	buffered = true
 	raw = plain
+	level = threshold()
 
 	Debugf("logging enabled, raw mode: %t", plain)
 }
 
+// Configure applies the settings, an invalid one is skipped and reported in the returned error.
+// A File enables logging on its own, without Enable.
+func Configure(settings Settings) error {
+	var errs []error
+
+	if len(settings.Level) != 0 {
+		l, err := ParseLevel(settings.Level)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			memoryLevel, fileLevel = l, l
+			level = threshold()
+		}
+	}
+
+	switch strings.ToLower(settings.Format) {
+	case "", TextFormat:
+	case JSONFormat:
+		jsonOutput = true
+	default:
+		errs = append(errs, fmt.Errorf("unsupported log format: %s", settings.Format))
+	}
+
+	include, exclude = nil, nil
+
+	for name := range strings.SplitSeq(settings.Subsystems, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		switch {
+		case len(name) == 0:
+		case strings.HasPrefix(name, "-"):
+			if exclude == nil {
+				exclude = make(map[string]bool)
+			}
+
+			exclude[name[1:]] = true
+		default:
+			if include == nil {
+				include = make(map[string]bool)
+			}
+
+			include[name] = true
+		}
+	}
+
+	if len(settings.File) == 0 {
+		return errors.Join(errs...)
+	}
+
+	output, err := openRotatingFile(settings.File)
+	if err != nil {
+		errs = append(errs, err)
+		return errors.Join(errs...)
+	}
+
+	mutex.Lock()
+	file = output
+	mutex.Unlock()
+
+	enabled = true
+	level = threshold()
+
+	return errors.Join(errs...)
+}
+
+// threshold returns the lowest level the enabled outputs log.
+func threshold() Level {
+	switch {
+	case buffered && file != nil:
+		return min(memoryLevel, fileLevel)
+	case file != nil:
+		return fileLevel
+	default:
+		return memoryLevel
+	}
+}
+
+// Close closes the log file, if any.
+func Close() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if file == nil {
+		return nil
+	}
+
+	err := file.close()
+	file = nil
+	level = threshold()
+
+	return err
+}
+
 // Call sites that build an expensive message (e.g. via fmt.Sprintf) before calling
 // Debug/Trace should guard with this check so the formatting is skipped when logging is disabled.
 func Enabled() bool {
@@ -29,80 +158,162 @@ func Enabled() bool {
 }
 
 func Trace(start time.Time, args ...string) {
-	if !enabled {
+	if !enabled && !tracing {
 		return
 	}
 
 	elapsed := time.Since(start)
-	fn, _ := funcSpec()
+	fn, _, subsystem := funcSpec()
 
-	// Color-code elapsed time based on duration
-	var coloredElapsed Text
-	ms := elapsed.Milliseconds()
+	// traces are shared, the URLs and command lines in them must not carry credentials
+	detail := strings.Join(redactArgs(args), " ")
 
-	switch {
-	case ms < 1:
-		coloredElapsed = Text(elapsed.String()).Green().Plain()
-	case ms >= 1 && ms < 10:
-		coloredElapsed = Text(elapsed.String()).Yellow().Plain()
-	case ms >= 10 && ms < 100:
-		coloredElapsed = Text(elapsed.String()).Orange().Plain()
-	default: // >= 100ms
-		coloredElapsed = Text(elapsed.String()).Red().Plain()
+	if tracing {
+		record(start, "trace", strings.TrimSpace(fn+" "+detail), detail)
 	}
 
-	header := fmt.Sprintf("%s(%s) - %s", fn, strings.Join(args, " "), coloredElapsed)
+	if !enabled || level > TraceLevel {
+		return
+	}
 
-	printLn(trace, header)
+	write(&entry{
+		time:      time.Now(),
+		level:     TraceLevel,
+		source:    fn,
+		subsystem: subsystem,
+		message:   detail,
+		elapsed:   elapsed,
+	})
 }
 
 func Debug(message ...string) {
-	if !enabled {
+	emit(DebugLevel, strings.Join(message, " "))
+}
+
+func Debugf(format string, args ...any) {
+	if !enabled || level > DebugLevel {
+		return
+	}
+
+	emit(DebugLevel, fmt.Sprintf(format, args...))
+}
+
+// Private logs a debug message in memory only, for the --debug output. It never reaches the
+// log file, as it can hold credentials, like the body of an OAuth token response.
+func Private(message ...string) {
+	if !buffered || level > DebugLevel {
 		return
 	}
 
-	fn, line := funcSpec()
-	header := fmt.Sprintf("%s:%d", fn, line)
+	fn, line, subsystem := funcSpec()
 
-	printLn(debug, header, strings.Join(message, " "))
+	write(&entry{
+		time:      time.Now(),
+		level:     DebugLevel,
+		source:    fmt.Sprintf("%s:%d", fn, line),
+		subsystem: subsystem,
+		message:   strings.Join(message, " "),
+		private:   true,
+	})
 }
 
-func Debugf(format string, args ...any) {
-	if !enabled {
+func Info(message ...string) {
+	emit(InfoLevel, strings.Join(message, " "))
+}
+
+func Infof(format string, args ...any) {
+	if !enabled || level > InfoLevel {
 		return
 	}
 
-	message := fmt.Sprintf(format, args...)
-	Debug(message)
+	emit(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func Warn(message ...string) {
+	emit(WarnLevel, strings.Join(message, " "))
+}
+
+func Warnf(format string, args ...any) {
+	if !enabled || level > WarnLevel {
+		return
+	}
+
+	emit(WarnLevel, fmt.Sprintf(format, args...))
 }
 
 func Error(err error) {
-	if !enabled {
+	if !enabled || level > ErrorLevel {
 		return
 	}
-	fn, line := funcSpec()
-	header := fmt.Sprintf("%s:%d", fn, line)
 
-	printLn(bug, header, err.Error())
+	emit(ErrorLevel, err.Error())
 }
 
 func Errorf(format string, args ...any) {
-	if !enabled {
+	if !enabled || level > ErrorLevel {
 		return
 	}
 
-	Error(fmt.Errorf(format, args...))
+	emit(ErrorLevel, fmt.Errorf(format, args...).Error())
 }
 
 func String() string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
 	return log.String()
 }
 
-func funcSpec() (string, int) {
-	pcs := make([]uintptr, 4)
+func emit(l Level, message string) {
+	if !enabled || level > l {
+		return
+	}
+
+	fn, line, subsystem := funcSpec()
+
+	write(&entry{
+		time:      time.Now(),
+		level:     l,
+		source:    fmt.Sprintf("%s:%d", fn, line),
+		subsystem: subsystem,
+		message:   message,
+	})
+}
+
+func write(e *entry) {
+	if include != nil && !include[e.subsystem] {
+		return
+	}
+
+	if exclude[e.subsystem] {
+		return
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if buffered && e.level >= memoryLevel {
+		log.WriteString(e.text(!raw))
+	}
+
+	if file == nil || e.private || e.level < fileLevel {
+		return
+	}
+
+	if jsonOutput {
+		file.write(e.json())
+		return
+	}
+
+	file.write(e.text(false))
+}
+
+// funcSpec returns the caller outside of this file and the subsystem it belongs to.
+func funcSpec() (string, int, string) {
+	pcs := make([]uintptr, 8)
 	n := runtime.Callers(3, pcs)
 	if n == 0 {
-		return "", 0
+		return "", 0, ""
 	}
 
 	frames := runtime.CallersFrames(pcs[:n])
@@ -118,15 +329,36 @@ func funcSpec() (string, int) {
 
 		// Found first non-log.go frame
 		fn := frame.Function
+		name := filepath.Base(frame.File)
+		pkg := subsystem(fn, name)
 		fn = fn[strings.LastIndex(fn, ".")+1:]
-		file := filepath.Base(frame.File)
 
 		if strings.HasPrefix(fn, "func") {
-			return file, frame.Line
+			return name, frame.Line, pkg
 		}
 
-		return fmt.Sprintf("%s:%s", file, fn), frame.Line
+		return fmt.Sprintf("%s:%s", name, fn), frame.Line, pkg
+	}
+
+	return "", 0, ""
+}
+
+// subsystem is the last element of the import path of the package function belongs to.
+// The serve daemon lives in the cli package, but is a subsystem of its own. Requests are
+// sent by the runtime, yet belong with the http package.
+func subsystem(function, file string) string {
+	if file == "serve.go" {
+		return "serve"
+	}
+
+	if strings.HasSuffix(function, ".HTTPRequest") || file == "httpdump.go" {
+		return "http"
+	}
+
+	pkg := function[strings.LastIndex(function, "/")+1:]
+	if index := strings.Index(pkg, "."); index > 0 {
+		pkg = pkg[:index]
 	}
 
-	return "", 0
+	return pkg
 }
@@ -0,0 +1,230 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func configureTestLog(t *testing.T, settings Settings) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		_ = Close()
+
+		enabled, buffered, raw = false, false, false
+		level, memoryLevel, fileLevel = TraceLevel, TraceLevel, InfoLevel
+		include, exclude, jsonOutput = nil, nil, false
+		log.Reset()
+	})
+
+	require.NoError(t, Configure(settings))
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Name     string
+		Error    string
+		Expected Level
+	}{
+		{Case: "Lowercase", Name: "warn", Expected: WarnLevel},
+		{Case: "Uppercase", Name: "DEBUG", Expected: DebugLevel},
+		{Case: "Unknown", Name: "verbose", Error: "unknown log level: verbose"},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseLevel(tc.Name)
+		if len(tc.Error) != 0 {
+			assert.EqualError(t, err, tc.Error, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, got, tc.Case)
+	}
+}
+
+func TestLevelAndSubsystemFilter(t *testing.T) {
+	configureTestLog(t, Settings{Level: "info", Subsystems: "log,-cache"})
+	Enable(true)
+
+	Debug("hidden debug")
+	Info("shown info")
+	Warnf("shown %s", "warning")
+	Error(errors.New("shown error"))
+
+	output := String()
+
+	assert.NotContains(t, output, "hidden debug")
+	assert.Contains(t, output, "[INFO]")
+	assert.Contains(t, output, "log_test.go:TestLevelAndSubsystemFilter:")
+	assert.Contains(t, output, "shown warning")
+	assert.Contains(t, output, "[ERROR]")
+
+	include = map[string]bool{"cache": true}
+
+	Info("other subsystem")
+	assert.NotContains(t, String(), "other subsystem")
+}
+
+func TestConfigureInvalidSettings(t *testing.T) {
+	err := Configure(Settings{Level: "loud", Format: "xml"})
+
+	t.Cleanup(func() {
+		level, memoryLevel, fileLevel, jsonOutput = TraceLevel, TraceLevel, InfoLevel, false
+	})
+
+	assert.EqualError(t, err, "unknown log level: loud\nunsupported log format: xml")
+	assert.Equal(t, TraceLevel, level)
+}
+
+func TestJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "omp.log")
+	configureTestLog(t, Settings{File: path, Format: JSONFormat, Level: "debug"})
+
+	assert.True(t, Enabled(), "a log file enables logging")
+
+	Debugf("%d segments", 3)
+	Infof("multi\nline")
+
+	require.NoError(t, Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entries []jsonEntry
+
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		var e jsonEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		entries = append(entries, e)
+	}
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "debug", entries[0].Level)
+	assert.Equal(t, "log", entries[0].Subsystem)
+	assert.Equal(t, "3 segments", entries[0].Message)
+	assert.Equal(t, "info", entries[1].Level)
+	assert.Equal(t, "multi\nline", entries[1].Message)
+	assert.NotContains(t, string(content), "\x1b", "the file holds no color codes")
+}
+
+func TestFileDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "omp.log")
+	configureTestLog(t, Settings{File: path})
+
+	Debug("hidden debug")
+	Info("shown info")
+
+	require.NoError(t, Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	if goruntime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "only the user can read the log file")
+	}
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(content), "hidden debug", "the file logs info and up by default")
+	assert.Contains(t, string(content), "shown info")
+}
+
+func TestPrivateStaysInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "omp.log")
+	configureTestLog(t, Settings{File: path, Level: "debug"})
+	Enable(true)
+
+	Private(`{"access_token": "secret"}`)
+	Debug("public")
+
+	require.NoError(t, Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Contains(t, String(), "access_token")
+	assert.NotContains(t, string(content), "access_token")
+	assert.Contains(t, string(content), "public")
+}
+
+// testTerminal stands in for runtime.Terminal, which logs its requests from HTTPRequest.
+type testTerminal struct{}
+
+func (testTerminal) HTTPRequest(url string) {
+	Debug("GET", url)
+}
+
+func TestHTTPSubsystemFilter(t *testing.T) {
+	configureTestLog(t, Settings{Subsystems: "http"})
+	Enable(true)
+
+	testTerminal{}.HTTPRequest("https://ohmyposh.dev")
+	Debug("not a request")
+
+	output := String()
+
+	assert.Contains(t, output, "GET https://ohmyposh.dev")
+	assert.NotContains(t, output, "not a request")
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "omp.log")
+
+	output, err := openRotatingFile(path)
+	require.NoError(t, err)
+
+	output.maxSize = 10
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n", "fifth\n"} {
+		output.write(line)
+	}
+
+	require.NoError(t, output.close())
+
+	expected := map[string]string{
+		path:        "fifth\n",
+		path + ".1": "fourth\n",
+		path + ".2": "third\n",
+		path + ".3": "second\n",
+	}
+
+	for file, content := range expected {
+		got, err := os.ReadFile(file)
+		require.NoError(t, err, file)
+		assert.Equal(t, content, string(got), file)
+	}
+
+	assert.NoFileExists(t, path+".4", "only maxBackups rotated files are kept")
+}
+
+func TestSubsystem(t *testing.T) {
+	cases := []struct {
+		Function string
+		File     string
+		Expected string
+	}{
+		{Function: "github.com/jandedobbeleer/oh-my-posh/src/cache.(*store).init", File: "store.go", Expected: "cache"},
+		{Function: "github.com/jandedobbeleer/oh-my-posh/src/runtime/http.Download", File: "download.go", Expected: "http"},
+		{Function: "github.com/jandedobbeleer/oh-my-posh/src/runtime.(*Terminal).HTTPRequest", File: "terminal.go", Expected: "http"},
+		{Function: "github.com/jandedobbeleer/oh-my-posh/src/runtime.(*Terminal).Pwd", File: "terminal.go", Expected: "runtime"},
+		{Function: "github.com/jandedobbeleer/oh-my-posh/src/cli.copyRecords.func1", File: "serve.go", Expected: "serve"},
+		{Function: "main.main", File: "main.go", Expected: "main"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, subsystem(tc.Function, tc.File), tc.Function)
+	}
+}
@@ -1,120 +1,164 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 )
 
-type logType byte
-
 const (
-	debug logType = 1 << iota
-	bug
-	trace
+	green  = "\x1b[38;2;191;207;240m"
+	red    = "\x1b[38;2;253;122;140m"
+	purple = "\x1b[38;2;204;137;214m"
+	yellow = "\x1b[38;2;156;231;201m"
+	orange = "\x1b[38;2;253;184;109m"
+	bold   = "\x1b[1m"
+	reset  = "\033[0m"
 )
 
 type Text string
 
 func (t Text) Green() Text {
-	if raw {
-		return t
-	}
-	return "\x1b[38;2;191;207;240m" + t
+	return t.paint(green, !raw)
 }
 
 func (t Text) Red() Text {
-	if raw {
-		return t
-	}
-	return "\x1b[38;2;253;122;140m" + t
+	return t.paint(red, !raw)
 }
 
 func (t Text) Purple() Text {
-	if raw {
-		return t
-	}
-	return "\x1b[38;2;204;137;214m" + t
+	return t.paint(purple, !raw)
 }
 
 func (t Text) Yellow() Text {
-	if raw {
-		return t
-	}
-	return "\x1b[38;2;156;231;201m" + t
+	return t.paint(yellow, !raw)
 }
 
 func (t Text) Orange() Text {
-	if raw {
-		return t
-	}
-	return "\x1b[38;2;253;184;109m" + t
+	return t.paint(orange, !raw)
 }
 
 func (t Text) Bold() Text {
-	if raw {
-		return t
-	}
-	return "\x1b[1m" + t
+	return t.paint(bold, !raw)
 }
 
 func (t Text) Plain() Text {
-	if raw {
-		return t
-	}
-	return t + "\033[0m"
+	return t.reset(!raw)
 }
 
 func (t Text) String() string {
 	return string(t)
 }
 
-func printLn(lt logType, args ...string) {
-	if len(args) == 0 {
-		return
+func (t Text) paint(color string, colored bool) Text {
+	if !colored {
+		return t
 	}
 
-	var str Text
-	switch lt {
-	case debug:
-		str = Text("[DEBUG] ").Green()
-	case bug:
-		str = Text("[ERROR] ").Red()
-	case trace:
-		str = Text("[TRACE] ").Purple()
+	return Text(color) + t
+}
+
+func (t Text) reset(colored bool) Text {
+	if !colored {
+		return t
+	}
+
+	return t + reset
+}
+
+// entry is a single log line. The source is file:function:line, or file:function
+// for a trace, which holds the traced arguments as its message.
+type entry struct {
+	time      time.Time
+	source    string
+	subsystem string
+	message   string
+	elapsed   time.Duration
+	level     Level
+	// private entries stay in memory, they never reach the log file
+	private bool
+}
+
+func (e *entry) text(colored bool) string {
+	label := fmt.Sprintf("[%s] ", strings.ToUpper(e.level.String()))
+
+	str := Text(label).paint(levels[e.level].color, colored)
+	str += Text(e.time.Format("15:04:05.000")+" ").paint(yellow, colored).reset(colored)
+
+	if e.level == TraceLevel {
+		str += Text(fmt.Sprintf("%s(%s) - %s\n", e.source, e.message, e.coloredElapsed(colored)))
+		return str.String()
 	}
 
-	// timestamp 156, 231, 201
-	str += Text(time.Now().Format("15:04:05.000") + " ").Yellow().Plain()
-	str += Text(args[0])
-	str += parseArgs(args...)
-	log.WriteString(str.String())
+	str += Text(e.source)
+	str += e.details(colored)
+
+	return str.String()
 }
 
-func parseArgs(args ...string) Text {
-	if len(args) == 1 {
-		return "\n"
+// coloredElapsed color-codes the elapsed time based on duration
+func (e *entry) coloredElapsed(colored bool) Text {
+	elapsed := Text(e.elapsed.String())
+	ms := e.elapsed.Milliseconds()
+
+	switch {
+	case ms < 1:
+		return elapsed.paint(green, colored).reset(colored)
+	case ms >= 1 && ms < 10:
+		return elapsed.paint(yellow, colored).reset(colored)
+	case ms >= 10 && ms < 100:
+		return elapsed.paint(orange, colored).reset(colored)
+	default: // >= 100ms
+		return elapsed.paint(red, colored).reset(colored)
 	}
+}
 
+func (e *entry) details(colored bool) Text {
 	// display empty return values as NO DATA
-	if args[1] == "" {
-		text := Text(" \u2192").Yellow()
-		text += Text(" NO DATA\n").Red().Plain()
+	if e.message == "" {
+		text := Text(" \u2192").paint(yellow, colored)
+		text += Text(" NO DATA\n").paint(red, colored).reset(colored)
 		return text
 	}
 
 	// print a single line for single output
-	splitted := strings.Split(args[1], "\n")
+	splitted := strings.Split(e.message, "\n")
 	if len(splitted) == 1 {
-		text := Text(" \u2192").Yellow().Plain()
-		return Text(fmt.Sprintf("%s %s\n", text, args[1]))
+		text := Text(" \u2192").paint(yellow, colored).reset(colored)
+		return Text(fmt.Sprintf("%s %s\n", text, e.message))
 	}
 
 	// indent multiline output with 4 spaces
 	var str Text
-	str += Text(" \u2193\n").Yellow().Plain()
+	str += Text(" \u2193\n").paint(yellow, colored).reset(colored)
 	for _, line := range splitted {
 		str += Text(fmt.Sprintf("    %s\n", line))
 	}
 	return str
 }
+
+type jsonEntry struct {
+	Time      time.Time     `json:"time"`
+	Level     string        `json:"level"`
+	Subsystem string        `json:"subsystem,omitempty"`
+	Source    string        `json:"source"`
+	Message   string        `json:"message"`
+	Duration  time.Duration `json:"duration_ns,omitempty"`
+}
+
+func (e *entry) json() string {
+	line, err := json.Marshal(&jsonEntry{
+		Time:      e.time,
+		Level:     e.level.String(),
+		Subsystem: e.subsystem,
+		Source:    e.source,
+		Message:   e.message,
+		Duration:  e.elapsed,
+	})
+	if err != nil {
+		return ""
+	}
+
+	return string(line) + "\n"
+}
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redacted replaces a credential in what gets logged or traced.
+const redacted = "REDACTED"
+
+// credentials are the query parameter and flag names, or what they end in, that hold a secret:
+// owm's appid, lastfm's api_key, an access_token, ...
+var credentials = []string{"key", "token", "secret", "password", "appid"}
+
+var queryParameter = regexp.MustCompile(`[?&][^=&?#\s]+=[^&#\s]*`)
+
+func isCredential(name string) bool {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimLeft(name, "-")), "-", "_")
+
+	for _, credential := range credentials {
+		if strings.HasSuffix(name, credential) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact masks the values of credential query parameters in the URLs in text,
+// like https://api.openweathermap.org/data/2.5/weather?q=Ghent&appid=REDACTED.
+func Redact(text string) string {
+	if !strings.ContainsAny(text, "?&") {
+		return text
+	}
+
+	return queryParameter.ReplaceAllStringFunc(text, func(parameter string) string {
+		name, _, _ := strings.Cut(parameter[1:], "=")
+		if !isCredential(name) {
+			return parameter
+		}
+
+		return parameter[:len(name)+2] + redacted
+	})
+}
+
+// redactArgs masks credentials in a command line or the URL a request goes to: the value of
+// a flag like --token, either the next argument or what follows =, and query parameters.
+func redactArgs(args []string) []string {
+	result := make([]string, len(args))
+	next := false
+
+	for i, arg := range args {
+		if next {
+			result[i] = redacted
+			next = false
+			continue
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			result[i] = Redact(arg)
+			continue
+		}
+
+		if flag, _, found := strings.Cut(arg, "="); found && isCredential(flag) {
+			result[i] = flag + "=" + redacted
+			continue
+		}
+
+		next = isCredential(arg)
+		result[i] = arg
+	}
+
+	return result
+}
@@ -0,0 +1,29 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactArgs(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Args     []string
+		Expected []string
+	}{
+		{Case: "No credentials", Args: []string{"git", "status", "-unormal"}, Expected: []string{"git", "status", "-unormal"}},
+		{
+			Case:     "Query parameters",
+			Args:     []string{"https://example.com/api?user=jan&api_key=abc&apiKey=def&token=ghi#top"},
+			Expected: []string{"https://example.com/api?user=jan&api_key=REDACTED&apiKey=REDACTED&token=REDACTED#top"},
+		},
+		{Case: "Flag with a value", Args: []string{"cli", "--password", "hunter2", "--user", "jan"}, Expected: []string{"cli", "--password", "REDACTED", "--user", "jan"}},
+		{Case: "Flag with =", Args: []string{"cli", "--api-key=abc", "--name=jan"}, Expected: []string{"cli", "--api-key=REDACTED", "--name=jan"}},
+		{Case: "Flag as the last argument", Args: []string{"cli", "--token"}, Expected: []string{"cli", "--token"}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, redactArgs(tc.Args), tc.Case)
+	}
+}
@@ -0,0 +1,320 @@
+package log
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The formats WriteTrace can export the recorded spans in.
+const (
+	// TraceChrome is the Chrome trace event format, which Perfetto and chrome://tracing open.
+	TraceChrome = "chrome"
+	// TraceOTLP is the OpenTelemetry protocol's JSON encoding of a single trace.
+	TraceOTLP = "otlp"
+)
+
+// maxSpanName keeps spans readable in a trace viewer, a template or a command line can be long.
+const maxSpanName = 80
+
+type span struct {
+	start     time.Time
+	end       time.Time
+	name      string
+	category  string
+	args      string
+	goroutine uint64
+	// sequence orders spans ending at the same time: an enclosing span is
+	// recorded after the spans it encloses, as it ends last.
+	sequence int
+}
+
+var (
+	tracing   bool
+	root      uint64
+	spans     []*span
+	spansLock sync.Mutex
+)
+
+// EnableTracing records a span for every Trace and Span call from now on, see WriteTrace.
+func EnableTracing() {
+	root = GoroutineID()
+	tracing = true
+}
+
+// Tracing reports whether spans are recorded. Call sites that build an expensive
+// span name should guard with this check, just like Enabled for the log.
+func Tracing() bool {
+	return tracing
+}
+
+// Span records a span from start until now without writing to the log, for work
+// that happens too often to log every call, like rendering a template.
+func Span(start time.Time, category, name string) {
+	if !tracing {
+		return
+	}
+
+	record(start, category, name, "")
+}
+
+func record(start time.Time, category, name, args string) {
+	s := &span{
+		start:     start,
+		end:       time.Now(),
+		name:      truncate(strings.Join(strings.Fields(name), " ")),
+		category:  category,
+		args:      args,
+		goroutine: GoroutineID(),
+	}
+
+	spansLock.Lock()
+	s.sequence = len(spans)
+	spans = append(spans, s)
+	spansLock.Unlock()
+}
+
+func truncate(name string) string {
+	if runes := []rune(name); len(runes) > maxSpanName {
+		return string(runes[:maxSpanName-1]) + "…"
+	}
+
+	return name
+}
+
+// GoroutineID identifies the calling goroutine, spans use it as their track.
+func GoroutineID() uint64 {
+	buf := make([]byte, 64)
+	n := runtime.Stack(buf, false)
+	s := strings.Fields(strings.TrimPrefix(string(buf[:n]), "goroutine "))
+	if len(s) == 0 {
+		return 0
+	}
+
+	id, err := strconv.ParseUint(s[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
+// WriteTrace writes the recorded spans to w in the given format.
+func WriteTrace(w io.Writer, format string) error {
+	spansLock.Lock()
+	recorded := slices.Clone(spans)
+	spansLock.Unlock()
+
+	slices.SortStableFunc(recorded, func(a, b *span) int {
+		return a.start.Compare(b.start)
+	})
+
+	var document any
+
+	switch format {
+	case TraceChrome:
+		document = chromeTrace(recorded)
+	case TraceOTLP:
+		document = otlpTrace(recorded)
+	default:
+		return fmt.Errorf("unsupported trace format: %s", format)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(document)
+}
+
+type chromeEvent struct {
+	Args map[string]string `json:"args,omitempty"`
+	Name string            `json:"name"`
+	Cat  string            `json:"cat,omitempty"`
+	Ph   string            `json:"ph"`
+	Ts   float64           `json:"ts"`
+	Dur  float64           `json:"dur,omitempty"`
+	Pid  int               `json:"pid"`
+	Tid  uint64            `json:"tid"`
+}
+
+// chromeTrace puts every goroutine on its own track, so segments that run in
+// parallel show up next to each other instead of stacked on top of each other.
+func chromeTrace(recorded []*span) map[string]any {
+	events := []chromeEvent{
+		{Name: "process_name", Ph: "M", Pid: 1, Tid: root, Args: map[string]string{"name": "oh-my-posh"}},
+	}
+
+	if len(recorded) == 0 {
+		return map[string]any{"traceEvents": events, "displayTimeUnit": "ms"}
+	}
+
+	origin := recorded[0].start
+	named := make(map[uint64]bool)
+
+	for _, s := range recorded {
+		if !named[s.goroutine] {
+			named[s.goroutine] = true
+
+			name := fmt.Sprintf("goroutine %d", s.goroutine)
+			if s.goroutine == root {
+				name = "main"
+			}
+
+			events = append(events, chromeEvent{Name: "thread_name", Ph: "M", Pid: 1, Tid: s.goroutine, Args: map[string]string{"name": name}})
+		}
+
+		event := chromeEvent{
+			Name: s.name,
+			Cat:  s.category,
+			Ph:   "X",
+			Ts:   microseconds(s.start.Sub(origin)),
+			Dur:  microseconds(s.end.Sub(s.start)),
+			Pid:  1,
+			Tid:  s.goroutine,
+		}
+
+		if len(s.args) != 0 {
+			event.Args = map[string]string{"args": s.args}
+		}
+
+		events = append(events, event)
+	}
+
+	return map[string]any{"traceEvents": events, "displayTimeUnit": "ms"}
+}
+
+func microseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1000
+}
+
+type otlpAttribute struct {
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+	Key string `json:"key"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Kind              int             `json:"kind"`
+}
+
+func attribute(key, value string) otlpAttribute {
+	a := otlpAttribute{Key: key}
+	a.Value.StringValue = value
+	return a
+}
+
+// otlpTrace exports the spans as a single trace. Go has no notion of a parent
+// goroutine, so a span's parent is the innermost span enclosing it on its own
+// goroutine and, for the first span of a goroutine, the innermost one enclosing
+// it on the main goroutine, which is the one that spawned the segments.
+func otlpTrace(recorded []*span) map[string]any {
+	traceID := randomID(16)
+
+	ids := make(map[*span]string, len(recorded))
+	for _, s := range recorded {
+		ids[s] = randomID(8)
+	}
+
+	otlpSpans := make([]otlpSpan, 0, len(recorded))
+
+	for _, s := range recorded {
+		attributes := []otlpAttribute{
+			attribute("category", s.category),
+			attribute("thread.id", strconv.FormatUint(s.goroutine, 10)),
+		}
+
+		if len(s.args) != 0 {
+			attributes = append(attributes, attribute("args", s.args))
+		}
+
+		otlpSpan := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            ids[s],
+			Name:              s.name,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        attributes,
+			Kind:              1, // SPAN_KIND_INTERNAL
+		}
+
+		if parent := parentSpan(recorded, s); parent != nil {
+			otlpSpan.ParentSpanID = ids[parent]
+		}
+
+		otlpSpans = append(otlpSpans, otlpSpan)
+	}
+
+	return map[string]any{
+		"resourceSpans": []any{
+			map[string]any{
+				"resource": map[string]any{
+					"attributes": []otlpAttribute{attribute("service.name", "oh-my-posh")},
+				},
+				"scopeSpans": []any{
+					map[string]any{
+						"scope": map[string]any{"name": "oh-my-posh"},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func parentSpan(recorded []*span, child *span) *span {
+	innermost := func(goroutine uint64) *span {
+		var parent *span
+
+		for _, s := range recorded {
+			if s == child || s.goroutine != goroutine {
+				continue
+			}
+
+			if s.start.After(child.start) || s.end.Before(child.end) {
+				continue
+			}
+
+			if s.start.Equal(child.start) && s.end.Equal(child.end) && s.sequence < child.sequence {
+				continue
+			}
+
+			if parent == nil || s.end.Sub(s.start) < parent.end.Sub(parent.start) {
+				parent = s
+			}
+		}
+
+		return parent
+	}
+
+	if parent := innermost(child.goroutine); parent != nil {
+		return parent
+	}
+
+	if child.goroutine == root {
+		return nil
+	}
+
+	return innermost(root)
+}
+
+func randomID(size int) string {
+	buf := make([]byte, size)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
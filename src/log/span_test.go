@@ -0,0 +1,137 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordTestSpans(t *testing.T) {
+	t.Helper()
+
+	spans = nil
+
+	EnableTracing()
+
+	t.Cleanup(func() {
+		tracing = false
+		spans = nil
+	})
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		segmentStart := time.Now()
+		Trace(time.Now(), "git", "status")
+		Span(segmentStart, "segment", "Git")
+	}()
+
+	wg.Wait()
+
+	Span(start, "prompt", "primary")
+}
+
+func TestWriteTraceChrome(t *testing.T) {
+	recordTestSpans(t)
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteTrace(&buffer, TraceChrome))
+
+	var trace struct {
+		TraceEvents []chromeEvent `json:"traceEvents"`
+	}
+
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &trace))
+
+	tracks := make(map[string]uint64)
+	threads := make(map[uint64]string)
+
+	for _, event := range trace.TraceEvents {
+		switch event.Ph {
+		case "X":
+			tracks[event.Name] = event.Tid
+		case "M":
+			if event.Name == "thread_name" {
+				threads[event.Tid] = event.Args["name"]
+			}
+		}
+	}
+
+	assert.Len(t, tracks, 3)
+	assert.Equal(t, tracks["Git"], tracks["span_test.go git status"], "a command runs on the track of its segment")
+	assert.NotEqual(t, tracks["Git"], tracks["primary"], "a segment runs on its own track")
+	assert.Equal(t, "main", threads[tracks["primary"]])
+}
+
+func TestWriteTraceOTLP(t *testing.T) {
+	recordTestSpans(t)
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteTrace(&buffer, TraceOTLP))
+
+	var trace struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []otlpSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &trace))
+
+	otlpSpans := trace.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, otlpSpans, 3)
+
+	byName := make(map[string]otlpSpan)
+	for _, s := range otlpSpans {
+		byName[s.Name] = s
+		assert.Equal(t, otlpSpans[0].TraceID, s.TraceID)
+	}
+
+	assert.Empty(t, byName["primary"].ParentSpanID)
+	assert.Equal(t, byName["primary"].SpanID, byName["Git"].ParentSpanID, "a segment's parent is the span that spawned it")
+	assert.Equal(t, byName["Git"].SpanID, byName["span_test.go git status"].ParentSpanID)
+}
+
+func TestWriteTraceUnsupportedFormat(t *testing.T) {
+	assert.EqualError(t, WriteTrace(&bytes.Buffer{}, "zipkin"), "unsupported trace format: zipkin")
+}
+
+func TestWriteTraceRedactsCredentials(t *testing.T) {
+	spans = nil
+
+	EnableTracing()
+
+	t.Cleanup(func() {
+		tracing = false
+		spans = nil
+	})
+
+	Trace(time.Now(), "https://api.openweathermap.org/data/2.5/weather?q=Ghent&appid=owm-secret&units=metric")
+	Trace(time.Now(), "https://ws.audioscrobbler.com/2.0/?method=user.getrecenttracks&api_key=lastfm-secret")
+	Trace(time.Now(), "gh", "api", "--token", "gh-secret", "--access-token=at-secret")
+
+	for _, format := range []string{TraceChrome, TraceOTLP} {
+		var buffer bytes.Buffer
+		require.NoError(t, WriteTrace(&buffer, format))
+
+		trace := buffer.String()
+
+		for _, secret := range []string{"owm-secret", "lastfm-secret", "gh-secret", "at-secret"} {
+			assert.NotContains(t, trace, secret, format)
+		}
+
+		assert.Contains(t, trace, "q=Ghent&appid=REDACTED&units=metric", format)
+	}
+}
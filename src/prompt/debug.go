@@ -2,13 +2,19 @@ package prompt
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
 	"github.com/jandedobbeleer/oh-my-posh/src/config"
 	"github.com/jandedobbeleer/oh-my-posh/src/log"
+	"github.com/jandedobbeleer/oh-my-posh/src/template"
 )
 
+// maxDebugTimings limits the template timings in the debug output to the slowest ones,
+// print --format json lists all of them.
+const maxDebugTimings = 10
+
 // debug will loop through your config file and output the timings for each segments
 func (e *Engine) PrintDebug(startTime time.Time, version string) string {
 	e.write(fmt.Sprintf("\n%s %s\n", log.Text("Version:").Green().Bold().Plain(), version))
@@ -63,9 +69,18 @@ func (e *Engine) PrintDebug(startTime time.Time, version string) string {
 			active = log.Text("false").Purple()
 		}
 		segmentName := fmt.Sprintf("%s(%s)", segment.Name(), active.Plain())
-		e.write(fmt.Sprintf("%-*s - %3d ms\n", largestSegmentNameLength, segmentName, duration))
+		line := fmt.Sprintf("%-*s - %3d ms", largestSegmentNameLength, segmentName, duration)
+		if len(segment.Demoted) != 0 {
+			line += log.Text(fmt.Sprintf(" - over latency budget, %s", segment.Demoted)).Red().Plain().String()
+		}
+
+		e.write(line + "\n")
 	}
 
+	profile := template.GetProfile()
+	e.writeTimings("Templates:", profile.Templates)
+	e.writeTimings("Template functions:", profile.Functions)
+
 	e.write(fmt.Sprintf("\n%s %s\n", log.Text("Run duration:").Green().Bold().Plain(), time.Since(startTime)))
 	e.write(fmt.Sprintf("\n%s %s\n", log.Text("Cache path:").Green().Bold().Plain(), cache.Path()))
 
@@ -80,3 +95,24 @@ func (e *Engine) PrintDebug(startTime time.Time, version string) string {
 	e.write(e.Env.Logs())
 	return e.string()
 }
+
+func (e *Engine) writeTimings(title string, timings []template.Timing) {
+	if len(timings) == 0 {
+		return
+	}
+
+	e.write(log.Text("\n" + title + "\n\n").Green().Bold().Plain().String())
+
+	for i, timing := range timings {
+		if i == maxDebugTimings {
+			break
+		}
+
+		name := strings.Join(strings.Fields(timing.Name), " ")
+		if runes := []rune(name); len(runes) > 80 {
+			name = string(runes[:79]) + "…"
+		}
+
+		e.write(fmt.Sprintf("%10s %4dx %s\n", timing.Duration.Round(time.Microsecond), timing.Count, name))
+	}
+}
@@ -32,6 +32,7 @@ type Engine struct {
 	abort                 chan struct{}
 	previousActiveSegment *config.Segment
 	pendingSegments       sync.Map
+	executing             sync.Map
 	Overflow              config.Overflow
 	rprompt               string
 	prompt                strings.Builder
@@ -79,14 +80,17 @@ const (
 	PRIMARY         = "primary"
 	TRANSIENT       = "transient"
 	TRANSIENT_RIGHT = "transient-right"
+	FINISHED        = "finished"
 	DEBUG           = "debug"
 	SECONDARY       = "secondary"
 	RIGHT           = "right"
 	TOOLTIP         = "tooltip"
+	INFO            = "info"
 	VALID           = "valid"
 	ERROR           = "error"
 	PREVIEW         = "preview"
 	CURSOR          = "cursor"
+	KEY             = "key"
 )
 
 func (e *Engine) write(txt string) {
@@ -482,9 +486,29 @@ func (e *Engine) setActiveSegment(segment *config.Segment) {
 		}
 	}
 
+	if adjusted := e.ensureContrast(background, foreground); adjusted != foreground {
+		foreground = adjusted
+		segment.CollapseForeground(foreground)
+	}
+
 	terminal.SetColors(background, foreground)
 }
 
+// ensureContrast adjusts the foreground color to reach the configured minimum_contrast with
+// the background color, a transparent background being the terminal_background. Only hex colors
+// can be checked, anything else is returned as is.
+func (e *Engine) ensureContrast(background, foreground color.Ansi) color.Ansi {
+	if e.Config.MinimumContrast <= 0 {
+		return foreground
+	}
+
+	if background.IsTransparent() || background.IsEmpty() {
+		background = resolvePaletteReference(terminal.BackgroundColor)
+	}
+
+	return color.EnsureContrast(foreground, background, e.Config.MinimumContrast)
+}
+
 // resolvePaletteReference expands a palette reference (p:name) so a palette entry
 // holding a gradient is visible to the engine's gradient handling; without this,
 // IsGradient/GradientLast run on the literal "p:name" string and every gradient
@@ -935,6 +959,8 @@ func newEngine(cfg *config.Config, env runtime.Environment) *Engine {
 	flags := env.Flags()
 
 	template.Init(env, cfg.Var, cfg.Maps)
+	template.SetCapabilities(cfg.TemplateCapabilities)
+	template.SetPartials(cfg.Partials)
 
 	flags.HasExtra = cfg.DebugPrompt != nil ||
 		cfg.SecondaryPrompt != nil ||
@@ -949,10 +975,16 @@ func newEngine(cfg *config.Config, env runtime.Environment) *Engine {
 	}
 
 	terminal.Init(sh)
+	cfg.TerminalFeatures.Apply()
+	color.ColorDepth = cfg.ColorDepth.Resolve(env.Getenv)
 	terminal.BackgroundColor = cfg.TerminalBackground.ResolveTemplate()
 	terminal.Colors = cfg.MakeColors(env)
 	terminal.Plain = flags.Plain
 
+	if cfg.ShellIntegration {
+		terminal.PromptID = cache.SessionID()
+	}
+
 	eng := &Engine{
 		Config:      cfg,
 		Env:         env,
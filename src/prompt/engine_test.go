@@ -481,3 +481,48 @@ func TestShouldFill(t *testing.T) {
 		assert.Equal(t, tc.ExpectedBool, gotBool, tc.Case)
 	}
 }
+
+func TestSetActiveSegmentMinimumContrast(t *testing.T) {
+	colors, background := terminal.Colors, terminal.BackgroundColor
+	t.Cleanup(func() {
+		terminal.Colors = colors
+		terminal.BackgroundColor = background
+	})
+
+	cases := []struct {
+		Case               string
+		Foreground         color.Ansi
+		Background         color.Ansi
+		TerminalBackground color.Ansi
+		Expected           color.Ansi
+		MinimumContrast    float64
+	}{
+		{Case: "Disabled", Foreground: "#FFFFFF", Background: "#FFFF00", Expected: "#FFFFFF"},
+		{Case: "Enough contrast", Foreground: "#000000", Background: "#FFFF00", MinimumContrast: 4.5, Expected: "#000000"},
+		{Case: "Adjusted", Foreground: "#FFFFFF", Background: "#FFFF00", MinimumContrast: 21, Expected: "#000000"},
+		{Case: "Named colors", Foreground: "white", Background: "yellow", MinimumContrast: 21, Expected: "white"},
+		{
+			Case:               "Transparent background",
+			Foreground:         "#000000",
+			Background:         color.Transparent,
+			TerminalBackground: "#101010",
+			MinimumContrast:    21,
+			Expected:           "#ffffff",
+		},
+	}
+
+	for _, tc := range cases {
+		terminal.Init(shell.GENERIC)
+		terminal.Colors = &color.Defaults{}
+		terminal.BackgroundColor = tc.TerminalBackground
+
+		engine := &Engine{
+			Config: &config.Config{MinimumContrast: tc.MinimumContrast},
+		}
+
+		segment := &config.Segment{Foreground: tc.Foreground, Background: tc.Background}
+		engine.setActiveSegment(segment)
+
+		assert.Equal(t, tc.Expected, segment.ResolveForeground(), tc.Case)
+	}
+}
@@ -3,6 +3,7 @@ package prompt
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/color"
 	"github.com/jandedobbeleer/oh-my-posh/src/config"
@@ -70,10 +71,16 @@ func (e *Engine) ExtraPrompt(promptType ExtraPromptType) string {
 	// prompt these marks can't go through e.write/e.string - append them to
 	// str directly instead.
 	var shellIntegrationStart, shellIntegrationEnd string
-	if promptType == Transient && e.Config.ShellIntegration {
-		exitCode, _ := e.Env.StatusCodes()
-		shellIntegrationStart = terminal.CommandFinished(exitCode, e.Env.Flags().NoExitCode) + terminal.PromptStart()
-		shellIntegrationEnd = terminal.CommandStart()
+	if e.shellIntegration() {
+		switch promptType { //nolint:exhaustive
+		case Transient:
+			exitCode, _ := e.Env.StatusCodes()
+			shellIntegrationStart = terminal.CommandFinished(exitCode, e.Env.Flags().NoExitCode) + terminal.PromptStart()
+			shellIntegrationEnd = terminal.CommandStart()
+		case Secondary:
+			shellIntegrationStart = terminal.PromptKindStart(terminal.SecondaryPrompt)
+			shellIntegrationEnd = terminal.CommandStart()
+		}
 	}
 
 	foreground := color.Ansi(prompt.ForegroundTemplates.FirstMatch(nil, string(prompt.Foreground)))
@@ -85,13 +92,13 @@ func (e *Engine) ExtraPrompt(promptType ExtraPromptType) string {
 	str = shellIntegrationStart + str
 
 	if promptType == Secondary && e.Env.Shell() == shell.ZSH && e.Env.Flags().Eval {
-		evalOutput := fmt.Sprintf("_omp_secondary_prompt=%s", shell.QuotePosixStr(str))
+		evalOutput := fmt.Sprintf("_omp_secondary_prompt=%s", shell.QuotePosixStr(str+shellIntegrationEnd))
 		evalOutput += fmt.Sprintf("\nPOSH_MULTILINE_KEEPPROMPT=%t", prompt.MultilineKeepPrompt)
 		return evalOutput
 	}
 
 	if promptType != Transient {
-		return str
+		return str + shellIntegrationEnd
 	}
 
 	rightStr, rightLength := e.renderRightTemplate(prompt, background, foreground)
@@ -184,6 +191,41 @@ func (e *Engine) TransientRPrompt() string {
 	return str
 }
 
+// Finished renders the transient prompt's finished_template once the command
+// accepted at the transient prompt is done. The shell prints it as is, either
+// on the transient prompt's line or below the command's output, so the text
+// is not escaped for the shell.
+func (e *Engine) Finished() string {
+	prompt := e.Config.TransientPrompt
+	if prompt == nil || len(prompt.FinishedTemplate) == 0 || e.Env.Flags().NoExitCode {
+		return ""
+	}
+
+	data := &config.Finished{
+		Timestamp: time.Now(),
+		Duration:  time.Duration(e.Env.ExecutionTime()) * time.Millisecond,
+	}
+
+	text, err := template.RenderTrusted(prompt.FinishedTemplate, data)
+	if err != nil {
+		text = err.Error()
+	}
+
+	if len(text) == 0 {
+		return ""
+	}
+
+	terminal.Init(shell.GENERIC)
+
+	foreground := color.Ansi(prompt.ForegroundTemplates.FirstMatch(nil, string(prompt.Foreground)))
+	background := color.Ansi(prompt.BackgroundTemplates.FirstMatch(nil, string(prompt.Background)))
+	terminal.SetColors(background, foreground)
+	terminal.Write(background, foreground, text)
+
+	str, _ := terminal.String()
+	return str
+}
+
 // Only shells with a supported native or emulated right prompt can display this.
 func (e *Engine) renderRightTemplate(prompt *config.Segment, background, foreground color.Ansi) (string, int) {
 	if len(prompt.RightTemplate) == 0 {
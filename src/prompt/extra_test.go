@@ -353,6 +353,35 @@ func TestExtraPromptTransientShellIntegration(t *testing.T) {
 	}
 }
 
+func TestExtraPromptSecondaryShellIntegration(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Shell    string
+		Expected string
+		Eval     bool
+	}{
+		{Case: "fish", Shell: shell.FISH, Expected: "\x1b]133;A;k=s\a> \x1b]133;B\a"},
+		{Case: "zsh, eval", Shell: shell.ZSH, Eval: true, Expected: "_omp_secondary_prompt=$'%{\x1b]133;A;k=s\a%}> %{\x1b]133;B\a%}'\nPOSH_MULTILINE_KEEPPROMPT=false"},
+		{Case: "elvish", Shell: shell.ELVISH, Expected: "> "},
+	}
+
+	for _, tc := range cases {
+		env := setupExtraPromptTest(t, tc.Shell, &runtime.Flags{Eval: tc.Eval})
+
+		engine := &Engine{
+			Config: &config.Config{
+				ShellIntegration: true,
+				SecondaryPrompt: &config.Segment{
+					Template: "> ",
+				},
+			},
+			Env: env,
+		}
+
+		assert.Equal(t, tc.Expected, engine.ExtraPrompt(Secondary), tc.Case)
+	}
+}
+
 func TestTransientRPromptTemplateError(t *testing.T) {
 	env := setupExtraPromptTest(t, shell.FISH, &runtime.Flags{})
 	engine := &Engine{
@@ -497,3 +526,39 @@ func TestStreamPrimary_TransientRecordContainsRightTemplateForPWSH(t *testing.T)
 	assert.Contains(t, transient, terminal.SaveCursorPosition(), "the streamed transient record should carry the right-aligned template")
 	assert.Contains(t, transient, "R>", "the streamed transient record should carry the right-aligned template")
 }
+
+func TestFinished(t *testing.T) {
+	cases := []struct {
+		Case          string
+		Template      string
+		Expected      string
+		ExecutionTime int
+		Code          int
+		NoExitCode    bool
+	}{
+		{Case: "succeeded", Template: "{{ .Code }} {{ .Duration }}", ExecutionTime: 1234, Expected: "0 1.234s"},
+		{Case: "failed", Template: "{{ if .Code }}x{{ end }} {{ .Duration.Milliseconds }}", ExecutionTime: 12, Code: 2, Expected: "x 12"},
+		{Case: "timestamp", Template: `{{ if not .Timestamp.IsZero }}now{{ end }}`, Expected: "now"},
+		{Case: "no command", Template: "{{ .Code }}", NoExitCode: true},
+		{Case: "no template"},
+		{Case: "empty text", Template: "{{ if .Code }}x{{ end }}"},
+	}
+
+	for _, tc := range cases {
+		env := setupExtraPromptTest(t, shell.ZSH, &runtime.Flags{NoExitCode: tc.NoExitCode})
+		env.On("ExecutionTime").Return(tc.ExecutionTime)
+		template.Cache.Code = tc.Code
+
+		engine := &Engine{
+			Config: &config.Config{
+				TransientPrompt: &config.Segment{
+					Template:         "> ",
+					FinishedTemplate: tc.Template,
+				},
+			},
+			Env: env,
+		}
+
+		assert.Equal(t, tc.Expected, engine.Finished(), tc.Case)
+	}
+}
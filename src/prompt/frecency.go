@@ -0,0 +1,42 @@
+package prompt
+
+import (
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+const FrecencyPwdKey = "frecency_pwd"
+
+// recordVisit adds the working directory the shell reported to the frecency
+// database. Only a change of directory counts as a visit, rendering the
+// prompt again in the same folder does not. The database is updated while
+// the prompt renders, the returned function waits for that to finish.
+func (e *Engine) recordVisit() func() {
+	if e.Config.Frecency == nil {
+		return func() {}
+	}
+
+	pwd := e.Env.Pwd()
+	if len(pwd) == 0 {
+		return func() {}
+	}
+
+	if last, ok := cache.Get[string](cache.Session, FrecencyPwdKey); ok && last == pwd {
+		return func() {}
+	}
+
+	cache.Set(cache.Session, FrecencyPwdKey, pwd, cache.INFINITE)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		if err := frecency.Visit(pwd, e.Config.Frecency); err != nil {
+			log.Error(err)
+		}
+	}()
+
+	return func() { <-done }
+}
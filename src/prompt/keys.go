@@ -0,0 +1,128 @@
+package prompt
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+	"github.com/jandedobbeleer/oh-my-posh/src/shell"
+	"github.com/jandedobbeleer/oh-my-posh/src/terminal"
+)
+
+// KeyBinding renders the output of the copy and open key bindings for the
+// given key chord: the clipboard content or the URL to open. The shell takes
+// care of the other actions.
+func (e *Engine) KeyBinding(key string) string {
+	var binding *shell.KeyBinding
+	for _, candidate := range e.Config.KeyBindings {
+		if strings.EqualFold(candidate.Key, key) {
+			binding = candidate
+			break
+		}
+	}
+
+	if binding == nil {
+		log.Errorf("no key binding for %s", key)
+		return ""
+	}
+
+	segment := e.keyBindingSegment(binding.Segment)
+	if segment == nil {
+		log.Errorf("key binding %s: no enabled segment %s", key, binding.Segment)
+		return ""
+	}
+
+	switch binding.Action {
+	case shell.CopyAction:
+		text := e.keyBindingText(segment, binding.Template)
+		if len(text) == 0 {
+			return ""
+		}
+
+		// PSReadLine sets the clipboard itself
+		if e.Env.Shell() == shell.PWSH {
+			return text
+		}
+
+		return terminal.Clipboard(text)
+	case shell.OpenAction:
+		tpl := binding.Template
+		if len(tpl) == 0 {
+			tpl = "{{ .URL }}"
+		}
+
+		text, err := segment.RenderTemplate(tpl)
+		if err != nil {
+			log.Error(err)
+			return ""
+		}
+
+		return webURL(key, strings.TrimSpace(text))
+	default:
+		return ""
+	}
+}
+
+// keyBindingSegment executes the segment with the given alias or type and
+// returns it when it's enabled.
+func (e *Engine) keyBindingSegment(key string) *config.Segment {
+	for _, block := range e.Config.Blocks {
+		for _, segment := range block.Segments {
+			if segment.DataKey() != key {
+				continue
+			}
+
+			segment.Execute(e.Env)
+			if !segment.Enabled {
+				return nil
+			}
+
+			return segment
+		}
+	}
+
+	return nil
+}
+
+// keyBindingText renders the template against the segment, or the segment's
+// own text when there is none, without any colors or escape sequences.
+func (e *Engine) keyBindingText(segment *config.Segment, tpl string) string {
+	if len(tpl) == 0 {
+		if !segment.Render(0, false) {
+			return ""
+		}
+
+		return e.plainText(segment, segment.Text())
+	}
+
+	text, err := segment.RenderTemplate(tpl)
+	if err != nil {
+		log.Error(err)
+		return ""
+	}
+
+	return e.plainText(segment, text)
+}
+
+func (e *Engine) plainText(segment *config.Segment, text string) string {
+	terminal.Init(shell.GENERIC)
+	terminal.Plain = true
+
+	terminal.Write(segment.ResolveBackground(), segment.ResolveForeground(), text)
+	plain, _ := terminal.String()
+
+	return strings.TrimSpace(plain)
+}
+
+// webURL only lets http(s) URLs through, the shell hands the value to the
+// OS opener which would just as well launch a local file or pass arguments.
+func webURL(key, text string) string {
+	link, err := url.Parse(text)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || len(link.Host) == 0 {
+		log.Errorf("key binding %s: not an http(s) URL: %s", key, text)
+		return ""
+	}
+
+	return link.String()
+}
@@ -0,0 +1,64 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/shell"
+	"github.com/jandedobbeleer/oh-my-posh/src/terminal"
+
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestKeyBinding(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Shell    string
+		Key      string
+		Expected string
+	}{
+		{Case: "copy the segment text", Shell: shell.ZSH, Key: "alt+c", Expected: terminal.Clipboard("hello world")},
+		{Case: "copy in pwsh", Shell: shell.PWSH, Key: "alt+c", Expected: "hello world"},
+		{Case: "copy a template", Shell: shell.FISH, Key: "alt+t", Expected: terminal.Clipboard("hello")},
+		{Case: "open a URL", Shell: shell.ZSH, Key: "ALT+O", Expected: "https://ohmyposh.dev"},
+		{Case: "open a local file", Shell: shell.ZSH, Key: "alt+f"},
+		{Case: "open a local path", Shell: shell.ZSH, Key: "alt+p"},
+		{Case: "open with arguments", Shell: shell.PWSH, Key: "alt+a"},
+		{Case: "unknown key", Shell: shell.ZSH, Key: "alt+u"},
+		{Case: "unknown segment", Shell: shell.ZSH, Key: "alt+s"},
+	}
+
+	for _, tc := range cases {
+		env := setupExtraPromptTest(t, tc.Shell, &runtime.Flags{})
+		env.On("Pwd").Return("/home/jan")
+		env.On("Home").Return("/home/jan")
+		env.On("GOOS").Return(runtime.LINUX)
+		env.On("DirMatchesOneOf", testifymock.Anything, testifymock.Anything).Return(false)
+
+		engine := &Engine{
+			Env: env,
+			Config: &config.Config{
+				Blocks: []*config.Block{
+					{
+						Segments: []*config.Segment{
+							{Type: config.TEXT, Alias: "greeting", Foreground: "white", Template: "<b>hello</b> world"},
+						},
+					},
+				},
+				KeyBindings: []*shell.KeyBinding{
+					{Key: "alt+c", Action: shell.CopyAction, Segment: "greeting"},
+					{Key: "alt+t", Action: shell.CopyAction, Segment: "greeting", Template: "{{ if .Segment }}hello{{ end }}"},
+					{Key: "alt+o", Action: shell.OpenAction, Segment: "greeting", Template: "https://ohmyposh.dev"},
+					{Key: "alt+f", Action: shell.OpenAction, Segment: "greeting", Template: "file:///etc/passwd"},
+					{Key: "alt+p", Action: shell.OpenAction, Segment: "greeting", Template: "/usr/bin/calc"},
+					{Key: "alt+a", Action: shell.OpenAction, Segment: "greeting", Template: "https://ohmyposh.dev -ArgumentList calc.exe"},
+					{Key: "alt+s", Action: shell.CopyAction, Segment: "git"},
+				},
+			},
+		}
+
+		assert.Equal(t, tc.Expected, engine.KeyBinding(tc.Key), tc.Case)
+	}
+}
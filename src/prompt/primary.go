@@ -3,9 +3,11 @@ package prompt
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
 	"github.com/jandedobbeleer/oh-my-posh/src/config"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
 	"github.com/jandedobbeleer/oh-my-posh/src/shell"
 	"github.com/jandedobbeleer/oh-my-posh/src/template"
 	"github.com/jandedobbeleer/oh-my-posh/src/terminal"
@@ -16,8 +18,18 @@ func (e *Engine) Primary() string {
 }
 
 func (e *Engine) primaryInternal(fromCache bool) string {
+	if log.Tracing() {
+		defer log.Span(time.Now(), "prompt", "primary")
+	}
+
 	e.startRunCapture()
 
+	// re-renders from cache belong to the same visit
+	if !fromCache {
+		wait := e.recordVisit()
+		defer wait()
+	}
+
 	needsPrimaryRightPrompt := e.needsPrimaryRightPrompt()
 
 	e.writePrimaryPromptInternal(needsPrimaryRightPrompt, fromCache)
@@ -56,7 +68,7 @@ func (e *Engine) writePrimaryPrompt(needsPrimaryRPrompt bool) {
 }
 
 func (e *Engine) writePrimaryPromptInternal(needsPrimaryRPrompt, fromCache bool) {
-	if e.Config.ShellIntegration {
+	if e.shellIntegration() {
 		exitCode, _ := e.Env.StatusCodes()
 		e.write(terminal.CommandFinished(exitCode, e.Env.Flags().NoExitCode))
 		e.write(terminal.PromptStart())
@@ -80,6 +92,15 @@ func (e *Engine) writePrimaryPromptInternal(needsPrimaryRPrompt, fromCache bool)
 	var launched []chan result
 	if !fromCache {
 		launched = make([]chan result, len(blocks))
+
+		for _, block := range blocks {
+			if block.Type == config.RPrompt && !needsPrimaryRPrompt {
+				continue
+			}
+
+			e.trackSegments(block.Segments)
+		}
+
 		for i, block := range blocks {
 			if block.Type == config.RPrompt && !needsPrimaryRPrompt {
 				continue
@@ -170,13 +191,24 @@ func (e *Engine) writePrimaryPromptInternal(needsPrimaryRPrompt, fromCache bool)
 		}
 	}
 
-	if e.Config.ShellIntegration {
+	if e.shellIntegration() {
 		e.write(terminal.CommandStart())
 	}
 
 	e.pwd()
 }
 
+// shellIntegration tells if the prompts need the semantic prompt marks. Elvish
+// and Xonsh can't render them, Elvish emits them from its hooks instead.
+func (e *Engine) shellIntegration() bool {
+	if !e.Config.ShellIntegration {
+		return false
+	}
+
+	sh := e.Env.Shell()
+	return sh != shell.ELVISH && sh != shell.XONSH
+}
+
 func (e *Engine) needsPrimaryRightPrompt() bool {
 	if e.Env.Flags().Debug {
 		return true
@@ -0,0 +1,51 @@
+package prompt
+
+import (
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/template"
+)
+
+// SegmentTiming is the execution time of a single segment.
+type SegmentTiming struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration_ns"`
+	Demoted  string        `json:"demoted,omitempty"`
+	Enabled  bool          `json:"enabled"`
+}
+
+// Profile is the machine readable counterpart of PrintDebug: the rendered
+// prompt together with the time spent in every segment, template and template function.
+type Profile struct {
+	Prompt    string            `json:"prompt"`
+	Segments  []SegmentTiming   `json:"segments"`
+	Templates []template.Timing `json:"templates"`
+	Functions []template.Timing `json:"functions"`
+}
+
+// Profile collects the timings of the render that produced prompt. Segment
+// durations are only recorded when runtime.Flags.Profile or Debug is set,
+// template timings only after template.EnableProfiling.
+func (e *Engine) Profile(prompt string) *Profile {
+	templates := template.GetProfile()
+
+	profile := &Profile{
+		Prompt:    prompt,
+		Segments:  []SegmentTiming{},
+		Templates: templates.Templates,
+		Functions: templates.Functions,
+	}
+
+	for _, block := range e.Config.Blocks {
+		for _, segment := range block.Segments {
+			profile.Segments = append(profile.Segments, SegmentTiming{
+				Name:     segment.Name(),
+				Duration: segment.Duration,
+				Enabled:  segment.Enabled,
+				Demoted:  string(segment.Demoted),
+			})
+		}
+	}
+
+	return profile
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/jandedobbeleer/oh-my-posh/src/config"
 	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
 	"github.com/jandedobbeleer/oh-my-posh/src/shell"
+	"github.com/jandedobbeleer/oh-my-posh/src/terminal"
 )
 
 const (
@@ -42,6 +43,11 @@ func (e *Engine) RPrompt() string {
 		text += " "
 	}
 
+	// the input starts after the primary prompt, which has the B mark
+	if e.shellIntegration() {
+		text = terminal.PromptKindStart(terminal.RightPrompt) + text
+	}
+
 	if !e.Config.ToolTipsAction.IsDefault() {
 		cache.Set(cache.Session, RPromptKey, text, cache.INFINITE)
 		cache.Set(cache.Session, RPromptLengthKey, e.rpromptLength, cache.INFINITE)
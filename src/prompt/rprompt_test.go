@@ -0,0 +1,51 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/config"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/shell"
+
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestRPromptShellIntegration(t *testing.T) {
+	cases := []struct {
+		Case             string
+		Template         string
+		Expected         string
+		ShellIntegration bool
+	}{
+		{Case: "no shell integration", Template: "right", Expected: "right"},
+		{Case: "shell integration", Template: "right", ShellIntegration: true, Expected: "\x1b]133;P;k=r\aright"},
+		{Case: "empty right prompt", ShellIntegration: true},
+	}
+
+	for _, tc := range cases {
+		env := setupExtraPromptTest(t, shell.FISH, &runtime.Flags{})
+		env.On("Pwd").Return("/home/jan")
+		env.On("Home").Return("/home/jan")
+		env.On("GOOS").Return(runtime.LINUX)
+		env.On("DirMatchesOneOf", testifymock.Anything, testifymock.Anything).Return(false)
+		env.On("TerminalWidth").Return(100, nil)
+
+		engine := &Engine{
+			Config: &config.Config{
+				ShellIntegration: tc.ShellIntegration,
+				Blocks: []*config.Block{
+					{
+						Type: config.RPrompt,
+						Segments: []*config.Segment{
+							{Type: config.TEXT, Foreground: "white", Template: tc.Template},
+						},
+					},
+				},
+			},
+			Env: env,
+		}
+
+		assert.Equal(t, tc.Expected, engine.RPrompt(), tc.Case)
+	}
+}
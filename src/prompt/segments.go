@@ -1,6 +1,8 @@
 package prompt
 
 import (
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/config"
@@ -15,6 +17,8 @@ type result struct {
 }
 
 func (e *Engine) writeBlockSegments(block *config.Block) (string, int) {
+	e.trackSegments(block.Segments)
+
 	out := e.launchBlockSegments(block)
 	if out == nil {
 		return "", 0
@@ -64,6 +68,10 @@ func drainBlockResults(out chan result, count int, executed map[string]bool) []*
 // fully populated for all blocks before this is called (via drainBlockResults),
 // so that cross-block .Segments.X dependencies resolve in both directions.
 func (e *Engine) renderBlockSegments(results []*config.Segment, block *config.Block, executed map[string]bool) (string, int, []terminal.Run) {
+	if log.Tracing() {
+		defer log.Span(time.Now(), "writer", strings.TrimSpace(string(block.Type)+" "+string(block.Alignment)))
+	}
+
 	if block.RestartCycle {
 		cycle = &e.Config.Cycle
 	}
@@ -92,6 +100,9 @@ func (e *Engine) renderBlockSegments(results []*config.Segment, block *config.Bl
 }
 
 func (e *Engine) writeSegmentsConcurrently(segments []*config.Segment, out chan result) {
+	share := e.latencyShare()
+	streaming := e.Env.Flags().Streaming && e.Config.Streaming > 0
+
 	for i, segment := range segments {
 		// In streaming mode, pre-register all segments as pending
 		// This ensures countPendingSegments() sees them before timeout occurs.
@@ -104,13 +115,25 @@ func (e *Engine) writeSegmentsConcurrently(segments []*config.Segment, out chan
 			e.pendingSegments.Store(segment.Name(), true)
 		}
 
+		// applied after the streaming timeout so an async demotion can lower it
+		if share > 0 {
+			segment.ApplyLatencyBudget(share, streaming)
+		}
+
 		go func(segment *config.Segment, index int) {
+			start := time.Now()
+
+			e.waitForSegments(segment)
+
+			// the wait for the when expression counts towards the timeout
 			if segment.Timeout > 0 {
-				e.executeSegmentWithTimeout(segment)
+				e.executeSegmentWithTimeout(segment, time.Duration(segment.Timeout)*time.Millisecond-time.Since(start))
 			} else {
 				segment.Execute(e.Env)
 			}
 
+			e.segmentExecuted(segment)
+
 			out <- result{segment, index}
 
 			// In streaming mode, clean up pre-registered segments that completed before timeout
@@ -121,7 +144,26 @@ func (e *Engine) writeSegmentsConcurrently(segments []*config.Segment, out chan
 	}
 }
 
-func (e *Engine) executeSegmentWithTimeout(segment *config.Segment) {
+// latencyShare is every segment's even part of the config's latency_budget,
+// zero when there is no budget.
+func (e *Engine) latencyShare() time.Duration {
+	if e.Config.LatencyBudget <= 0 {
+		return 0
+	}
+
+	count := 0
+	for _, block := range e.Config.Blocks {
+		count += len(block.Segments)
+	}
+
+	if count == 0 {
+		return 0
+	}
+
+	return time.Duration(e.Config.LatencyBudget) * time.Millisecond / time.Duration(count)
+}
+
+func (e *Engine) executeSegmentWithTimeout(segment *config.Segment, timeout time.Duration) {
 	done := make(chan bool)
 	gidChan := make(chan uint64, 1)
 
@@ -133,7 +175,7 @@ func (e *Engine) executeSegmentWithTimeout(segment *config.Segment) {
 
 	gid := <-gidChan
 
-	timer := time.NewTimer(time.Duration(segment.Timeout) * time.Millisecond)
+	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 
 	select {
@@ -219,3 +261,63 @@ func (e *Engine) canRenderSegment(segment *config.Segment, executed map[string]b
 
 	return true
 }
+
+// whenWaitTimeout caps how long a segment's when expression waits for the
+// segments it refers to, a segment's own timeout lowers it. Expressions
+// referring to each other are broken up when the config loads.
+const whenWaitTimeout = 2 * time.Second
+
+// trackSegments registers segments as executing before any of them is
+// launched, so a when expression can wait for a segment that is launched
+// after its own, for example one in a later block.
+func (e *Engine) trackSegments(segments []*config.Segment) {
+	for _, segment := range segments {
+		wg, _ := e.executing.LoadOrStore(segment.Name(), &sync.WaitGroup{})
+		wg.(*sync.WaitGroup).Add(1)
+	}
+}
+
+func (e *Engine) segmentExecuted(segment *config.Segment) {
+	if wg, ok := e.executing.Load(segment.Name()); ok {
+		wg.(*sync.WaitGroup).Done()
+	}
+}
+
+// waitForSegments blocks until every segment the when expression of segment
+// refers to has executed. Segments that are not part of this render are not
+// waited for, the expression sees them as disabled.
+func (e *Engine) waitForSegments(segment *config.Segment) {
+	needs := segment.WhenNeeds()
+	if len(needs) == 0 {
+		return
+	}
+
+	wait := whenWaitTimeout
+	if segment.Timeout > 0 {
+		wait = min(wait, time.Duration(segment.Timeout)*time.Millisecond)
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	for _, name := range needs {
+		wg, ok := e.executing.Load(name)
+		if !ok {
+			continue
+		}
+
+		done := make(chan struct{})
+
+		go func() {
+			wg.(*sync.WaitGroup).Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-deadline.C:
+			log.Errorf("segment %s: timeout waiting for segment %s", segment.Name(), name)
+			return
+		}
+	}
+}
@@ -256,3 +256,78 @@ func TestExecuteSegmentWithTimeout_CachedValueFallback(t *testing.T) {
 	text = segment.Text()
 	assert.NotEqual(t, "...", text, "Non-pending segment should show actual content")
 }
+
+func TestWaitForSegments(t *testing.T) {
+	engine := &Engine{}
+
+	other := &config.Segment{Type: "text", Alias: "Other"}
+	segment := &config.Segment{Type: "text", When: `{{ and (.Segments.Contains "Other") (.Segments.Contains "Missing") }}`}
+
+	engine.trackSegments([]*config.Segment{other, segment})
+
+	done := make(chan struct{})
+
+	go func() {
+		engine.waitForSegments(segment)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("when expression did not wait for the segment it refers to")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	engine.segmentExecuted(other)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("when expression kept waiting after the segment executed")
+	}
+}
+
+func TestWaitForSegmentsTimeout(t *testing.T) {
+	engine := &Engine{}
+
+	other := &config.Segment{Type: "text", Alias: "Other"}
+	segment := &config.Segment{Type: "text", When: `{{ .Segments.Contains "Other" }}`, Timeout: 50}
+
+	engine.trackSegments([]*config.Segment{other, segment})
+
+	done := make(chan struct{})
+
+	go func() {
+		engine.waitForSegments(segment)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(whenWaitTimeout / 2):
+		t.Fatal("the segment's timeout did not cap the wait")
+	}
+}
+
+func TestLatencyShare(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Blocks   []*config.Block
+		Budget   int
+		Expected time.Duration
+	}{
+		{Case: "No budget", Blocks: []*config.Block{{Segments: []*config.Segment{{}}}}},
+		{Case: "No segments", Budget: 100},
+		{
+			Case:     "Even share across blocks",
+			Budget:   120,
+			Blocks:   []*config.Block{{Segments: []*config.Segment{{}, {}}}, {Segments: []*config.Segment{{}}}},
+			Expected: 40 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range cases {
+		engine := &Engine{Config: &config.Config{LatencyBudget: tc.Budget, Blocks: tc.Blocks}}
+		assert.Equal(t, tc.Expected, engine.latencyShare(), tc.Case)
+	}
+}
@@ -1,7 +1,6 @@
 package prompt
 
 import (
-	"slices"
 	"strings"
 
 	"github.com/jandedobbeleer/oh-my-posh/src/cache"
@@ -41,12 +40,13 @@ func (e *Engine) tooltipFallback() string {
 	}
 }
 
-func (e *Engine) Tooltip(tip string) string {
-	tip = strings.Trim(tip, " ")
+// matchingTooltips executes the tooltips matching the command line and
+// returns the enabled ones.
+func (e *Engine) matchingTooltips(line string) []*config.Segment {
 	tooltips := make([]*config.Segment, 0, 1)
 
 	for _, tooltip := range e.Config.Tooltips {
-		if !slices.Contains(tooltip.Tips, tip) {
+		if !tooltip.MatchesTip(line) {
 			continue
 		}
 
@@ -59,6 +59,12 @@ func (e *Engine) Tooltip(tip string) string {
 		tooltips = append(tooltips, tooltip)
 	}
 
+	return tooltips
+}
+
+func (e *Engine) Tooltip(tip string) string {
+	tooltips := e.matchingTooltips(strings.Trim(tip, " "))
+
 	if len(tooltips) == 0 {
 		return e.tooltipFallback()
 	}
@@ -124,3 +130,31 @@ func (e *Engine) handleToolTipAction(text string, length int) (string, int) {
 
 	return text, length
 }
+
+// InfoPanelMarker prefixes a streamed record that contains the info panel
+// rather than a primary prompt update.
+const InfoPanelMarker = "\x1d"
+
+// InfoPanel renders the tooltips matching the command line as a left aligned
+// line the shell prints above the prompt. Unlike a tooltip, it is not part of
+// the prompt, so the text is not escaped for the shell.
+func (e *Engine) InfoPanel(line string) string {
+	tooltips := e.matchingTooltips(strings.TrimSpace(line))
+	if len(tooltips) == 0 {
+		return ""
+	}
+
+	terminal.Init(shell.GENERIC)
+
+	block := &config.Block{
+		Alignment: config.Left,
+		Segments:  tooltips,
+	}
+
+	text, length := e.writeBlockSegments(block)
+	if length == 0 {
+		return ""
+	}
+
+	return text
+}
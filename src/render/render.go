@@ -92,6 +92,8 @@ func Config(cfg *config.Config, terminalWidth int, resetTemplateCache bool, appl
 	}
 
 	template.Init(env, cfg.Var, cfg.Maps)
+	template.SetCapabilities(cfg.TemplateCapabilities)
+	template.SetPartials(cfg.Partials)
 
 	// set sane defaults for things we don't print/need while rendering for export
 	cfg.ConsoleTitleTemplate = ""
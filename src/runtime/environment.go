@@ -113,6 +113,9 @@ type Flags struct {
 	Force         bool
 	Streaming     bool
 	Interrupted   bool
+	// Profile records segment durations like Debug does, without any of
+	// Debug's other side effects on the rendered prompt.
+	Profile bool
 	// DataOnly cuts this environment off from the machine: every method that
 	// would read a file, list a directory, resolve a symlink, run a command,
 	// make a request or read an OS variable answers empty or errDataOnly (see
@@ -131,6 +134,9 @@ type Flags struct {
 	// entire point; on for a caller rendering a config it was handed rather
 	// than one the user owns, such as the studio.
 	DataOnly bool
+	// EnvVars are the OS variables a recorded debug bundle captured. Under
+	// DataOnly, Getenv answers from these instead of answering empty.
+	EnvVars map[string]string
 }
 
 type CommandError struct {
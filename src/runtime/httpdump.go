@@ -5,6 +5,8 @@ package runtime
 import (
 	"net/http"
 	"net/http/httputil"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
 )
 
 // dumpRequest renders a request for --debug output.
@@ -16,7 +18,30 @@ import (
 // would set: Host, User-Agent, Accept-Encoding. What a segment is actually debugged against - the
 // URL, its own headers, the body - is identical either way, and both restore the body after
 // reading it.
+//
+// Credentials in the headers and the query are redacted, the output ends up in log files.
 func dumpRequest(request *http.Request) string {
+	header := request.Header
+	request.Header = redactHeaders(header)
+
 	dump, _ := httputil.DumpRequest(request, true)
-	return string(dump)
+
+	request.Header = header
+
+	return log.Redact(string(dump))
+}
+
+// credentialHeaders are the request headers segments authenticate with.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Private-Token", "Cookie"}
+
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+
+	for _, name := range credentialHeaders {
+		if len(redacted.Values(name)) != 0 {
+			redacted.Set(name, "REDACTED")
+		}
+	}
+
+	return redacted
 }
@@ -0,0 +1,29 @@
+//go:build !(js && wasm)
+
+package runtime
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpRequestRedactsCredentials(t *testing.T) {
+	request, err := http.NewRequest(http.MethodGet, "https://api.openweathermap.org/data/2.5/weather?q=Ghent&appid=owm_secret", nil)
+	require.NoError(t, err)
+
+	request.Header.Set("Authorization", "Bearer ghp_secret")
+	request.Header.Set("PRIVATE-TOKEN", "glpat-secret")
+	request.Header.Set("Accept", "application/json")
+
+	dump := dumpRequest(request)
+
+	assert.NotContains(t, dump, "secret")
+	assert.Contains(t, dump, "GET /data/2.5/weather?q=Ghent&appid=REDACTED HTTP/1.1")
+	assert.Contains(t, dump, "Authorization: REDACTED")
+	assert.Contains(t, dump, "Private-Token: REDACTED")
+	assert.Contains(t, dump, "Accept: application/json")
+	assert.Equal(t, "Bearer ghp_secret", request.Header.Get("Authorization"), "the request itself keeps its credentials")
+}
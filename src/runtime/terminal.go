@@ -61,9 +61,10 @@ func (term *Terminal) Getenv(key string) string {
 	// has no environment either. Answering empty is what makes the CLI under
 	// DataOnly and the wasm build agree; reading the real environment would
 	// leave a segment keyed on, say, TERM_PROGRAM rendering one thing here and
-	// another there, from the same config and the same data.
+	// another there, from the same config and the same data. A replayed debug
+	// bundle is the exception: it recorded the variables its templates read.
 	if term.CmdFlags != nil && term.CmdFlags.DataOnly {
-		return ""
+		return term.CmdFlags.EnvVars[key]
 	}
 
 	val := os.Getenv(key)
@@ -505,7 +506,7 @@ func (term *Terminal) HTTPRequest(targetURL string, body io.Reader, timeout int,
 		return nil, err
 	}
 
-	log.Debug(string(responseBody))
+	log.Private(string(responseBody))
 
 	return responseBody, nil
 }
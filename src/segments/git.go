@@ -89,6 +89,9 @@ const (
 	TagIcon             options.Option = "tag_icon"
 	MergeIcon           options.Option = "merge_icon"
 	UpstreamIcons       options.Option = "upstream_icons"
+	ForgeHosts          options.Option = "forge_hosts"
+	ForgeAPIs           options.Option = "forge_apis"
+	PullRequestInterval options.Option = "pull_request_interval"
 	GithubIcon          options.Option = "github_icon"
 	BitbucketIcon       options.Option = "bitbucket_icon"
 	AzureDevOpsIcon     options.Option = "azure_devops_icon"
@@ -121,6 +124,8 @@ type Git struct {
 	Working        *GitStatus
 	Staging        *GitStatus
 	commit         *Commit
+	forgeRepo      *forgeRepo
+	pullRequest    *PullRequest
 	Rebase         *Rebase
 	User           *User
 	ShortHash      string
@@ -141,6 +146,8 @@ type Git struct {
 	worktreeCount    int
 	configOnce       sync.Once
 	mainWorktreeOnce sync.Once
+	forgeOnce        sync.Once
+	pullRequestOnce  sync.Once
 	IsWorkTree       bool
 	Merge            bool
 	CherryPick       bool
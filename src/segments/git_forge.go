@@ -0,0 +1,241 @@
+package segments
+
+import (
+	"fmt"
+	url2 "net/url"
+	"strings"
+)
+
+const (
+	forgeGitHub    = "github"
+	forgeGitLab    = "gitlab"
+	forgeBitbucket = "bitbucket"
+	forgeAzure     = "azure_devops"
+	forgeGitea     = "gitea"
+)
+
+// forgeRepo is the web UI of the repository the remote points to.
+type forgeRepo struct {
+	// base is the repository's web URL, like https://github.com/owner/repo
+	base string
+	kind string
+}
+
+// forgeKinds maps the hosts run by the forges themselves to the forge, forge_hosts adds self-hosted
+// instances. A host that merely contains a forge's name, like gitlab.example.com, is unknown.
+var forgeKinds = map[string]string{
+	"github.com":    forgeGitHub,
+	"gitlab.com":    forgeGitLab,
+	"bitbucket.org": forgeBitbucket,
+	"dev.azure.com": forgeAzure,
+	"codeberg.org":  forgeGitea,
+	"gitea.com":     forgeGitea,
+}
+
+// azureLegacyDomain hosts the Azure DevOps organizations that predate dev.azure.com, as <org>.visualstudio.com.
+const azureLegacyDomain = ".visualstudio.com"
+
+// forge resolves the web UI of the upstream remote, nil when the remote is unknown or
+// not hosted on a supported forge.
+func (g *Git) forge() *forgeRepo {
+	g.forgeOnce.Do(func() {
+		if len(g.RawUpstreamURL) == 0 {
+			g.RawUpstreamURL = g.getRemoteURL()
+		}
+
+		if len(g.RawUpstreamURL) == 0 {
+			return
+		}
+
+		web, err := url2.Parse(g.cleanUpstreamURL(g.RawUpstreamURL))
+		if err != nil || len(web.Host) == 0 {
+			return
+		}
+
+		kind := g.forgeKind(web)
+		if len(kind) == 0 {
+			return
+		}
+
+		// credentials in an HTTPS remote must never end up in a link
+		web.User = nil
+		web.RawQuery = ""
+		web.Fragment = ""
+		web.Path = strings.TrimSuffix(strings.TrimSuffix(web.Path, "/"), ".git")
+		web.RawPath = ""
+
+		if web.Scheme != "http" {
+			web.Scheme = "https"
+		}
+
+		g.forgeRepo = &forgeRepo{
+			base: web.String(),
+			kind: kind,
+		}
+	})
+
+	return g.forgeRepo
+}
+
+func (g *Git) forgeKind(web *url2.URL) string {
+	// user mappings first, for self-hosted instances
+	custom := g.options.KeyValueMap(ForgeHosts, map[string]string{})
+	if kind, ok := custom[web.Host]; ok {
+		return kind
+	}
+
+	if kind, ok := custom[web.Hostname()]; ok {
+		return kind
+	}
+
+	host := strings.ToLower(web.Hostname())
+	if kind, ok := forgeKinds[host]; ok {
+		return kind
+	}
+
+	if strings.HasSuffix(host, azureLegacyDomain) {
+		return forgeAzure
+	}
+
+	return ""
+}
+
+// remoteBranch returns the name of the branch on the remote, which can differ from the local one.
+func (g *Git) remoteBranch() string {
+	if remote, branch, found := strings.Cut(g.Upstream, "/"); found && len(remote) != 0 {
+		return branch
+	}
+
+	if g.Detached || g.Ref == DETACHED {
+		return ""
+	}
+
+	return g.Ref
+}
+
+// defaultBranch returns the branch the remote HEAD points to, main when it's not known.
+func (g *Git) defaultBranch() string {
+	remote, _, _ := strings.Cut(g.Upstream, "/")
+	if len(remote) == 0 {
+		remote = origin
+	}
+
+	head := g.fileContent(g.mainSCMDir, "refs/remotes/"+remote+"/HEAD")
+	if branch, found := strings.CutPrefix(head, "ref: refs/remotes/"+remote+"/"); found {
+		return branch
+	}
+
+	return "main"
+}
+
+// escapeRef escapes a branch name for use in a URL path, keeping the slashes.
+func escapeRef(ref string) string {
+	parts := strings.Split(ref, "/")
+	for i, part := range parts {
+		parts[i] = url2.PathEscape(part)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// BranchURL is the web page of the current branch on the forge.
+func (g *Git) BranchURL() string {
+	forge := g.forge()
+	branch := g.remoteBranch()
+
+	if forge == nil || len(branch) == 0 {
+		return ""
+	}
+
+	switch forge.kind {
+	case forgeGitHub:
+		return fmt.Sprintf("%s/tree/%s", forge.base, escapeRef(branch))
+	case forgeGitLab:
+		return fmt.Sprintf("%s/-/tree/%s", forge.base, escapeRef(branch))
+	case forgeBitbucket:
+		return fmt.Sprintf("%s/src/%s", forge.base, escapeRef(branch))
+	case forgeAzure:
+		return fmt.Sprintf("%s?version=GB%s", forge.base, url2.QueryEscape(branch))
+	case forgeGitea:
+		return fmt.Sprintf("%s/src/branch/%s", forge.base, escapeRef(branch))
+	}
+
+	return ""
+}
+
+// CommitURL is the web page of the HEAD commit on the forge.
+func (g *Git) CommitURL() string {
+	forge := g.forge()
+	if forge == nil {
+		return ""
+	}
+
+	if len(g.Hash) == 0 {
+		g.Hash = g.getGitCommandOutput("rev-parse", "HEAD")
+	}
+
+	if len(g.Hash) == 0 {
+		return ""
+	}
+
+	switch forge.kind {
+	case forgeGitLab:
+		return fmt.Sprintf("%s/-/commit/%s", forge.base, g.Hash)
+	case forgeBitbucket:
+		return fmt.Sprintf("%s/commits/%s", forge.base, g.Hash)
+	case forgeGitHub, forgeAzure, forgeGitea:
+		return fmt.Sprintf("%s/commit/%s", forge.base, g.Hash)
+	}
+
+	return ""
+}
+
+// CompareURL is the web page comparing the current branch with the default branch on the forge.
+func (g *Git) CompareURL() string {
+	forge := g.forge()
+	branch := g.remoteBranch()
+
+	if forge == nil || len(branch) == 0 {
+		return ""
+	}
+
+	base := g.defaultBranch()
+
+	switch forge.kind {
+	case forgeGitHub, forgeGitea:
+		return fmt.Sprintf("%s/compare/%s...%s", forge.base, escapeRef(base), escapeRef(branch))
+	case forgeGitLab:
+		return fmt.Sprintf("%s/-/compare/%s...%s", forge.base, escapeRef(base), escapeRef(branch))
+	case forgeBitbucket:
+		return fmt.Sprintf("%s/branches/compare/%s%%0D%s", forge.base, escapeRef(branch), escapeRef(base))
+	case forgeAzure:
+		return fmt.Sprintf("%s/branchCompare?baseVersion=GB%s&targetVersion=GB%s", forge.base, url2.QueryEscape(base), url2.QueryEscape(branch))
+	}
+
+	return ""
+}
+
+// PullRequestURL is the web page to open a pull request for the current branch on the forge.
+func (g *Git) PullRequestURL() string {
+	forge := g.forge()
+	branch := g.remoteBranch()
+
+	if forge == nil || len(branch) == 0 {
+		return ""
+	}
+
+	switch forge.kind {
+	case forgeGitHub:
+		return fmt.Sprintf("%s/pull/new/%s", forge.base, escapeRef(branch))
+	case forgeGitLab:
+		return fmt.Sprintf("%s/-/merge_requests/new?%s", forge.base, url2.Values{"merge_request[source_branch]": {branch}}.Encode())
+	case forgeBitbucket:
+		return fmt.Sprintf("%s/pull-requests/new?%s", forge.base, url2.Values{"source": {branch}}.Encode())
+	case forgeAzure:
+		return fmt.Sprintf("%s/pullrequestcreate?%s", forge.base, url2.Values{"sourceRef": {branch}}.Encode())
+	case forgeGitea:
+		return fmt.Sprintf("%s/compare/%s...%s", forge.base, escapeRef(g.defaultBranch()), escapeRef(branch))
+	}
+
+	return ""
+}
@@ -0,0 +1,626 @@
+package segments
+
+import (
+	"fmt"
+	httplib "net/http"
+	url2 "net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/http"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/jobs"
+	"github.com/jandedobbeleer/oh-my-posh/src/segments/options"
+
+	yaml "go.yaml.in/yaml/v3"
+)
+
+const (
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+	ReviewPending          = "pending"
+
+	ChecksSuccess = "success"
+	ChecksFailure = "failure"
+	ChecksPending = "pending"
+
+	pullRequestCacheKey = "git_pull_request"
+	// the refresh runs in the background, it can afford more than the prompt's default
+	pullRequestTimeout = 5000
+)
+
+// PullRequest is the open pull (or merge) request of the current branch.
+type PullRequest struct {
+	URL    string
+	Title  string
+	Review string
+	Checks string
+	Number int
+	Draft  bool
+}
+
+// PullRequestCache is what the device cache holds for a branch, PullRequest is nil when the
+// branch has no open pull request.
+type PullRequestCache struct {
+	PullRequest *PullRequest
+	Fetched     int64
+}
+
+// PullRequestQuery identifies a branch on a forge API. Trusted tells the host is the forge's own
+// (like gitlab.com) or listed in forge_hosts or forge_apis, only then are tokens from the
+// environment sent along.
+type PullRequestQuery struct {
+	Forge   string
+	API     string
+	Host    string
+	Repo    string
+	Branch  string
+	Timeout int
+	Trusted bool
+}
+
+func (q *PullRequestQuery) cacheKey() string {
+	return fmt.Sprintf("%s_%s/%s:%s", pullRequestCacheKey, q.API, q.Repo, q.Branch)
+}
+
+// Args are the command line arguments of the oh-my-posh process refreshing the query.
+func (q *PullRequestQuery) Args() []string {
+	args := []string{
+		"pull-request",
+		"--forge", q.Forge,
+		"--api", q.API,
+		"--host", q.Host,
+		"--repo", q.Repo,
+		"--branch", q.Branch,
+		"--timeout", strconv.Itoa(q.Timeout),
+	}
+
+	if q.Trusted {
+		args = append(args, "--trusted")
+	}
+
+	return args
+}
+
+// canonicalForgeHosts are the hosts run by the forge itself, their tokens are meant for them.
+var canonicalForgeHosts = map[string]string{
+	forgeGitHub: "github.com",
+	forgeGitLab: "gitlab.com",
+	forgeGitea:  "codeberg.org",
+}
+
+// refreshPullRequest starts a detached oh-my-posh process that fetches the pull request,
+// the prompt never waits for the forge API.
+var refreshPullRequest = func(query *PullRequestQuery) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable, query.Args()...)
+	jobs.SetProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// reap the child when we live long enough, like in serve mode
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
+}
+
+// PullRequest is the open pull request of the current branch, read from the device cache.
+// When the cached value is older than pull_request_interval, a background process refreshes it
+// for the next prompt.
+func (g *Git) PullRequest() *PullRequest {
+	g.pullRequestOnce.Do(func() {
+		query := g.pullRequestQuery()
+		if query == nil {
+			return
+		}
+
+		key := query.cacheKey()
+		interval := g.options.String(PullRequestInterval, "5m")
+
+		data, ok := cache.Get[*PullRequestCache](cache.Device, key)
+		if ok {
+			g.pullRequest = data.PullRequest
+		}
+
+		if ok && time.Now().Unix()-data.Fetched < int64(cache.Duration(interval).Seconds()) {
+			return
+		}
+
+		if g.env.Flags().DataOnly {
+			return
+		}
+
+		// only one refresh at a time, the marker expires when the process fails
+		refreshKey := key + "_refresh"
+		if _, refreshing := cache.Get[bool](cache.Device, refreshKey); refreshing {
+			return
+		}
+
+		cache.Set(cache.Device, refreshKey, true, "1m")
+
+		if err := refreshPullRequest(query); err != nil {
+			log.Error(err)
+		}
+	})
+
+	return g.pullRequest
+}
+
+func (g *Git) pullRequestQuery() *PullRequestQuery {
+	forge := g.forge()
+	branch := g.remoteBranch()
+
+	if forge == nil || len(branch) == 0 {
+		return nil
+	}
+
+	web, err := url2.Parse(forge.base)
+	if err != nil {
+		return nil
+	}
+
+	query := &PullRequestQuery{
+		Forge:   forge.kind,
+		Host:    web.Host,
+		Repo:    strings.TrimPrefix(web.Path, "/"),
+		Branch:  branch,
+		Timeout: g.options.Int(options.HTTPTimeout, pullRequestTimeout),
+	}
+
+	apis := g.options.KeyValueMap(ForgeAPIs, map[string]string{})
+	hosts := g.options.KeyValueMap(ForgeHosts, map[string]string{})
+
+	// only the forge's own host, or one the user listed, is trusted with a token
+	query.Trusted = web.Hostname() == canonicalForgeHosts[forge.kind] ||
+		isListedHost(apis, web) || isListedHost(hosts, web)
+
+	for host, api := range apis {
+		if strings.Contains(web.Host, host) {
+			query.API = strings.TrimSuffix(api, "/")
+			return query
+		}
+	}
+
+	origin := web.Scheme + "://" + web.Host
+
+	switch forge.kind {
+	case forgeGitHub:
+		query.API = origin + "/api/v3"
+		if web.Host == "github.com" {
+			query.API = "https://api.github.com"
+		}
+	case forgeGitLab:
+		query.API = origin + "/api/v4"
+	case forgeGitea:
+		query.API = origin + "/api/v1"
+	default:
+		return nil
+	}
+
+	return query
+}
+
+// isListedHost tells whether the host of the URL is a key of the map, as is.
+func isListedHost(hosts map[string]string, web *url2.URL) bool {
+	if _, ok := hosts[web.Host]; ok {
+		return true
+	}
+
+	_, ok := hosts[web.Hostname()]
+	return ok
+}
+
+// RefreshPullRequest fetches the open pull request of the branch from the forge API and stores it
+// in the device cache.
+func RefreshPullRequest(env runtime.Environment, query *PullRequestQuery) error {
+	client := &forgeClient{
+		env:   env,
+		query: query,
+		request: &http.Request{
+			Env:         env,
+			HTTPTimeout: query.Timeout,
+		},
+	}
+
+	var pullRequest *PullRequest
+	var err error
+
+	switch query.Forge {
+	case forgeGitHub:
+		pullRequest, err = client.gitHub()
+	case forgeGitLab:
+		pullRequest, err = client.gitLab()
+	case forgeGitea:
+		pullRequest, err = client.gitea()
+	default:
+		err = fmt.Errorf("unsupported forge: %s", query.Forge)
+	}
+
+	if err != nil {
+		// keep the previous value, it's better than nothing
+		return err
+	}
+
+	cache.Set(cache.Device, query.cacheKey(), &PullRequestCache{
+		PullRequest: pullRequest,
+		Fetched:     time.Now().Unix(),
+	}, cache.ONEWEEK)
+
+	return nil
+}
+
+type forgeClient struct {
+	env     runtime.Environment
+	query   *PullRequestQuery
+	request *http.Request
+}
+
+type forgeUser struct {
+	Login string `json:"login"`
+}
+
+type forgeReview struct {
+	User  forgeUser `json:"user"`
+	State string    `json:"state"`
+}
+
+type forgeCommitStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+type gitHubPullRequest struct {
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Number int  `json:"number"`
+	Draft  bool `json:"draft"`
+}
+
+type gitHubCheckRuns struct {
+	CheckRuns []struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"check_runs"`
+}
+
+type gitLabMergeRequest struct {
+	HeadPipeline *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+	WebURL              string `json:"web_url"`
+	Title               string `json:"title"`
+	DetailedMergeStatus string `json:"detailed_merge_status"`
+	IID                 int    `json:"iid"`
+	Draft               bool   `json:"draft"`
+}
+
+type gitLabApprovals struct {
+	ApprovedBy []any `json:"approved_by"`
+	Approved   bool  `json:"approved"`
+}
+
+func (c *forgeClient) gitHub() (*PullRequest, error) {
+	token := c.gitHubToken()
+
+	modifier := func(request *httplib.Request) {
+		request.Header.Set("Accept", "application/vnd.github+json")
+		request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		request.Header.Set("User-Agent", "oh-my-posh")
+		if len(token) != 0 {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	owner, _, _ := strings.Cut(c.query.Repo, "/")
+	repo := c.query.API + "/repos/" + c.query.Repo
+
+	query := url2.Values{
+		"state":    {"open"},
+		"head":     {owner + ":" + c.query.Branch},
+		"per_page": {"1"},
+	}
+
+	pulls, err := http.Do[[]gitHubPullRequest](c.request, repo+"/pulls?"+query.Encode(), nil, modifier)
+	if err != nil || len(pulls) == 0 {
+		return nil, err
+	}
+
+	pull := pulls[0]
+	pullRequest := &PullRequest{
+		Number: pull.Number,
+		Title:  pull.Title,
+		URL:    pull.HTMLURL,
+		Draft:  pull.Draft,
+	}
+
+	reviews, err := http.Do[[]forgeReview](c.request, fmt.Sprintf("%s/pulls/%d/reviews?per_page=100", repo, pull.Number), nil, modifier)
+	if err == nil {
+		pullRequest.Review = reviewState(reviews)
+	}
+
+	var states []string
+
+	runs, err := http.Do[gitHubCheckRuns](c.request, fmt.Sprintf("%s/commits/%s/check-runs?per_page=100", repo, pull.Head.SHA), nil, modifier)
+	if err == nil {
+		for _, run := range runs.CheckRuns {
+			states = append(states, checkRunState(run.Status, run.Conclusion))
+		}
+	}
+
+	// commit statuses are what external CI services report
+	status, err := http.Do[forgeCommitStatus](c.request, fmt.Sprintf("%s/commits/%s/status", repo, pull.Head.SHA), nil, modifier)
+	if err == nil && status.TotalCount != 0 {
+		states = append(states, commitStatusState(status.State))
+	}
+
+	pullRequest.Checks = checksState(states)
+
+	return pullRequest, nil
+}
+
+// secure tells whether the API is served over HTTPS, a token is never sent in the clear.
+func (c *forgeClient) secure() bool {
+	api, err := url2.Parse(c.query.API)
+	return err == nil && api.Scheme == "https"
+}
+
+// envToken returns the first token set in the environment, when the query may use one.
+func (c *forgeClient) envToken(variables ...string) string {
+	if !c.query.Trusted || !c.secure() {
+		return ""
+	}
+
+	for _, variable := range variables {
+		if token := c.env.Getenv(variable); len(token) != 0 {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// gitHubToken follows the gh CLI: the environment first, then its hosts file.
+func (c *forgeClient) gitHubToken() string {
+	variables := []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	if c.query.Host != "github.com" {
+		variables = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+
+	if token := c.envToken(variables...); len(token) != 0 {
+		return token
+	}
+
+	// the hosts file keeps a token per host, it's only sent to the host it belongs to
+	if !c.secure() {
+		return ""
+	}
+
+	configDir := c.env.Getenv("GH_CONFIG_DIR")
+
+	switch {
+	case len(configDir) != 0:
+	case len(c.env.Getenv("XDG_CONFIG_HOME")) != 0:
+		configDir = filepath.Join(c.env.Getenv("XDG_CONFIG_HOME"), "gh")
+	case c.env.GOOS() == runtime.WINDOWS && len(c.env.Getenv("AppData")) != 0:
+		configDir = filepath.Join(c.env.Getenv("AppData"), "GitHub CLI")
+	default:
+		configDir = filepath.Join(c.env.Home(), ".config", "gh")
+	}
+
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+
+	// gh keeps the token in the system keyring by default, the file only has it when it can't
+	if err := yaml.Unmarshal([]byte(c.env.FileContent(filepath.Join(configDir, "hosts.yml"))), &hosts); err != nil {
+		return ""
+	}
+
+	return hosts[c.query.Host].OAuthToken
+}
+
+func (c *forgeClient) gitLab() (*PullRequest, error) {
+	token := c.envToken("GITLAB_TOKEN")
+
+	modifier := func(request *httplib.Request) {
+		if len(token) != 0 {
+			request.Header.Set("PRIVATE-TOKEN", token)
+		}
+	}
+
+	project := c.query.API + "/projects/" + url2.PathEscape(c.query.Repo)
+
+	query := url2.Values{
+		"state":         {"opened"},
+		"source_branch": {c.query.Branch},
+		"per_page":      {"1"},
+	}
+
+	requests, err := http.Do[[]gitLabMergeRequest](c.request, project+"/merge_requests?"+query.Encode(), nil, modifier)
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+
+	// the list doesn't contain the pipeline, the merge request itself does
+	mergeRequest, err := http.Do[gitLabMergeRequest](c.request, fmt.Sprintf("%s/merge_requests/%d", project, requests[0].IID), nil, modifier)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequest := &PullRequest{
+		Number: mergeRequest.IID,
+		Title:  mergeRequest.Title,
+		URL:    mergeRequest.WebURL,
+		Draft:  mergeRequest.Draft,
+		Review: ReviewPending,
+	}
+
+	if mergeRequest.HeadPipeline != nil {
+		pullRequest.Checks = gitLabPipelineState(mergeRequest.HeadPipeline.Status)
+	}
+
+	if mergeRequest.DetailedMergeStatus == "requested_changes" {
+		pullRequest.Review = ReviewChangesRequested
+		return pullRequest, nil
+	}
+
+	approvals, err := http.Do[gitLabApprovals](c.request, fmt.Sprintf("%s/merge_requests/%d/approvals", project, mergeRequest.IID), nil, modifier)
+	if err == nil && approvals.Approved && len(approvals.ApprovedBy) != 0 {
+		pullRequest.Review = ReviewApproved
+	}
+
+	return pullRequest, nil
+}
+
+func (c *forgeClient) gitea() (*PullRequest, error) {
+	token := c.envToken("GITEA_TOKEN")
+
+	modifier := func(request *httplib.Request) {
+		if len(token) != 0 {
+			request.Header.Set("Authorization", "token "+token)
+		}
+	}
+
+	repo := c.query.API + "/repos/" + c.query.Repo
+
+	// the API can't filter on the head branch
+	pulls, err := http.Do[[]gitHubPullRequest](c.request, repo+"/pulls?state=open&limit=50", nil, modifier)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, pull := range pulls {
+		if pull.Head.Ref == c.query.Branch {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		return nil, nil
+	}
+
+	pull := pulls[index]
+	pullRequest := &PullRequest{
+		Number: pull.Number,
+		Title:  pull.Title,
+		URL:    pull.HTMLURL,
+		Draft:  pull.Draft,
+	}
+
+	reviews, err := http.Do[[]forgeReview](c.request, fmt.Sprintf("%s/pulls/%d/reviews", repo, pull.Number), nil, modifier)
+	if err == nil {
+		pullRequest.Review = reviewState(reviews)
+	}
+
+	status, err := http.Do[forgeCommitStatus](c.request, fmt.Sprintf("%s/commits/%s/status", repo, pull.Head.SHA), nil, modifier)
+	if err == nil && status.TotalCount != 0 {
+		pullRequest.Checks = commitStatusState(status.State)
+	}
+
+	return pullRequest, nil
+}
+
+// reviewState keeps the latest review of every reviewer, one reviewer asking for changes wins.
+func reviewState(reviews []forgeReview) string {
+	latest := make(map[string]string)
+
+	for _, review := range reviews {
+		switch review.State {
+		case "APPROVED":
+			latest[review.User.Login] = ReviewApproved
+		case "CHANGES_REQUESTED", "REQUEST_CHANGES":
+			latest[review.User.Login] = ReviewChangesRequested
+		case "DISMISSED":
+			delete(latest, review.User.Login)
+		}
+	}
+
+	state := ReviewPending
+
+	for _, review := range latest {
+		if review == ReviewChangesRequested {
+			return ReviewChangesRequested
+		}
+
+		state = ReviewApproved
+	}
+
+	return state
+}
+
+func checkRunState(status, conclusion string) string {
+	if status != "completed" {
+		return ChecksPending
+	}
+
+	switch conclusion {
+	case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+		return ChecksFailure
+	}
+
+	return ChecksSuccess
+}
+
+func commitStatusState(state string) string {
+	switch state {
+	case "success", "warning":
+		return ChecksSuccess
+	case "failure", "error":
+		return ChecksFailure
+	case "pending":
+		return ChecksPending
+	}
+
+	return ""
+}
+
+func gitLabPipelineState(status string) string {
+	switch status {
+	case "success":
+		return ChecksSuccess
+	case "failed", "canceled":
+		return ChecksFailure
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return ChecksPending
+	}
+
+	return ""
+}
+
+// checksState combines the state of all checks, a failure wins over a pending check.
+func checksState(states []string) string {
+	var state string
+
+	for _, s := range states {
+		switch {
+		case s == ChecksFailure:
+			return ChecksFailure
+		case s == ChecksPending:
+			state = ChecksPending
+		case len(state) == 0:
+			state = s
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,419 @@
+package segments
+
+import (
+	"errors"
+	"io"
+	httplib "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/http"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+	"github.com/jandedobbeleer/oh-my-posh/src/segments/options"
+
+	"github.com/stretchr/testify/assert"
+	testify_ "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// forgeEnv sends its requests to a local stand-in of the forge API.
+type forgeEnv struct {
+	*mock.Environment
+	client *httplib.Client
+}
+
+func (env *forgeEnv) HTTPRequest(url string, body io.Reader, _ int, requestModifiers ...http.RequestModifier) ([]byte, error) {
+	request, err := httplib.NewRequest(httplib.MethodGet, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, modifier := range requestModifiers {
+		modifier(request)
+	}
+
+	response, err := env.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != httplib.StatusOK {
+		return nil, &http.Error{StatusCode: response.StatusCode}
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+func newForgeServer(t *testing.T, secure bool, header, token string, responses map[string]string) *httptest.Server {
+	handler := httplib.HandlerFunc(func(w httplib.ResponseWriter, r *httplib.Request) {
+		if r.Header.Get(header) != token {
+			w.WriteHeader(httplib.StatusUnauthorized)
+			return
+		}
+
+		response, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(httplib.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(response))
+	})
+
+	server := httptest.NewUnstartedServer(handler)
+	if secure {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRefreshPullRequest(t *testing.T) {
+	cases := []struct {
+		Responses map[string]string
+		Env       map[string]string
+		Expected  *PullRequest
+		Case      string
+		Forge     string
+		Repo      string
+		Header    string
+		Token     string
+		Error     bool
+		Untrusted bool
+		Insecure  bool
+	}{
+		{
+			Case:   "GitHub",
+			Forge:  forgeGitHub,
+			Repo:   "jandedobbeleer/oh-my-posh",
+			Env:    map[string]string{"GH_TOKEN": "gh"},
+			Header: "Authorization",
+			Token:  "Bearer gh",
+			Responses: map[string]string{
+				"/repos/jandedobbeleer/oh-my-posh/pulls?head=jandedobbeleer%3Afeat%2Fforge&per_page=1&state=open": `[{"number": 42, "title": "feat: forge", "html_url": "https://github.com/jandedobbeleer/oh-my-posh/pull/42", "head": {"sha": "abc"}}]`, //nolint:lll
+				"/repos/jandedobbeleer/oh-my-posh/pulls/42/reviews?per_page=100": `[{"user": {"login": "jan"}, "state": "CHANGES_REQUESTED"},
+					{"user": {"login": "jan"}, "state": "APPROVED"}, {"user": {"login": "bob"}, "state": "COMMENTED"}]`,
+				"/repos/jandedobbeleer/oh-my-posh/commits/abc/check-runs?per_page=100": `{"check_runs": [{"status": "completed", "conclusion": "success"},
+					{"status": "in_progress"}]}`,
+				"/repos/jandedobbeleer/oh-my-posh/commits/abc/status": `{"state": "pending", "total_count": 0}`,
+			},
+			Expected: &PullRequest{
+				Number: 42,
+				Title:  "feat: forge",
+				URL:    "https://github.com/jandedobbeleer/oh-my-posh/pull/42",
+				Review: ReviewApproved,
+				Checks: ChecksPending,
+			},
+		},
+		{
+			Case:   "GitHub token from the gh hosts file",
+			Forge:  forgeGitHub,
+			Repo:   "jandedobbeleer/oh-my-posh",
+			Header: "Authorization",
+			Token:  "Bearer hosts",
+			Responses: map[string]string{
+				"/repos/jandedobbeleer/oh-my-posh/pulls?head=jandedobbeleer%3Afeat%2Fforge&per_page=1&state=open": `[]`,
+			},
+		},
+		{
+			Case:   "GitHub without access",
+			Forge:  forgeGitHub,
+			Repo:   "jandedobbeleer/oh-my-posh",
+			Env:    map[string]string{"GITHUB_TOKEN": "expired"},
+			Header: "Authorization",
+			Token:  "Bearer gh",
+			Error:  true,
+		},
+		{
+			Case:   "GitLab",
+			Forge:  forgeGitLab,
+			Repo:   "group/sub/project",
+			Env:    map[string]string{"GITLAB_TOKEN": "gl"},
+			Header: "PRIVATE-TOKEN",
+			Token:  "gl",
+			Responses: map[string]string{
+				"/projects/group%2Fsub%2Fproject/merge_requests?per_page=1&source_branch=feat%2Fforge&state=opened": `[{"iid": 7}]`,
+				"/projects/group%2Fsub%2Fproject/merge_requests/7": `{"iid": 7, "title": "Draft: forge", "web_url": "https://gitlab.com/group/sub/project/-/merge_requests/7",
+					"draft": true, "head_pipeline": {"status": "failed"}}`,
+				"/projects/group%2Fsub%2Fproject/merge_requests/7/approvals": `{"approved": true, "approved_by": [{"user": {"username": "jan"}}]}`,
+			},
+			Expected: &PullRequest{
+				Number: 7,
+				Title:  "Draft: forge",
+				URL:    "https://gitlab.com/group/sub/project/-/merge_requests/7",
+				Draft:  true,
+				Review: ReviewApproved,
+				Checks: ChecksFailure,
+			},
+		},
+		{
+			Case:   "Gitea",
+			Forge:  forgeGitea,
+			Repo:   "jan/posh",
+			Env:    map[string]string{"GITEA_TOKEN": "tea"},
+			Header: "Authorization",
+			Token:  "token tea",
+			Responses: map[string]string{
+				"/repos/jan/posh/pulls?state=open&limit=50": `[{"number": 1, "head": {"ref": "main"}},
+					{"number": 3, "title": "forge", "html_url": "https://codeberg.org/jan/posh/pulls/3", "head": {"ref": "feat/forge", "sha": "def"}}]`,
+				"/repos/jan/posh/pulls/3/reviews":    `[{"user": {"login": "jan"}, "state": "REQUEST_CHANGES"}]`,
+				"/repos/jan/posh/commits/def/status": `{"state": "success", "total_count": 2}`,
+			},
+			Expected: &PullRequest{
+				Number: 3,
+				Title:  "forge",
+				URL:    "https://codeberg.org/jan/posh/pulls/3",
+				Review: ReviewChangesRequested,
+				Checks: ChecksSuccess,
+			},
+		},
+		{
+			Case:   "Gitea without a pull request",
+			Forge:  forgeGitea,
+			Repo:   "jan/posh",
+			Header: "Authorization",
+			Responses: map[string]string{
+				"/repos/jan/posh/pulls?state=open&limit=50": `[{"number": 1, "head": {"ref": "main"}}]`,
+			},
+		},
+		{
+			Case:      "GitLab token for an untrusted host",
+			Forge:     forgeGitLab,
+			Repo:      "group/project",
+			Env:       map[string]string{"GITLAB_TOKEN": "gl"},
+			Header:    "PRIVATE-TOKEN",
+			Untrusted: true,
+			Responses: map[string]string{
+				"/projects/group%2Fproject/merge_requests?per_page=1&source_branch=feat%2Fforge&state=opened": `[]`,
+			},
+		},
+		{
+			Case:     "Gitea token over plain HTTP",
+			Forge:    forgeGitea,
+			Repo:     "jan/posh",
+			Env:      map[string]string{"GITEA_TOKEN": "tea"},
+			Header:   "Authorization",
+			Insecure: true,
+			Responses: map[string]string{
+				"/repos/jan/posh/pulls?state=open&limit=50": `[]`,
+			},
+		},
+		{
+			Case:     "GitHub hosts file token over plain HTTP",
+			Forge:    forgeGitHub,
+			Repo:     "jandedobbeleer/oh-my-posh",
+			Header:   "Authorization",
+			Insecure: true,
+			Responses: map[string]string{
+				"/repos/jandedobbeleer/oh-my-posh/pulls?head=jandedobbeleer%3Afeat%2Fforge&per_page=1&state=open": `[]`,
+			},
+		},
+		{
+			Case:  "Unsupported forge",
+			Forge: forgeBitbucket,
+			Error: true,
+		},
+	}
+
+	for _, tc := range cases {
+		server := newForgeServer(t, !tc.Insecure, tc.Header, tc.Token, tc.Responses)
+
+		env := &mock.Environment{}
+		for key, value := range tc.Env {
+			env.On("Getenv", key).Return(value)
+		}
+		env.On("Getenv", testify_.Anything).Return("")
+		env.On("GOOS").Return(runtime.LINUX)
+		env.On("Home").Return("/home/jan")
+		env.On("FileContent", "/home/jan/.config/gh/hosts.yml").Return("github.com:\n    oauth_token: hosts\n    user: jan\n")
+
+		query := &PullRequestQuery{
+			Forge:   tc.Forge,
+			API:     server.URL,
+			Host:    "github.com",
+			Repo:    tc.Repo,
+			Branch:  "feat/forge",
+			Timeout: 1000,
+			Trusted: !tc.Untrusted,
+		}
+
+		cache.DeleteAll(cache.Device)
+
+		err := RefreshPullRequest(&forgeEnv{Environment: env, client: server.Client()}, query)
+		if tc.Error {
+			assert.Error(t, err, tc.Case)
+			_, ok := cache.Get[*PullRequestCache](cache.Device, query.cacheKey())
+			assert.False(t, ok, tc.Case)
+			continue
+		}
+
+		require.NoError(t, err, tc.Case)
+
+		data, ok := cache.Get[*PullRequestCache](cache.Device, query.cacheKey())
+		require.True(t, ok, tc.Case)
+		assert.Equal(t, tc.Expected, data.PullRequest, tc.Case)
+	}
+}
+
+func TestGitPullRequest(t *testing.T) {
+	pullRequest := &PullRequest{Number: 42}
+
+	refresh := refreshPullRequest
+	defer func() {
+		refreshPullRequest = refresh
+	}()
+
+	cases := []struct {
+		Cached          *PullRequestCache
+		Expected        *PullRequest
+		Case            string
+		Remote          string
+		ExpectedAPI     string
+		ForgeAPIs       map[string]string
+		RefreshError    error
+		Refreshing      bool
+		ExpectedRefresh bool
+		ExpectedTrusted bool
+	}{
+		{
+			Case:            "Not cached yet",
+			Remote:          "git@github.com:jandedobbeleer/oh-my-posh.git",
+			ExpectedAPI:     "https://api.github.com",
+			ExpectedTrusted: true,
+			ExpectedRefresh: true,
+		},
+		{
+			Case:            "Fresh",
+			Remote:          "git@github.com:jandedobbeleer/oh-my-posh.git",
+			ExpectedAPI:     "https://api.github.com",
+			ExpectedTrusted: true,
+			Cached:          &PullRequestCache{PullRequest: pullRequest, Fetched: time.Now().Unix()},
+			Expected:        pullRequest,
+		},
+		{
+			Case:            "Fresh without a pull request",
+			Remote:          "git@github.com:jandedobbeleer/oh-my-posh.git",
+			ExpectedAPI:     "https://api.github.com",
+			ExpectedTrusted: true,
+			Cached:          &PullRequestCache{Fetched: time.Now().Unix()},
+		},
+		{
+			Case:            "Outdated",
+			Remote:          "https://gitlab.com/group/project.git",
+			ExpectedAPI:     "https://gitlab.com/api/v4",
+			ExpectedTrusted: true,
+			Cached:          &PullRequestCache{PullRequest: pullRequest, Fetched: time.Now().Add(-time.Hour).Unix()},
+			Expected:        pullRequest,
+			ExpectedRefresh: true,
+		},
+		{
+			Case:            "Outdated while refreshing",
+			Remote:          "https://codeberg.org/jan/posh.git",
+			ExpectedAPI:     "https://codeberg.org/api/v1",
+			ExpectedTrusted: true,
+			Cached:          &PullRequestCache{PullRequest: pullRequest, Fetched: time.Now().Add(-time.Hour).Unix()},
+			Refreshing:      true,
+			Expected:        pullRequest,
+		},
+		{
+			Case:            "Refresh fails",
+			Remote:          "git@git.example.com:jan/posh.git",
+			ExpectedAPI:     "https://git.example.com/api/v3",
+			ExpectedTrusted: true,
+			RefreshError:    errors.New("no executable"),
+			ExpectedRefresh: true,
+		},
+		{
+			Case:            "Mapped API",
+			Remote:          "git@git.example.com:jan/posh.git",
+			ForgeAPIs:       map[string]string{"git.example.com": "https://api.example.com/v3/"},
+			ExpectedAPI:     "https://api.example.com/v3",
+			ExpectedTrusted: true,
+			ExpectedRefresh: true,
+		},
+		{
+			Case:            "Listed in forge_hosts",
+			Remote:          "https://git.example.com/jan/posh.git",
+			ExpectedAPI:     "https://git.example.com/api/v3",
+			ExpectedTrusted: true,
+			ExpectedRefresh: true,
+		},
+		{
+			Case:   "Forge name in another domain",
+			Remote: "https://github.com.example.org/jan/posh.git",
+		},
+		{
+			Case:   "Unsupported forge",
+			Remote: "git@bitbucket.org:jan/posh.git",
+		},
+	}
+
+	for _, tc := range cases {
+		cache.DeleteAll(cache.Device)
+
+		env := &mock.Environment{}
+		env.On("IsWsl").Return(false)
+		env.On("GOOS").Return("unix")
+		env.On("Flags").Return(&runtime.Flags{})
+
+		g := &Git{
+			Scm: Scm{
+				command: GITCOMMAND,
+			},
+			Ref:            "feat/forge",
+			RawUpstreamURL: tc.Remote,
+		}
+
+		props := options.Map{
+			ForgeHosts: map[string]string{"git.example.com": forgeGitHub},
+		}
+
+		if tc.ForgeAPIs != nil {
+			props[ForgeAPIs] = tc.ForgeAPIs
+		}
+
+		g.Init(props, env)
+
+		var refreshed *PullRequestQuery
+		refreshPullRequest = func(query *PullRequestQuery) error {
+			refreshed = query
+			return tc.RefreshError
+		}
+
+		if query := g.pullRequestQuery(); query != nil {
+			assert.Equal(t, tc.ExpectedAPI, query.API, tc.Case)
+			assert.Equal(t, tc.ExpectedTrusted, query.Trusted, tc.Case)
+
+			if tc.Cached != nil {
+				cache.Set(cache.Device, query.cacheKey(), tc.Cached, cache.ONEWEEK)
+			}
+
+			if tc.Refreshing {
+				cache.Set(cache.Device, query.cacheKey()+"_refresh", true, "1m")
+			}
+		}
+
+		assert.Equal(t, tc.Expected, g.PullRequest(), tc.Case)
+		assert.Equal(t, tc.ExpectedRefresh, refreshed != nil, tc.Case)
+
+		if refreshed == nil {
+			continue
+		}
+
+		// the refresh only starts once, also when it failed
+		refreshed = nil
+		second := &Git{Scm: Scm{command: GITCOMMAND}, Ref: "feat/forge", RawUpstreamURL: tc.Remote}
+		second.Init(props, env)
+		second.PullRequest()
+		assert.Nil(t, refreshed, tc.Case)
+	}
+}
@@ -0,0 +1,83 @@
+package segments
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
+	"github.com/jandedobbeleer/oh-my-posh/src/segments/options"
+)
+
+type RecentDirs struct {
+	Base
+
+	Dirs []*RecentDir
+}
+
+type RecentDir struct {
+	Path  string
+	Short string
+	Name  string
+	Score float64
+}
+
+const (
+	// DirsLimit is the maximum number of folders to show
+	DirsLimit options.Option = "limit"
+	// DirsSortBy sorts the folders on frecency or on the time of the last visit
+	DirsSortBy options.Option = "sort_by"
+
+	sortByFrecency = "frecency"
+	sortByRecent   = "recent"
+)
+
+func (r *RecentDirs) Template() string {
+	return ` {{ range $i, $dir := .Dirs }}{{ if $i }} {{ end }}{{ $dir.Name }}{{ end }} `
+}
+
+func (r *RecentDirs) Enabled() bool {
+	db := frecency.Parse([]byte(r.env.FileContent(frecency.File())))
+
+	now := time.Now()
+	entries := db.Frecent(0, now)
+	if r.options.String(DirsSortBy, sortByFrecency) == sortByRecent {
+		entries = db.Recent(0)
+	}
+
+	limit := r.options.Int(DirsLimit, 3)
+	pwd := r.env.Pwd()
+
+	for _, entry := range entries {
+		if len(r.Dirs) == limit {
+			break
+		}
+
+		if entry.Path == pwd {
+			continue
+		}
+
+		r.Dirs = append(r.Dirs, &RecentDir{
+			Path:  entry.Path,
+			Short: r.shorten(entry.Path),
+			Name:  filepath.Base(entry.Path),
+			Score: entry.Score(now),
+		})
+	}
+
+	return len(r.Dirs) != 0
+}
+
+func (r *RecentDirs) shorten(dir string) string {
+	home := r.env.Home()
+	if len(home) == 0 || !strings.HasPrefix(dir, home) {
+		return dir
+	}
+
+	rest := dir[len(home):]
+	if len(rest) == 0 || strings.ContainsRune(`/\`, rune(rest[0])) {
+		return "~" + rest
+	}
+
+	return dir
+}
@@ -0,0 +1,75 @@
+package segments
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+	"github.com/jandedobbeleer/oh-my-posh/src/segments/options"
+
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+)
+
+func TestRecentDirs(t *testing.T) {
+	now := time.Now()
+	db := &frecency.DB{
+		Entries: []*frecency.Entry{
+			{Path: "/home/jan/often", Rank: 10, LastAccess: now.Add(-2 * time.Hour).Unix()},
+			{Path: "/home/jan/latest", Rank: 1, LastAccess: now.Unix()},
+			{Path: "/opt/old", Rank: 5, LastAccess: now.Add(-30 * 24 * time.Hour).Unix()},
+		},
+	}
+
+	content, _ := json.Marshal(db)
+
+	cases := []struct {
+		Case            string
+		Content         string
+		Pwd             string
+		SortBy          string
+		Expected        string
+		Template        string
+		Limit           int
+		ExpectedEnabled bool
+	}{
+		{Case: "no database", ExpectedEnabled: false},
+		{Case: "frecency", Content: string(content), Expected: "often latest old", ExpectedEnabled: true},
+		{Case: "recent", Content: string(content), SortBy: "recent", Expected: "latest often old", ExpectedEnabled: true},
+		{Case: "limit", Content: string(content), Limit: 1, Expected: "often", ExpectedEnabled: true},
+		{Case: "short path", Content: string(content), Limit: 2, Template: "{{ range .Dirs }}{{ .Short }} {{ end }}", Expected: "~/often ~/latest", ExpectedEnabled: true},
+		{Case: "skip the current directory", Content: string(content), Pwd: "/home/jan/often", Limit: 1, Expected: "latest", ExpectedEnabled: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("FileContent", mock2.Anything).Return(tc.Content)
+		env.On("Pwd").Return(tc.Pwd)
+		env.On("Home").Return("/home/jan")
+
+		props := options.Map{}
+		if len(tc.SortBy) != 0 {
+			props[DirsSortBy] = tc.SortBy
+		}
+
+		if tc.Limit != 0 {
+			props[DirsLimit] = tc.Limit
+		}
+
+		dirs := &RecentDirs{}
+		dirs.Init(props, env)
+
+		assert.Equal(t, tc.ExpectedEnabled, dirs.Enabled(), tc.Case)
+		if !tc.ExpectedEnabled {
+			continue
+		}
+
+		if len(tc.Template) == 0 {
+			tc.Template = dirs.Template()
+		}
+
+		assert.Equal(t, tc.Expected, renderTemplate(env, tc.Template, dirs), tc.Case)
+	}
+}
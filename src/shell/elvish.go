@@ -13,7 +13,9 @@ func (f Features) Elvish() Code {
 		return "$_omp_executable upgrade --auto"
 	case Notice:
 		return "$_omp_executable notice"
-	case PromptMark, RPrompt, PoshGit, Azure, LineError, Jobs, CursorPositioning, Tooltips, Transient, FTCSMarks, Async, Streaming, KeyHandlers, VIMode:
+	case FTCSMarks:
+		return "_omp_enable_ftcs_marks"
+	case PromptMark, RPrompt, PoshGit, Azure, LineError, Jobs, CursorPositioning, Tooltips, Transient, Async, Streaming, KeyHandlers, VIMode:
 		fallthrough
 	default:
 		return ""
@@ -10,6 +10,7 @@ func TestElvishFeatures(t *testing.T) {
 	got := allFeatures.Lines(ELVISH).String("// these are the features")
 
 	want := `// these are the features
+_omp_enable_ftcs_marks
 $_omp_executable upgrade --auto
 $_omp_executable notice`
 
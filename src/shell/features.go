@@ -22,6 +22,10 @@ const (
 	KeyHandlers
 	VIMode
 	TransientRPrompt
+	TooltipsCommandLine
+	InfoPanel
+	TransientFinished
+	KeyBindings
 )
 
 func getAllFeatures() []Features {
@@ -32,7 +36,7 @@ func getAllFeatures() []Features {
 		feature := Features(1 << i)
 
 		// Stop when we reach a power of 2 greater than our highest defined feature
-		if feature > TransientRPrompt {
+		if feature > KeyBindings {
 			break
 		}
 
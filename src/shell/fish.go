@@ -31,6 +31,23 @@ func (f Features) Fish() Code {
 		return unixNotice
 	case VIMode:
 		return "_omp_enable_vimode"
+	case TooltipsCommandLine:
+		return "set --global _omp_tooltips_command_line 1"
+	case TransientFinished:
+		return "set --global _omp_transient_finished 1"
+	case InfoPanel:
+		// the key is a plain escape sequence, fish only expands it unquoted
+		key := infoPanelKey(FISH)
+		if len(key) == 0 {
+			return ""
+		}
+
+		return Code("_omp_enable_info_panel " + key)
+	case KeyBindings:
+		return keyBindings(FISH, func(key string, binding *KeyBinding) string {
+			return fmt.Sprintf("_omp_bind_key %s %s %s %s",
+				key, quoteFishStr(binding.Key), binding.Action, quoteFishStr(binding.Segment))
+		})
 	case RPrompt, PoshGit, Azure, LineError, Jobs, Async, KeyHandlers:
 		fallthrough
 	default:
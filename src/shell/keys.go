@@ -0,0 +1,189 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+// InfoPanelKey is the key chord that shows the info panel, set from the
+// config before generating the init script.
+var InfoPanelKey string
+
+type keyChord struct {
+	key  string
+	ctrl bool
+	alt  bool
+}
+
+// parseKeyChord parses a portable key chord: any combination of the ctrl and
+// alt modifiers followed by a single letter or digit, separated by a +.
+func parseKeyChord(chord string) (*keyChord, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(chord)), "+")
+
+	key := parts[len(parts)-1]
+	r, _ := utf8.DecodeRuneInString(key)
+	if utf8.RuneCountInString(key) != 1 || r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r)) {
+		return nil, fmt.Errorf("invalid key chord %q: the key must be a single letter or digit", chord)
+	}
+
+	result := &keyChord{key: key}
+
+	for _, modifier := range parts[:len(parts)-1] {
+		switch modifier {
+		case "ctrl":
+			result.ctrl = true
+		case "alt":
+			result.alt = true
+		default:
+			return nil, fmt.Errorf("invalid key chord %q: unknown modifier %q", chord, modifier)
+		}
+	}
+
+	if !result.ctrl && !result.alt {
+		return nil, fmt.Errorf("invalid key chord %q: at least one of ctrl or alt is required", chord)
+	}
+
+	return result, nil
+}
+
+// KeyChord translates a portable key chord, like alt+i or ctrl+x, into the
+// notation the shell's key binding command expects.
+func KeyChord(sh, chord string) (string, error) {
+	parsed, err := parseKeyChord(chord)
+	if err != nil {
+		return "", err
+	}
+
+	switch sh {
+	case ZSH:
+		key := parsed.key
+		if parsed.ctrl {
+			key = "^" + strings.ToUpper(key)
+		}
+
+		if parsed.alt {
+			key = "^[" + key
+		}
+
+		return key, nil
+	case FISH:
+		key := parsed.key
+		if parsed.ctrl {
+			key = `\c` + key
+		}
+
+		if parsed.alt {
+			key = `\e` + key
+		}
+
+		return key, nil
+	case PWSH:
+		var modifiers []string
+		if parsed.ctrl {
+			modifiers = append(modifiers, "Ctrl")
+		}
+
+		if parsed.alt {
+			modifiers = append(modifiers, "Alt")
+		}
+
+		return strings.Join(append(modifiers, parsed.key), "+"), nil
+	case NU:
+		// the modifier and keycode fields of a keybinding record
+		var modifiers []string
+		if parsed.ctrl {
+			modifiers = append(modifiers, "control")
+		}
+
+		if parsed.alt {
+			modifiers = append(modifiers, "alt")
+		}
+
+		return strings.Join(modifiers, "_") + " char_" + parsed.key, nil
+	default:
+		return "", fmt.Errorf("key chords are not supported in %s", sh)
+	}
+}
+
+// infoPanelKey returns InfoPanelKey in the shell's notation, or an empty string
+// when it can't be translated.
+func infoPanelKey(sh string) string {
+	key, err := KeyChord(sh, InfoPanelKey)
+	if err != nil {
+		log.Error(err)
+		return ""
+	}
+
+	return key
+}
+
+type KeyAction string
+
+const (
+	// TooltipAction renders the tooltips matching the command line
+	TooltipAction KeyAction = "tooltip"
+	// ToggleAction toggles a segment on or off
+	ToggleAction KeyAction = "toggle"
+	// CopyAction copies the text of a segment to the clipboard
+	CopyAction KeyAction = "copy"
+	// OpenAction opens the URL of a segment in the browser
+	OpenAction KeyAction = "open"
+)
+
+// KeyBinding binds a key chord to an action, the segment ones act on the
+// segment with that alias or type.
+type KeyBinding struct {
+	Key     string    `json:"key" toml:"key" yaml:"key"`
+	Action  KeyAction `json:"action" toml:"action" yaml:"action"`
+	Segment string    `json:"segment,omitempty" toml:"segment,omitempty" yaml:"segment,omitempty"`
+	// Template renders the text to copy or the URL to open from the
+	// segment's properties, rather than using the segment's text or .URL.
+	Template string `json:"template,omitempty" toml:"template,omitempty" yaml:"template,omitempty"`
+}
+
+// Bindings are the key bindings from the config, set before generating
+// the init script.
+var Bindings []*KeyBinding
+
+func (binding *KeyBinding) validate() error {
+	switch binding.Action {
+	case TooltipAction:
+		return nil
+	case ToggleAction, CopyAction, OpenAction:
+		if len(binding.Segment) == 0 {
+			return fmt.Errorf("key binding %s: the %s action needs a segment", binding.Key, binding.Action)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("key binding %s: unknown action %q", binding.Key, binding.Action)
+	}
+}
+
+// keyBindings translates every valid key binding into a line of code using
+// format, which gets the key in the shell's notation. Invalid bindings are
+// logged and skipped so they don't break the init script.
+func keyBindings(sh string, format func(key string, binding *KeyBinding) string) Code {
+	var lines []string
+
+	for _, binding := range Bindings {
+		if err := binding.validate(); err != nil {
+			log.Error(err)
+			continue
+		}
+
+		key, err := KeyChord(sh, binding.Key)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		lines = append(lines, format(key, binding))
+	}
+
+	return Code(strings.Join(lines, "\n"))
+}
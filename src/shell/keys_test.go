@@ -0,0 +1,79 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyChord(t *testing.T) {
+	cases := []struct {
+		Case        string
+		Shell       string
+		Chord       string
+		Expected    string
+		ShouldError bool
+	}{
+		{Case: "zsh alt", Shell: ZSH, Chord: "alt+i", Expected: "^[i"},
+		{Case: "zsh ctrl", Shell: ZSH, Chord: "ctrl+x", Expected: "^X"},
+		{Case: "zsh ctrl alt", Shell: ZSH, Chord: "Ctrl+Alt+x", Expected: "^[^X"},
+		{Case: "fish alt", Shell: FISH, Chord: "alt+i", Expected: `\ei`},
+		{Case: "fish ctrl", Shell: FISH, Chord: "ctrl+x", Expected: `\cx`},
+		{Case: "pwsh ctrl alt", Shell: PWSH, Chord: "alt+ctrl+x", Expected: "Ctrl+Alt+x"},
+		{Case: "nu alt", Shell: NU, Chord: "alt+g", Expected: "alt char_g"},
+		{Case: "nu ctrl alt", Shell: NU, Chord: "ctrl+alt+g", Expected: "control_alt char_g"},
+		{Case: "no modifier", Shell: ZSH, Chord: "i", ShouldError: true},
+		{Case: "unknown modifier", Shell: ZSH, Chord: "super+i", ShouldError: true},
+		{Case: "more than one key", Shell: ZSH, Chord: "alt+ij", ShouldError: true},
+		{Case: "not a letter or digit", Shell: FISH, Chord: "alt+'", ShouldError: true},
+		{Case: "unsupported shell", Shell: BASH, Chord: "alt+i", ShouldError: true},
+	}
+
+	for _, tc := range cases {
+		got, err := KeyChord(tc.Shell, tc.Chord)
+		if tc.ShouldError {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, got, tc.Case)
+	}
+}
+
+func TestInfoPanelFeature(t *testing.T) {
+	original := InfoPanelKey
+	t.Cleanup(func() { InfoPanelKey = original })
+
+	InfoPanelKey = "alt+i"
+
+	assert.Equal(t, Code("_omp_enable_info_panel $'^[i'"), InfoPanel.Zsh())
+	assert.Equal(t, Code(`_omp_enable_info_panel \ei`), InfoPanel.Fish())
+	assert.Equal(t, Code("Enable-PoshInfoPanel 'Alt+i'"), InfoPanel.Pwsh())
+
+	InfoPanelKey = "i"
+
+	assert.Empty(t, InfoPanel.Zsh())
+}
+
+func TestKeyBindingsFeature(t *testing.T) {
+	original := Bindings
+	t.Cleanup(func() { Bindings = original })
+
+	Bindings = []*KeyBinding{
+		{Key: "alt+t", Action: TooltipAction},
+		{Key: "alt+g", Action: CopyAction, Segment: "git"},
+		{Key: "alt+x", Action: ToggleAction},
+		{Key: "alt+o", Action: "launch", Segment: "git"},
+		{Key: "g", Action: OpenAction, Segment: "git"},
+	}
+
+	assert.Equal(t, Code("_omp_bind_key $'^[t' $'alt+t' tooltip ''\n_omp_bind_key $'^[g' $'alt+g' copy $'git'"), KeyBindings.Zsh())
+	assert.Equal(t, Code("_omp_bind_key \\et 'alt+t' tooltip ''\n_omp_bind_key \\eg 'alt+g' copy 'git'"), KeyBindings.Fish())
+	assert.Equal(t, Code("Set-PoshKeyBinding 'Alt+t' 'alt+t' tooltip ''\nSet-PoshKeyBinding 'Alt+g' 'alt+g' copy 'git'"), KeyBindings.Pwsh())
+	assert.Equal(t, Code(`_omp_bind_key alt char_t "alt+t" tooltip ''`+"\n"+`_omp_bind_key alt char_g "alt+g" copy "git"`), KeyBindings.Nu())
+
+	Bindings = nil
+
+	assert.Empty(t, KeyBindings.Zsh())
+}
@@ -17,7 +17,14 @@ func (f Features) Nu() Code {
 		return "^$_omp_executable upgrade --auto"
 	case Notice:
 		return "^$_omp_executable notice"
-	case PromptMark, RPrompt, PoshGit, Azure, LineError, Jobs, Tooltips, FTCSMarks, CursorPositioning, Async, Streaming, KeyHandlers, VIMode:
+	case FTCSMarks:
+		return "_omp_enable_ftcs_marks"
+	case KeyBindings:
+		return keyBindings(NU, func(key string, binding *KeyBinding) string {
+			return fmt.Sprintf("_omp_bind_key %s %s %s %s",
+				key, quoteNuStr(binding.Key), binding.Action, quoteNuStr(binding.Segment))
+		})
+	case PromptMark, RPrompt, PoshGit, Azure, LineError, Jobs, Tooltips, CursorPositioning, Async, Streaming, KeyHandlers, VIMode:
 		fallthrough
 	default:
 		return ""
@@ -35,6 +35,22 @@ func (f Features) Pwsh() Code {
 		return "Enable-KeyHandlers"
 	case VIMode:
 		return "Enable-PoshVIMode"
+	case TooltipsCommandLine:
+		return "$global:_ompTooltipsCommandLine = $true"
+	case TransientFinished:
+		return "$global:_ompTransientFinished = $true"
+	case InfoPanel:
+		key := infoPanelKey(PWSH)
+		if len(key) == 0 {
+			return ""
+		}
+
+		return Code("Enable-PoshInfoPanel " + quotePwshOrElvishStr(key))
+	case KeyBindings:
+		return keyBindings(PWSH, func(key string, binding *KeyBinding) string {
+			return fmt.Sprintf("Set-PoshKeyBinding %s %s %s %s",
+				quotePwshOrElvishStr(key), quotePwshOrElvishStr(binding.Key), binding.Action, quotePwshOrElvishStr(binding.Segment))
+		})
 	case PromptMark, RPrompt, CursorPositioning, Async:
 		fallthrough
 	default:
@@ -80,6 +80,31 @@ function _omp_urlencode() {
     printf '%s' "$encoded"
 }
 
+# escape $1 for VS Code's OSC 633 sequences: backslashes, semicolons and control characters
+function _omp_vscode_escape() {
+    local LC_ALL=C
+    local str=$1 escaped='' ch i
+    for ((i = 0; i < ${#str}; i++)); do
+        ch=${str:i:1}
+        case $ch in
+        '\')
+            escaped+='\\'
+            ;;
+        ';')
+            escaped+='\x3b'
+            ;;
+        [[:cntrl:]])
+            printf -v ch '\\x%02x' "'$ch"
+            escaped+=$ch
+            ;;
+        *)
+            escaped+=$ch
+            ;;
+        esac
+    done
+    printf '%s' "$escaped"
+}
+
 function _omp_ftcs_command_start() {
     if [[ $_omp_ftcs_marks != 1 ]]; then
         return
@@ -96,6 +121,11 @@ function _omp_ftcs_command_start() {
         cmd=${cmd#??}                      # strip the modified flag and separator
     fi
 
+    # report the command line to VS Code, the nonce marks the report as trusted
+    if [[ $TERM_PROGRAM == vscode ]]; then
+        printf '\e]633;E;%s%s\a' "$(_omp_vscode_escape "$cmd")" "${VSCODE_NONCE:+;$VSCODE_NONCE}"
+    fi
+
     if [[ -n $cmd ]]; then
         # advertise the command line via kitty's cmdline_url= extension
         printf '\e]133;C;cmdline_url=%s\a' "$(_omp_urlencode "$cmd")"
@@ -4,11 +4,16 @@ set --export --global POWERLINE_COMMAND oh-my-posh
 set --export --global CONDA_PROMPT_MODIFIER false
 
 set --global _omp_tooltip_command ''
+set --global _omp_tooltips_command_line 0
 set --global _omp_current_rprompt ''
 set --global _omp_transient 0
 set --global _omp_executable ::OMP::
 set --global _omp_cursor_positioning 0
 set --global _omp_ftcs_marks 0
+set --global _omp_transient_finished 0
+set --global _omp_transient_width 0
+set --global _omp_command_row ''
+set --global _omp_command_width 0
 set --global _omp_transient_prompt 0
 set --global _omp_transient_rprompt 0
 set --global _omp_prompt_mark 0
@@ -52,6 +57,13 @@ function _omp_set_cursor_position
         return
     end
 
+    set --local parts (_omp_query_cursor)
+    set --export --global POSH_CURSOR_LINE $parts[1]
+    set --export --global POSH_CURSOR_COLUMN $parts[2]
+end
+
+# Prints the cursor's line and column.
+function _omp_query_cursor
     set --local oldstty (stty -g </dev/tty)
     stty raw -echo min 1 </dev/tty
 
@@ -65,9 +77,56 @@ function _omp_set_cursor_position
 
     stty $oldstty </dev/tty
 
-    set --local parts (string match -gr '\[(\d+);(\d+)R' $pos)
-    set --export --global POSH_CURSOR_LINE $parts[1]
-    set --export --global POSH_CURSOR_COLUMN $parts[2]
+    string match -gr '\[(\d+);(\d+)R' $pos
+end
+
+# Remembers where the command accepted at the transient prompt was drawn, so
+# _omp_render_finished can find that line back once it's done.
+function _omp_track_command
+    set --global _omp_command_row ''
+
+    # only a command on a single line, after a transient prompt without a right
+    # side, leaves room to add the finished text to
+    if test (count (string split \n -- "$argv")) -ne 1; or test "$_omp_transient_rprompt" = 1
+        return
+    end
+
+    set --global _omp_command_width (math $_omp_transient_width + (string length --visible -- "$argv"))
+
+    if test $_omp_command_width -ge $COLUMNS
+        return
+    end
+
+    set --global _omp_command_row (_omp_query_cursor)[1]
+end
+
+# Rewrites the line of the command that just finished with the transient
+# prompt's finished_template, right aligned. When that line can't be found
+# back, because the output scrolled the screen or the command spans more than
+# one line, the finished text is printed below the output instead.
+function _omp_render_finished
+    set --local row $_omp_command_row
+    set --global _omp_command_row ''
+
+    set --local line (_omp_get_prompt finished | string collect)
+    if test -z "$line"
+        return
+    end
+
+    set --local width (string length --visible -- "$line")
+
+    if test -n "$row"; and test (math $_omp_command_width + $width) -lt $COLUMNS
+        set --local now (_omp_query_cursor)[1]
+
+        # the screen can only have scrolled when the cursor is on the last line
+        if test $now -ge $row; and test $now -lt $LINES
+            printf '\e7\e[%dA\e[%dG%s\e8' (math $now - $row + 1) (math $COLUMNS - $width + 1) "$line" >/dev/tty
+            return
+        end
+    end
+
+    # fish captures what fish_prompt prints, write to the terminal directly
+    printf '%s\n' "$line" >/dev/tty
 end
 
 # template function for context loading
@@ -439,6 +498,18 @@ end
 
 # NOTE: Input function calls via `commandline --function` are put into a queue and will not be executed until an outer regular function returns. See https://fishshell.com/docs/current/cmds/commandline.html.
 
+function _omp_print_transient
+    # prefer the transient prompt rendered ahead of time by the serve
+    # daemon or the streaming process, saves a CLI call
+    if test -n "$_omp_serve_tempfile"; and test -s "$_omp_serve_tempfile.transient"
+        cat "$_omp_serve_tempfile.transient"
+    else if test $_omp_enable_streaming -eq 1; and test -n "$_omp_streaming_tempfile"; and test -s "$_omp_streaming_tempfile.transient"
+        cat "$_omp_streaming_tempfile.transient"
+    else
+        _omp_get_prompt transient
+    end
+end
+
 function fish_prompt
     set --local omp_status_temp $status
     set --local omp_pipestatus_temp $pipestatus
@@ -447,15 +518,16 @@ function fish_prompt
     # see https://github.com/fish-shell/fish-shell/issues/8418
     printf \e\[0J
     if test "$_omp_transient" = 1
-        # prefer the transient prompt rendered ahead of time by the serve
-        # daemon or the streaming process, saves a CLI call
-        if test -n "$_omp_serve_tempfile"; and test -s "$_omp_serve_tempfile.transient"
-            cat "$_omp_serve_tempfile.transient"
-        else if test $_omp_enable_streaming -eq 1; and test -n "$_omp_streaming_tempfile"; and test -s "$_omp_streaming_tempfile.transient"
-            cat "$_omp_streaming_tempfile.transient"
-        else
-            _omp_get_prompt transient
+        if test "$_omp_transient_finished" = 1
+            # the width of the transient prompt's last line tells where the
+            # command ends, for _omp_track_command
+            set --local transient (_omp_print_transient | string split \n)
+            set --global _omp_transient_width (string length --visible -- "$transient[-1]")
+            string join \n -- $transient
+            return
         end
+
+        _omp_print_transient
         return
     end
     if test "$_omp_new_prompt" = 0
@@ -487,6 +559,10 @@ function fish_prompt
         set --global _omp_last_status_generation $status_generation
     end
 
+    if test "$_omp_transient_finished" = 1
+        _omp_render_finished
+    end
+
     set_poshcontext
     _omp_set_cursor_position
 
@@ -556,11 +632,35 @@ function _omp_postexec --on-event fish_postexec
     set --global _omp_last_command $argv
 end
 
+# escape the command line for VS Code's OSC 633 sequences: backslashes, semicolons and control characters
+function _omp_vscode_escape
+    set --local str (string replace --all -- '\\' '\\\\' "$argv" | string collect)
+    set str (string replace --all -- ';' '\\x3b' "$str" | string collect)
+    set str (string replace --all -- \n '\\x0a' "$str" | string collect)
+    set str (string replace --all -- \r '\\x0d' "$str" | string collect)
+    set str (string replace --all -- \t '\\x09' "$str" | string collect)
+    printf '%s' "$str"
+end
+
 function _omp_preexec --on-event fish_preexec
+    if test "$_omp_transient_finished" = 1
+        _omp_track_command $argv
+    end
+
     if test $_omp_ftcs_marks != 1
         return
     end
 
+    # report the command line to VS Code, the nonce marks the report as trusted
+    if test "$TERM_PROGRAM" = vscode
+        set --local command (_omp_vscode_escape "$argv")
+        set --local nonce
+        if set --query VSCODE_NONCE
+            set nonce ";$VSCODE_NONCE"
+        end
+        printf '\e]633;E;%s%s\a' "$command" "$nonce"
+    end
+
     if test -n "$argv"
         # advertise the command line via kitty's cmdline_url= extension
         echo -ne "\e]133;C;cmdline_url="(string escape --style=url -- "$argv")"\a"
@@ -596,11 +696,22 @@ end
 
 # tooltip
 
+# Prints the part of the command line tooltips match against: the first word,
+# or the whole trimmed line when a tooltip matches more than that.
+function _omp_tooltip_line
+    if test "$_omp_tooltips_command_line" = 1
+        commandline --current-buffer | string trim | string collect
+        return
+    end
+
+    commandline --current-buffer | string trim -l | string split --allow-empty -f1 ' ' | string collect
+end
+
 function _omp_space_key_handler
     commandline --function expand-abbr
     commandline --insert ' '
 
-    set --local tooltip_command (commandline --current-buffer | string trim -l | string split --allow-empty -f1 ' ' | string collect)
+    set --local tooltip_command (_omp_tooltip_line)
 
     if test -z "$tooltip_command" || test "$tooltip_command" = "$_omp_tooltip_command"
         return
@@ -625,7 +736,7 @@ function _omp_backspace_key_handler
         return
     end
 
-    set --local current_command (commandline --current-buffer | string trim -l | string split --allow-empty -f1 ' ' | string collect)
+    set --local current_command (_omp_tooltip_line)
 
     if test "$current_command" = "$_omp_tooltip_command"
         return
@@ -643,6 +754,58 @@ function enable_poshtooltips
     bind \x7f _omp_backspace_key_handler -M insert
 end
 
+# info panel
+
+function _omp_info_panel_key_handler
+    set --local panel (_omp_get_prompt info --command=(commandline --current-buffer | string collect) | string collect)
+
+    if test -z "$panel"
+        return
+    end
+
+    # print the panel above the prompt, the repaint redraws the prompt and buffer below it
+    echo
+    printf '%s\n' $panel
+    commandline --function repaint
+end
+
+function _omp_enable_info_panel
+    bind $argv[1] _omp_info_panel_key_handler -M default
+    bind $argv[1] _omp_info_panel_key_handler -M insert
+end
+
+# key bindings
+
+# Runs the action of a key binding from the key_bindings setting.
+function _omp_key_binding --argument-names key action segment
+    switch $action
+        case tooltip
+            set --global _omp_tooltip_command (_omp_tooltip_line)
+            set --global _omp_current_rprompt (_omp_get_prompt tooltip --command="$_omp_tooltip_command" | string join '')
+            commandline --function repaint
+        case toggle
+            $_omp_executable toggle $segment
+            omp_repaint_prompt
+        case copy
+            # an OSC 52 sequence that sets the clipboard
+            _omp_get_prompt key --key=$key | string collect >/dev/tty
+        case open
+            set --local url (_omp_get_prompt key --key=$key | string collect)
+            if test -z "$url"
+                return
+            end
+
+            # fish ships an open function that picks the platform's opener
+            open $url &>/dev/null
+    end
+end
+
+function _omp_bind_key --argument-names sequence key action segment
+    set --local handler "_omp_key_binding "(string escape -- $key $action $segment | string join ' ')
+    bind $sequence $handler -M default
+    bind $sequence $handler -M insert
+end
+
 # transient prompt
 
 function _omp_enter_key_handler
@@ -22,6 +22,8 @@ $global:_ompAzure = $false
 $global:_ompExecutable = ::OMP::
 $global:_ompTransientPrompt = $false
 $global:_ompStreaming = $false
+$global:_ompTooltipsCommandLine = $false
+$global:_ompTransientFinished = $false
 
 New-Module -Name "oh-my-posh-core" -ScriptBlock {
     $script:ConstrainedLanguageMode = $ExecutionContext.SessionState.LanguageMode -eq "ConstrainedLanguage"
@@ -247,6 +249,15 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
         }
     }
 
+    # Prints the transient prompt's finished_template for the command that just
+    # finished on its own line, below the command's output.
+    function Write-PoshFinishedLine {
+        $finished = (Get-PoshPrompt "finished") -join "`n"
+        if ($finished) {
+            Write-Host $finished
+        }
+    }
+
     function Set-PoshPromptType {
         if ($script:TransientPrompt -eq $true) {
             $script:PromptType = "transient"
@@ -917,6 +928,10 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
 
         Set-PoshContext $script:ErrorCode
 
+        if ($global:_ompTransientFinished -and $script:PromptType -eq 'primary') {
+            Write-PoshFinishedLine
+        }
+
         # set the cursor positions, they are zero based so align with other platforms
         $env:POSH_CURSOR_LINE = $Host.UI.RawUI.CursorPosition.Y + 1
         $env:POSH_CURSOR_COLUMN = $Host.UI.RawUI.CursorPosition.X + 1
@@ -1059,6 +1074,16 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
         [void](Start-PoshServe)
     }
 
+    # The part of the command line tooltips match against: the first word, or the
+    # whole trimmed line when a tooltip matches more than that.
+    function Get-PoshTooltipCommand([string]$Buffer) {
+        if ($global:_ompTooltipsCommandLine) {
+            return $Buffer.Trim()
+        }
+
+        return $Buffer.TrimStart().Split(' ', 2) | Select-Object -First 1
+    }
+
     function Enable-PoshTooltips {
         if ($script:ConstrainedLanguageMode) {
             return
@@ -1070,7 +1095,7 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
             try {
                 $command = ''
                 [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$command, [ref]$null)
-                $command = $command.TrimStart().Split(' ', 2) | Select-Object -First 1
+                $command = Get-PoshTooltipCommand $command
 
                 if (!$command -or ($command -eq $script:TooltipCommand)) {
                     return
@@ -1102,7 +1127,7 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
 
             $command = ''
             [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$command, [ref]$null)
-            $command = $command.TrimStart().Split(' ', 2) | Select-Object -First 1
+            $command = Get-PoshTooltipCommand $command
 
             if ($command -eq $script:TooltipCommand) { return }
 
@@ -1163,6 +1188,16 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
                         # Windows PowerShell's Uri.EscapeDataString throws beyond 32766 characters, hence the length cap.
                         $cmdline = ''
                         $command = $ast.Extent.Text
+                        # Report the command line to VS Code, the nonce marks the report as trusted.
+                        if ($env:TERM_PROGRAM -eq 'vscode') {
+                            $escaped = [regex]::Replace("$command", '[\\;\x00-\x1f]', {
+                                    param($match)
+                                    if ($match.Value -eq '\') { return '\\' }
+                                    '\x{0:x2}' -f [int][char]$match.Value
+                                })
+                            $nonce = if ($env:VSCODE_NONCE) { ";$env:VSCODE_NONCE" } else { '' }
+                            Write-Host "$([char]27)]633;E;$escaped$nonce$([char]7)" -NoNewline
+                        }
                         if ($command -and $command.Length -lt 32000) {
                             $cmdline = ";cmdline_url=$([Uri]::EscapeDataString($command))"
                         }
@@ -1212,6 +1247,92 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
         Set-PSReadLineOption -PromptText $validLine, $errorLine
     }
 
+    function Enable-PoshInfoPanel([string]$Chord) {
+        if ($script:ConstrainedLanguageMode) {
+            return
+        }
+
+        Set-PSReadLineKeyHandler -Chord $Chord -BriefDescription 'OhMyPoshInfoPanelKeyHandler' -ScriptBlock {
+            $command = ''
+            [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$command, [ref]$null)
+
+            $output = (Get-PoshPrompt "info" @("--command=$command")) -join "`n"
+            if (!$output) {
+                return
+            }
+
+            # Print the panel above the prompt, InvokePrompt redraws the prompt and buffer below it.
+            Write-Host ""
+            Write-Host $output
+
+            $previousOutputEncoding = [Console]::OutputEncoding
+            try {
+                [Console]::OutputEncoding = [Text.Encoding]::UTF8
+                [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+            }
+            catch [System.ArgumentOutOfRangeException] {
+            }
+            finally {
+                [Console]::OutputEncoding = $previousOutputEncoding
+            }
+        }
+    }
+
+    # Runs the action of a key binding from the key_bindings setting.
+    function Invoke-PoshKeyBinding([string]$Key, [string]$Action, [string]$Segment) {
+        switch ($Action) {
+            'tooltip' {
+                $command = ''
+                [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$command, [ref]$null)
+                $script:TooltipCommand = Get-PoshTooltipCommand $command
+
+                $output = (Get-PoshPrompt "tooltip" @(
+                        "--column=$($Host.UI.RawUI.CursorPosition.X)"
+                        "--command=$script:TooltipCommand"
+                    )) -join ''
+                if (!$output) {
+                    return
+                }
+
+                Write-Host $output -NoNewline
+
+                # Workaround to prevent the text after cursor from disappearing when the tooltip is printed.
+                [Microsoft.PowerShell.PSConsoleReadLine]::Insert(' ')
+                [Microsoft.PowerShell.PSConsoleReadLine]::Undo()
+            }
+            'toggle' {
+                Invoke-Utf8Posh @("toggle", $Segment) | Out-Null
+                Invoke-PoshPromptRepaint
+            }
+            'copy' {
+                $text = (Get-PoshPrompt "key" @("--key=$Key")) -join "`n"
+                if ($text) {
+                    Set-Clipboard -Value $text
+                }
+            }
+            'open' {
+                $url = (Get-PoshPrompt "key" @("--key=$Key")) -join ''
+                if ($url) {
+                    Start-Process $url
+                }
+            }
+        }
+    }
+
+    function Set-PoshKeyBinding([string]$Chord, [string]$Key, [string]$Action, [string]$Segment) {
+        if ($script:ConstrainedLanguageMode) {
+            return
+        }
+
+        # PSReadLine doesn't tell the handler which binding it runs for, so bake the
+        # arguments into a script block bound to this module to reach its functions.
+        $arguments = @($Key, $Action, $Segment) | ForEach-Object { "'$($_ -replace "'", "''")'" }
+        $handler = [ScriptBlock]::Create("Invoke-PoshKeyBinding $($arguments -join ' ')")
+
+        Set-PSReadLineKeyHandler -Chord $Chord -BriefDescription "OhMyPoshKeyBinding$Action" `
+            -ScriptBlock $ExecutionContext.SessionState.Module.NewBoundScriptBlock($handler)
+    }
+
     function Enable-PoshVIMode {
         if ($script:ConstrainedLanguageMode) {
             return
@@ -1406,6 +1527,8 @@ New-Module -Name "oh-my-posh-core" -ScriptBlock {
     Export-ModuleMember -Function @(
         "Set-PoshContext"
         "Enable-PoshTooltips"
+        "Enable-PoshInfoPanel"
+        "Set-PoshKeyBinding"
         "Enable-KeyHandlers"
         "Enable-PoshLineError"
         "Enable-PoshVIMode"
@@ -2,6 +2,7 @@ package shell
 
 import (
 	_ "embed"
+	"fmt"
 )
 
 //go:embed scripts/omp.zsh
@@ -25,6 +26,22 @@ func (f Features) Zsh() Code {
 		return "_omp_enable_streaming=1"
 	case VIMode:
 		return "_omp_enable_vimode"
+	case TooltipsCommandLine:
+		return "_omp_tooltips_command_line=1"
+	case TransientFinished:
+		return "_omp_transient_finished=1"
+	case InfoPanel:
+		key := infoPanelKey(ZSH)
+		if len(key) == 0 {
+			return ""
+		}
+
+		return Code("_omp_enable_info_panel " + QuotePosixStr(key))
+	case KeyBindings:
+		return keyBindings(ZSH, func(key string, binding *KeyBinding) string {
+			return fmt.Sprintf("_omp_bind_key %s %s %s %s",
+				QuotePosixStr(key), QuotePosixStr(binding.Key), binding.Action, QuotePosixStr(binding.Segment))
+		})
 	case PromptMark, RPrompt, PoshGit, Azure, LineError, Jobs, Async, KeyHandlers:
 		fallthrough
 	default:
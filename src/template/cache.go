@@ -89,6 +89,10 @@ func loadCache(vars maps.Simple[any], aliases *maps.Config) {
 		tmpl.OS = env.Platform()
 	}
 
+	// see terminal.Probe, which runs on init
+	background, _ := cache.Get[string](cache.Session, cache.TERMINALBACKGROUNDCACHE)
+	tmpl.TerminalIsLight = background == "light"
+
 	val := env.Getenv("SHLVL")
 	if shlvl, err := strconv.Atoi(val); err == nil {
 		tmpl.SHLVL = shlvl
@@ -0,0 +1,228 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+	runjobs "github.com/jandedobbeleer/oh-my-posh/src/runtime/jobs"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/path"
+)
+
+// Capabilities is the template_capabilities policy: it narrows down what the
+// functions that can access the host system (see dangerousFuncs) may do in
+// trusted templates. Without a policy everything is allowed, as before; with
+// one, anything not explicitly listed is denied.
+type Capabilities struct {
+	// Commands are the executables cmd may run and stat may look up. A bare
+	// name (git) only matches a command resolved through PATH, a path only
+	// matches that exact executable.
+	Commands []string `json:"commands,omitempty" toml:"commands,omitempty" yaml:"commands,omitempty"`
+	// Paths are the folders readFile and glob may access, including their
+	// subfolders. A relative path, like ".", is relative to the current
+	// working directory.
+	Paths []string `json:"paths,omitempty" toml:"paths,omitempty" yaml:"paths,omitempty"`
+	// Env are the environment variables env, expandenv and .Env may read,
+	// any other variable reads as unset.
+	Env []string `json:"env,omitempty" toml:"env,omitempty" yaml:"env,omitempty"`
+	// Functions are the remaining host functions (e.g. getHostByName) that are allowed.
+	Functions []string `json:"functions,omitempty" toml:"functions,omitempty" yaml:"functions,omitempty"`
+	// Timeout in milliseconds after which a single cmd call is killed, 0 means no timeout.
+	Timeout int `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// An atomic pointer as the serve daemon replaces the policy per render
+// cycle while goroutines of an abandoned cycle may still be rendering.
+var capabilities atomic.Pointer[Capabilities]
+
+// SetCapabilities enforces policy on every trusted render that follows, nil
+// removes all restrictions.
+func SetCapabilities(policy *Capabilities) {
+	capabilities.Store(policy)
+}
+
+type capabilityError struct {
+	function string
+	value    string
+}
+
+func (e *capabilityError) Error() string {
+	if e.value == "" {
+		return fmt.Sprintf("%s is not allowed by template_capabilities", e.function)
+	}
+
+	return fmt.Sprintf("%s: %s is not allowed by template_capabilities", e.function, e.value)
+}
+
+func (c *Capabilities) allowsCommand(command string) bool {
+	if c == nil {
+		return true
+	}
+
+	if !strings.ContainsAny(command, `/\`) {
+		return slices.ContainsFunc(c.Commands, func(allowed string) bool {
+			return !strings.ContainsAny(allowed, `/\`) && sameName(allowed, command)
+		})
+	}
+
+	command = filepath.Clean(command)
+
+	return slices.ContainsFunc(c.Commands, func(allowed string) bool {
+		return sameName(filepath.Clean(path.ReplaceTildePrefixWithHomeDir(allowed)), command)
+	})
+}
+
+func sameName(a, b string) bool {
+	if filepath.Separator == '\\' {
+		return strings.EqualFold(strings.TrimSuffix(a, ".exe"), strings.TrimSuffix(b, ".exe"))
+	}
+
+	return a == b
+}
+
+// resolvePath makes target absolute, relative to the working directory the
+// file functions resolve against, and follows symlinks so a link can't point
+// outside of an allowed folder.
+func resolvePath(target string) string {
+	target = path.ReplaceTildePrefixWithHomeDir(target)
+
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		return resolved
+	}
+
+	return target
+}
+
+// allowsPath returns the path to access when target is within one of the
+// allowed folders. The resolved path is returned so the check and the
+// access can't diverge.
+func (c *Capabilities) allowsPath(target string) (string, bool) {
+	if c == nil {
+		return target, true
+	}
+
+	resolved := resolvePath(target)
+
+	for _, folder := range c.Paths {
+		rel, err := filepath.Rel(resolvePath(folder), resolved)
+		if err != nil {
+			continue
+		}
+
+		if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		return resolved, true
+	}
+
+	return "", false
+}
+
+func (c *Capabilities) allowsEnv(name string) bool {
+	if c == nil {
+		return true
+	}
+
+	return slices.Contains(c.Env, name)
+}
+
+func (c *Capabilities) allowsFunction(name string) bool {
+	if c == nil {
+		return true
+	}
+
+	return slices.Contains(c.Functions, name)
+}
+
+func (c *Capabilities) timeout() time.Duration {
+	if c == nil || c.Timeout <= 0 {
+		return 0
+	}
+
+	return time.Duration(c.Timeout) * time.Millisecond
+}
+
+// getenv is the policy aware counterpart of env.Getenv, a denied variable reads as unset.
+func getenv(name string) string {
+	if !capabilities.Load().allowsEnv(name) {
+		log.Debugf("environment variable %s is not allowed by template_capabilities", name)
+		return ""
+	}
+
+	return env.Getenv(name)
+}
+
+func expandenv(value string) string {
+	return os.Expand(value, getenv)
+}
+
+// runCommand runs command through the environment, killing it once the
+// policy's timeout expires. Mirrors how the prompt engine enforces segment timeouts.
+func runCommand(timeout time.Duration, command string, args ...string) (string, error) {
+	if timeout == 0 {
+		return env.RunCommand(command, args...)
+	}
+
+	type result struct {
+		err    error
+		output string
+	}
+
+	done := make(chan result, 1)
+	gidChan := make(chan uint64, 1)
+
+	go func() {
+		gidChan <- runjobs.CurrentGID()
+		output, err := env.RunCommand(command, args...)
+		done <- result{output: output, err: err}
+	}()
+
+	gid := <-gidChan
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		return res.output, res.err
+	case <-timer.C:
+		if err := runjobs.KillGoroutineChildren(gid); err != nil {
+			log.Error(err)
+		}
+
+		return "", fmt.Errorf("cmd: %s timed out after %s", command, timeout)
+	}
+}
+
+// gateFunc wraps one of the remaining dangerous functions so it fails the
+// render when the policy doesn't allow it. text/template turns the panic
+// into a regular execution error.
+func gateFunc(name string, fn any) any {
+	value := reflect.ValueOf(fn)
+	variadic := value.Type().IsVariadic()
+
+	gated := reflect.MakeFunc(value.Type(), func(args []reflect.Value) []reflect.Value {
+		if !capabilities.Load().allowsFunction(name) {
+			panic(&capabilityError{function: name})
+		}
+
+		if variadic {
+			return value.CallSlice(args)
+		}
+
+		return value.Call(args)
+	})
+
+	return gated.Interface()
+}
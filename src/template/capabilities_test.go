@@ -0,0 +1,82 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilities(t *testing.T) {
+	original := capabilities.Load()
+	t.Cleanup(func() { capabilities.Store(original) })
+
+	pwd := t.TempDir()
+	outside := t.TempDir()
+	t.Chdir(pwd)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(pwd, "inside.txt"), []byte("inside"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "outside.txt"), []byte("outside"), 0o644))
+
+	cases := []struct {
+		Policy      *Capabilities
+		Case        string
+		Template    string
+		Expected    string
+		ShouldError bool
+	}{
+		{Case: "no policy allows cmd", Template: `{{ cmd "git" }}`, Expected: "main"},
+		{Case: "allowed command", Policy: &Capabilities{Commands: []string{"git"}}, Template: `{{ cmd "git" }}`, Expected: "main"},
+		{Case: "denied command", Policy: &Capabilities{Commands: []string{"git"}}, Template: `{{ cmd "curl" }}`, ShouldError: true},
+		{Case: "bare name does not allow a path", Policy: &Capabilities{Commands: []string{"git"}}, Template: `{{ cmd "/tmp/git" }}`, ShouldError: true},
+		{Case: "command timeout", Policy: &Capabilities{Commands: []string{"sleep"}, Timeout: 10}, Template: `{{ cmd "sleep" }}`, ShouldError: true},
+		{Case: "relative path inside pwd", Policy: &Capabilities{Paths: []string{"."}}, Template: `{{ readFile "inside.txt" }}`, Expected: "inside"},
+		{Case: "path outside of allowed folders", Policy: &Capabilities{Paths: []string{"."}}, Template: `{{ readFile "` + filepath.Join(outside, "outside.txt") + `" }}`, ShouldError: true},
+		{Case: "path escaping the allowed folder", Policy: &Capabilities{Paths: []string{"."}}, Template: `{{ readFile "../` + filepath.Base(outside) + `/outside.txt" }}`, ShouldError: true},
+		{Case: "glob skips denied matches", Policy: &Capabilities{Paths: []string{"."}}, Template: `{{ glob "` + filepath.Join(outside, "*.txt") + `" }}`, Expected: "false"},
+		{Case: "glob with allowed matches", Policy: &Capabilities{Paths: []string{"."}}, Template: `{{ glob "*.txt" }}`, Expected: "true"},
+		{Case: "allowed env", Policy: &Capabilities{Env: []string{"HELLO"}}, Template: `{{ env "HELLO" }} {{ .Env.HELLO }}`, Expected: "world world"},
+		{Case: "denied env", Policy: &Capabilities{Env: []string{"HELLO"}}, Template: `{{ env "SECRET" }}|{{ .Env.SECRET }}|{{ expandenv "$SECRET" }}`, Expected: "||"},
+		{Case: "denied function", Policy: &Capabilities{}, Template: `{{ randBytes 4 | len }}`, ShouldError: true},
+		{Case: "allowed function", Policy: &Capabilities{Functions: []string{"randBytes"}}, Template: `{{ randBytes 3 | len }}`, Expected: "4"},
+	}
+
+	for _, tc := range cases {
+		env := &mock.Environment{}
+		env.On("Shell").Return("foo")
+		env.On("Getenv", "HELLO").Return("world")
+		env.On("Getenv", "SECRET").Return("password")
+		env.On("RunCommand", "git", []string{}).Return("main", nil)
+		env.On("RunCommand", "sleep", []string{}).After(time.Second).Return("", nil)
+
+		Cache = new(cache.Template)
+		Init(env, nil, nil)
+		SetCapabilities(tc.Policy)
+
+		text, err := RenderTrusted(tc.Template, nil)
+		if tc.ShouldError {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, text, tc.Case)
+	}
+}
+
+func TestReadFileDeniedNamesPath(t *testing.T) {
+	original := capabilities.Load()
+	t.Cleanup(func() { capabilities.Store(original) })
+
+	capabilities.Store(&Capabilities{Paths: []string{t.TempDir()}})
+
+	denied := filepath.Join(t.TempDir(), "secret.txt")
+
+	_, err := readFile(denied)
+	assert.EqualError(t, err, "readFile: "+denied+" is not allowed by template_capabilities")
+}
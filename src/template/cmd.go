@@ -3,6 +3,11 @@ package template
 import "strings"
 
 func cmd(command string, args ...string) (string, error) {
-	output, err := env.RunCommand(command, args...)
+	policy := capabilities.Load()
+	if !policy.allowsCommand(command) {
+		return "", &capabilityError{function: "cmd", value: command}
+	}
+
+	output, err := runCommand(policy.timeout(), command, args...)
 	return strings.TrimSpace(output), err
 }
@@ -13,20 +13,43 @@ func glob(pattern string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return len(matches) > 0, nil
+
+	policy := capabilities.Load()
+	if policy == nil {
+		return len(matches) > 0, nil
+	}
+
+	// only report matches the policy allows, so a glob can't be used to
+	// probe for files outside of the allowed folders
+	for _, match := range matches {
+		if _, ok := policy.allowsPath(match); ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
-func readFile(path string) string {
-	content, _ := os.ReadFile(path)
-	return string(content)
+func readFile(path string) (string, error) {
+	allowed, ok := capabilities.Load().allowsPath(path)
+	if !ok {
+		return "", &capabilityError{function: "readFile", value: path}
+	}
+
+	content, _ := os.ReadFile(allowed)
+	return string(content), nil
 }
 
-func stat(path string) string {
+func stat(path string) (string, error) {
+	if !capabilities.Load().allowsCommand(path) {
+		return "", &capabilityError{function: "stat", value: path}
+	}
+
 	fullPath, err := exec.LookPath(path)
 	if err != nil {
 		log.Error(err)
-		return ""
+		return "", nil
 	}
 
-	return fullPath
+	return fullPath, nil
 }
@@ -0,0 +1,32 @@
+package template
+
+import (
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
+)
+
+// frecencyFile is a variable so tests don't touch the user's database.
+var frecencyFile = frecency.File
+
+// frecentDirs returns the n folders with the highest frecency score,
+// 0 returns all of them.
+func frecentDirs(n int) []string {
+	db := frecency.Load(frecencyFile())
+	return dirPaths(db.Frecent(n, time.Now()))
+}
+
+// recentDirs returns the n most recently visited folders, 0 returns all of them.
+func recentDirs(n int) []string {
+	db := frecency.Load(frecencyFile())
+	return dirPaths(db.Recent(n))
+}
+
+func dirPaths(entries []*frecency.Entry) []string {
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.Path)
+	}
+
+	return result
+}
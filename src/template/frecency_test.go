@@ -0,0 +1,54 @@
+package template
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/frecency"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrecencyFunctions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), frecency.FileName)
+
+	original := frecencyFile
+	t.Cleanup(func() { frecencyFile = original })
+	frecencyFile = func() string { return file }
+
+	now := time.Now()
+	db := &frecency.DB{
+		Entries: []*frecency.Entry{
+			{Path: "/often", Rank: 10, LastAccess: now.Add(-2 * time.Hour).Unix()},
+			{Path: "/latest", Rank: 1, LastAccess: now.Unix()},
+			{Path: "/old", Rank: 5, LastAccess: now.Add(-30 * 24 * time.Hour).Unix()},
+		},
+	}
+	require.NoError(t, db.Save(file))
+
+	cases := []struct {
+		Case     string
+		Template string
+		Expected string
+	}{
+		{Case: "frecent", Template: `{{ frecentDirs 2 | join "," }}`, Expected: "/often,/latest"},
+		{Case: "recent", Template: `{{ recentDirs 0 | join "," }}`, Expected: "/latest,/often,/old"},
+		{Case: "first recent", Template: `{{ index (recentDirs 1) 0 | base }}`, Expected: "latest"},
+	}
+
+	for _, tc := range cases {
+		env := &mock.Environment{}
+		env.On("Shell").Return("foo")
+
+		Cache = new(cache.Template)
+		Init(env, nil, nil)
+
+		text, err := RenderTrusted(tc.Template, nil)
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, text, tc.Case)
+	}
+}
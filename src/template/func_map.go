@@ -21,6 +21,10 @@ var dangerousFuncs = map[string]bool{
 	"env":       true,
 	"expandenv": true,
 
+	// the frecency database reveals where the user has been
+	"frecentDirs": true,
+	"recentDirs":  true,
+
 	// getHostByName does a live DNS lookup, which can be used to exfiltrate
 	// data (e.g. an env var) from an untrusted template over DNS.
 	"getHostByName": true,
@@ -95,6 +99,8 @@ func localFuncMap() map[string]any {
 		"secondsRound": secondsRound,
 		"url":          url,
 		"path":         filePath,
+		"fileURL":      fileURL,
+		"editorURL":    editorURL,
 		"matchP":       matchP,
 		"findP":        findP,
 		"replaceP":     replaceP,
@@ -142,11 +148,14 @@ var sharedFuncMap = sync.OnceValue(func() template.FuncMap {
 	fm["readFile"] = readFile
 	fm["stat"] = stat
 	fm["glob"] = glob
+	fm["env"] = getenv
+	fm["expandenv"] = expandenv
+	fm["partial"] = partial
+	fm["frecentDirs"] = gateFunc("frecentDirs", frecentDirs)
+	fm["recentDirs"] = gateFunc("recentDirs", recentDirs)
 
 	sprigFuncs := sprig.TxtFuncMap()
 	for _, name := range []string{
-		"env",
-		"expandenv",
 		"getHostByName",
 		"genPrivateKey",
 		"genCA",
@@ -164,7 +173,7 @@ var sharedFuncMap = sync.OnceValue(func() template.FuncMap {
 		"randBytes",
 	} {
 		if fn, ok := sprigFuncs[name]; ok {
-			fm[name] = fn
+			fm[name] = gateFunc(name, fn)
 		}
 	}
 
@@ -197,6 +206,14 @@ var restrictedFuncMap = sync.OnceValue(func() template.FuncMap {
 })
 
 func funcMap(trusted bool) template.FuncMap {
+	if profiling.Load() {
+		if trusted {
+			return profiledSharedFuncMap()
+		}
+
+		return profiledRestrictedFuncMap()
+	}
+
 	if trusted {
 		return sharedFuncMap()
 	}
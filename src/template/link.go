@@ -3,32 +3,148 @@ package template
 import (
 	"fmt"
 	link "net/url"
+	"path/filepath"
 	"slices"
+	"strconv"
+	"strings"
 )
 
 func url(text, url string) (string, error) {
-	unsupported := []string{elvish, xonsh}
-	if slices.Contains(unsupported, shell) {
-		return text, nil
-	}
-
-	if url == "" {
+	if !hyperlinks() || url == "" {
 		return text, nil
 	}
 	_, err := link.ParseRequestURI(url)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("<LINK>%s<TEXT>%s</TEXT></LINK>", url, text), nil
+	return Hyperlink(text, url), nil
 }
 
 func filePath(text, path string) (string, error) {
-	unsupported := []string{elvish, xonsh}
-	if slices.Contains(unsupported, shell) {
+	if !hyperlinks() {
 		return text, nil
 	}
 
 	encodedPath := (&link.URL{Path: path}).EscapedPath()
 
-	return fmt.Sprintf("<LINK>file:%s<TEXT>%s</TEXT></LINK>", encodedPath, text), nil
+	return Hyperlink(text, "file:"+encodedPath), nil
+}
+
+// hyperlinks tells whether the shell can print a hyperlink.
+func hyperlinks() bool {
+	unsupported := []string{elvish, xonsh}
+	return !slices.Contains(unsupported, shell)
+}
+
+// Hyperlink wraps text in a hyperlink to url, leaving it as is for shells that can't print one.
+// The writer drops the hyperlink again when the terminal doesn't support OSC 8.
+func Hyperlink(text, url string) string {
+	if !hyperlinks() {
+		return text
+	}
+
+	return fmt.Sprintf("<LINK>%s<TEXT>%s</TEXT></LINK>", url, text)
+}
+
+// absoluteSlashPath expands ~ and relative paths against the working directory, and returns
+// the path with forward slashes and a leading slash, as URLs expect it.
+func absoluteSlashPath(path string) string {
+	if env != nil {
+		if rest, found := strings.CutPrefix(path, "~"); found {
+			path = env.Home() + rest
+		}
+
+		if !filepath.IsAbs(path) && !strings.HasPrefix(path, "/") && !hasDriveLetter(path) {
+			path = filepath.Join(env.Pwd(), path)
+		}
+	}
+
+	path = strings.ReplaceAll(path, `\`, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return path
+}
+
+func hasDriveLetter(path string) bool {
+	return len(path) > 1 && path[1] == ':'
+}
+
+// remoteSession tells whether the shell runs over SSH, where a path only makes sense
+// together with the host it lives on.
+func remoteSession() bool {
+	if env == nil {
+		return false
+	}
+
+	return len(env.Getenv("SSH_CONNECTION")) != 0 || len(env.Getenv("SSH_CLIENT")) != 0
+}
+
+// fileURL returns the file:// URL of a path, including the hostname in a remote session.
+func fileURL(path string) string {
+	u := &link.URL{
+		Scheme: "file",
+		Path:   absoluteSlashPath(path),
+	}
+
+	if remoteSession() {
+		if host, err := env.Host(); err == nil {
+			u.Host = host
+		}
+	}
+
+	return u.String()
+}
+
+// editorURL returns a deep link that opens a path in an editor, optionally at a line and column.
+func editorURL(editor, path string, position ...int) (string, error) {
+	path = absoluteSlashPath(path)
+
+	var line, column string
+	if len(position) > 0 {
+		line = strconv.Itoa(position[0])
+	}
+
+	if len(position) > 1 {
+		column = strconv.Itoa(position[1])
+	}
+
+	switch editor {
+	case "vscode", "vscode-insiders", "vscodium", "cursor", "windsurf", "zed":
+		target := (&link.URL{Path: path}).EscapedPath()
+		if len(line) != 0 {
+			target += ":" + line
+		}
+
+		if len(column) != 0 {
+			target += ":" + column
+		}
+
+		return editor + "://file" + target, nil
+	case "idea", "pycharm", "goland", "webstorm", "rider", "phpstorm", "rubymine", "clion":
+		query := link.Values{"file": {path}}
+		if len(line) != 0 {
+			query.Set("line", line)
+		}
+
+		if len(column) != 0 {
+			query.Set("column", column)
+		}
+
+		return editor + "://open?" + query.Encode(), nil
+	case "sublime":
+		query := link.Values{"url": {"file://" + path}}
+		if len(line) != 0 {
+			query.Set("line", line)
+		}
+
+		if len(column) != 0 {
+			query.Set("column", column)
+		}
+
+		return "subl://open?" + query.Encode(), nil
+	}
+
+	return "", fmt.Errorf("unsupported editor: %s", editor)
 }
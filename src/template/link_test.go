@@ -62,3 +62,78 @@ func TestPath(t *testing.T) {
 		assert.Equal(t, tc.Expected, text, tc.Case)
 	}
 }
+
+func TestFileURL(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Path     string
+		Expected string
+		SSH      bool
+	}{
+		{Case: "Absolute path", Path: "/home/jan/my file.txt", Expected: "file:///home/jan/my%20file.txt"},
+		{Case: "Relative path", Path: "src/main.go", Expected: "file:///home/jan/dev/src/main.go"},
+		{Case: "Home", Path: "~/.config", Expected: "file:///home/jan/.config"},
+		{Case: "Windows path", Path: `C:\Users\jan`, Expected: "file:///C:/Users/jan"},
+		{Case: "Remote session", Path: "/home/jan", SSH: true, Expected: "file://devbox/home/jan"},
+	}
+
+	for _, tc := range cases {
+		sshConnection := ""
+		if tc.SSH {
+			sshConnection = "10.0.0.1 50000 10.0.0.2 22"
+		}
+
+		env := &mock.Environment{}
+		env.On("Shell").Return("foo")
+		env.On("Home").Return("/home/jan")
+		env.On("Pwd").Return("/home/jan/dev")
+		env.On("Host").Return("devbox", nil)
+		env.On("Getenv", "SSH_CONNECTION").Return(sshConnection)
+		env.On("Getenv", "SSH_CLIENT").Return("")
+
+		Cache = new(cache.Template)
+
+		Init(env, nil, nil)
+
+		text, err := RenderTrusted(`{{ fileURL .Path }}`, tc)
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, text, tc.Case)
+	}
+}
+
+func TestEditorURL(t *testing.T) {
+	cases := []struct {
+		Case        string
+		Expected    string
+		Template    string
+		ShouldError bool
+	}{
+		{Case: "VS Code", Template: `{{ editorURL "vscode" "/src/main.go" }}`, Expected: "vscode://file/src/main.go"},
+		{Case: "VS Code with line", Template: `{{ editorURL "vscode" "/src/main.go" 12 }}`, Expected: "vscode://file/src/main.go:12"},
+		{Case: "Cursor with line and column", Template: `{{ editorURL "cursor" "/my src/main.go" 12 4 }}`, Expected: "cursor://file/my%20src/main.go:12:4"},
+		{Case: "Windows path", Template: `{{ editorURL "vscode" "C:\\src\\main.go" 3 }}`, Expected: "vscode://file/C:/src/main.go:3"},
+		{Case: "JetBrains", Template: `{{ editorURL "goland" "/src/main.go" 12 }}`, Expected: "goland://open?file=%2Fsrc%2Fmain.go&line=12"},
+		{Case: "Sublime Text", Template: `{{ editorURL "sublime" "/src/main.go" 12 }}`, Expected: "subl://open?line=12&url=file%3A%2F%2F%2Fsrc%2Fmain.go"},
+		{Case: "Unknown editor", Template: `{{ editorURL "notepad" "/src/main.go" }}`, ShouldError: true},
+	}
+
+	env := &mock.Environment{}
+	env.On("Shell").Return("foo")
+	env.On("Home").Return("/home/jan")
+	env.On("Pwd").Return("/home/jan/dev")
+
+	Cache = new(cache.Template)
+
+	Init(env, nil, nil)
+
+	for _, tc := range cases {
+		text, err := RenderTrusted(tc.Template, nil)
+		if tc.ShouldError {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, text, tc.Case)
+	}
+}
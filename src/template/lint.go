@@ -0,0 +1,357 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/maps"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+
+	segmentsField = "Segments"
+	envField      = "Env"
+)
+
+// Issue is a single finding reported by Linter.Lint.
+type Issue struct {
+	Severity Severity
+	Message  string
+}
+
+// builtinFuncs are the functions text/template provides on its own, they
+// are never part of a FuncMap.
+var builtinFuncs = map[string]bool{
+	"and": true, "or": true, "not": true, "len": true, "index": true, "slice": true, "call": true,
+	"print": true, "printf": true, "println": true, "html": true, "js": true, "urlquery": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+}
+
+var (
+	globalContextType = reflect.TypeFor[context]()
+	// segmentsType is what .Segments resolves to, used to accept method
+	// calls like .Segments.Contains.
+	segmentsType = reflect.TypeFor[*maps.Concurrent[any]]()
+)
+
+// Linter statically checks template text without rendering it: the text
+// must parse, every function must exist and every .Field reference must
+// resolve against the type the template is rendered with, or against the
+// global template properties.
+type Linter struct {
+	// Segments maps a segment name, as referenced through .Segments.<Name>,
+	// to a value of the type that segment renders with.
+	Segments map[string]any
+}
+
+// Lint returns the issues found in text when rendered against a value of
+// the same type as context. A nil context only allows global properties.
+func (l *Linter) Lint(text string, context any) []Issue {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+
+	tree := parse.New("lint")
+	tree.Mode = parse.SkipFuncCheck
+	treeSet := make(map[string]*parse.Tree)
+
+	if _, err := tree.Parse(text, "", "", treeSet); err != nil {
+		return []Issue{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	walker := &lintWalker{
+		linter: l,
+		root:   reflect.TypeOf(context),
+		seen:   make(map[string]bool),
+	}
+
+	names := make([]string, 0, len(treeSet))
+	for name := range treeSet {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if root := treeSet[name].Root; root != nil {
+			walker.walk(root, scope{root: true})
+		}
+	}
+
+	return walker.issues
+}
+
+// scope describes what dot refers to: the root context, or a nested type
+// introduced by with/range. A nil typ outside of the root means the type
+// could not be determined and references against it are not checked.
+type scope struct {
+	typ  reflect.Type
+	root bool
+}
+
+type lintWalker struct {
+	linter *Linter
+	root   reflect.Type
+	seen   map[string]bool
+	issues []Issue
+}
+
+func (w *lintWalker) report(severity Severity, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	if w.seen[message] {
+		return
+	}
+
+	w.seen[message] = true
+	w.issues = append(w.issues, Issue{Severity: severity, Message: message})
+}
+
+func (w *lintWalker) walk(node parse.Node, dot scope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			w.walk(child, dot)
+		}
+	case *parse.ActionNode:
+		w.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		w.pipe(n.Pipe, dot)
+		w.walk(n.List, dot)
+		w.walk(n.ElseList, dot)
+	case *parse.WithNode:
+		inner := w.pipe(n.Pipe, dot)
+		w.walk(n.List, scope{typ: inner})
+		w.walk(n.ElseList, dot)
+	case *parse.RangeNode:
+		inner := w.pipe(n.Pipe, dot)
+		w.walk(n.List, scope{typ: elementType(inner)})
+		w.walk(n.ElseList, dot)
+	case *parse.TemplateNode:
+		w.pipe(n.Pipe, dot)
+	}
+}
+
+// pipe checks every command in the pipeline and returns the type of its
+// result when it can be determined.
+func (w *lintWalker) pipe(pipe *parse.PipeNode, dot scope) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+
+	var result reflect.Type
+	for _, cmd := range pipe.Cmds {
+		result = w.command(cmd, dot)
+	}
+
+	return result
+}
+
+func (w *lintWalker) command(cmd *parse.CommandNode, dot scope) reflect.Type {
+	var result reflect.Type
+
+	for i, arg := range cmd.Args {
+		typ := w.arg(arg, dot)
+		if i == 0 {
+			result = typ
+		}
+	}
+
+	return result
+}
+
+func (w *lintWalker) arg(node parse.Node, dot scope) reflect.Type {
+	switch n := node.(type) {
+	case *parse.IdentifierNode:
+		w.function(n.Ident)
+		return nil
+	case *parse.FieldNode:
+		return w.fields(n.Ident, dot)
+	case *parse.VariableNode:
+		// Only $ has a known type, other variables are not tracked.
+		if n.Ident[0] != "$" || len(n.Ident) == 1 {
+			return nil
+		}
+
+		return w.fields(n.Ident[1:], scope{root: true})
+	case *parse.ChainNode:
+		w.arg(n.Node, dot)
+		return nil
+	case *parse.PipeNode:
+		return w.pipe(n, dot)
+	case *parse.DotNode:
+		if dot.root {
+			return w.root
+		}
+
+		return dot.typ
+	}
+
+	return nil
+}
+
+func (w *lintWalker) function(name string) {
+	if builtinFuncs[name] {
+		return
+	}
+
+	if _, ok := funcMap(true)[name]; !ok {
+		w.report(SeverityError, "function %q is not defined", name)
+		return
+	}
+
+	if dangerousFuncs[name] {
+		w.report(SeverityWarning, "function %q can access the host system, only use it in configs you trust", name)
+	}
+}
+
+func (w *lintWalker) fields(idents []string, dot scope) reflect.Type {
+	if !dot.root {
+		return w.resolve(dot.typ, idents, 0)
+	}
+
+	first := idents[0]
+
+	switch first {
+	case envField:
+		return nil
+	case segmentsField:
+		return w.segments(idents)
+	}
+
+	if typ, ok := lookupField(w.root, first); ok && w.root != nil {
+		return w.resolve(typ, idents, 1)
+	}
+
+	if typ, ok := lookupField(globalContextType, first); ok {
+		return w.resolve(typ, idents, 1)
+	}
+
+	w.report(SeverityError, "unknown property .%s", strings.Join(idents, "."))
+	return nil
+}
+
+// segments resolves .Segments.<Name>.<Field> against the type of the named
+// segment, idents starts at Segments.
+func (w *lintWalker) segments(idents []string) reflect.Type {
+	if len(idents) == 1 {
+		return segmentsType
+	}
+
+	name := idents[1]
+
+	if _, ok := lookupField(segmentsType, name); ok {
+		return nil
+	}
+
+	context, ok := w.linter.Segments[name]
+	if !ok {
+		w.report(SeverityWarning, "segment %q referenced by .Segments.%s is not part of this config", name, name)
+		return nil
+	}
+
+	return w.resolve(reflect.TypeOf(context), idents, 2)
+}
+
+// resolve walks idents from start onwards, starting from typ, and reports
+// the first one that does not exist.
+func (w *lintWalker) resolve(typ reflect.Type, idents []string, start int) reflect.Type {
+	for i := start; i < len(idents); i++ {
+		if typ == nil {
+			return nil
+		}
+
+		next, ok := lookupField(typ, idents[i])
+		if !ok {
+			w.report(SeverityError, "unknown property .%s (%s has no field or method %s)", strings.Join(idents[:i+1], "."), typeName(typ), idents[i])
+			return nil
+		}
+
+		typ = next
+	}
+
+	return typ
+}
+
+// lookupField mirrors how text/template resolves a name: exported struct
+// fields (including promoted ones), methods on the value or its pointer,
+// and map keys. A nil type means the dynamic type is unknown, which is
+// reported as found.
+func lookupField(typ reflect.Type, name string) (reflect.Type, bool) {
+	if typ == nil {
+		return nil, true
+	}
+
+	if method, ok := typ.MethodByName(name); ok {
+		return methodResult(method), true
+	}
+
+	if typ.Kind() != reflect.Pointer && typ.Kind() != reflect.Interface {
+		if method, ok := reflect.PointerTo(typ).MethodByName(name); ok {
+			return methodResult(method), true
+		}
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		return nil, true
+	case reflect.Map:
+		return typ.Elem(), true
+	case reflect.Struct:
+		field, ok := typ.FieldByName(name)
+		if !ok || !field.IsExported() {
+			return nil, false
+		}
+
+		return field.Type, true
+	default:
+		return nil, false
+	}
+}
+
+func typeName(typ reflect.Type) string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ.String()
+}
+
+func methodResult(method reflect.Method) reflect.Type {
+	if method.Type.NumOut() == 0 {
+		return nil
+	}
+
+	return method.Type.Out(0)
+}
+
+func elementType(typ reflect.Type) reflect.Type {
+	if typ == nil {
+		return nil
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return typ.Elem()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lintStatus struct {
+	Branch  string
+	Changed bool
+}
+
+type lintContext struct {
+	Working *lintStatus
+	Items   []lintStatus
+	HEAD    string
+}
+
+func (l *lintContext) Upstream() string {
+	return ""
+}
+
+func TestLint(t *testing.T) {
+	segments := map[string]any{
+		"Git": &lintContext{},
+	}
+
+	cases := []struct {
+		Context  any
+		Case     string
+		Template string
+		Expected []Issue
+	}{
+		{Case: "plain text", Template: "hello"},
+		{Case: "field", Template: "{{ .HEAD }}", Context: &lintContext{}},
+		{Case: "method", Template: "{{ .Upstream }}", Context: &lintContext{}},
+		{Case: "nested field", Template: "{{ .Working.Branch }}", Context: &lintContext{}},
+		{Case: "global property", Template: "{{ .Shell }} {{ .PWD }}", Context: &lintContext{}},
+		{Case: "global property without context", Template: "{{ .Folder }}"},
+		{Case: "environment variable", Template: "{{ .Env.HOME }}"},
+		{Case: "config variable", Template: "{{ .Var.Anything }}"},
+		{Case: "map context", Template: "{{ .Anything }}", Context: map[string]any{}},
+		{Case: "root variable", Template: "{{ with .Working }}{{ $.HEAD }}{{ end }}", Context: &lintContext{}},
+		{Case: "with scope", Template: "{{ with .Working }}{{ .Changed }}{{ end }}", Context: &lintContext{}},
+		{Case: "range scope", Template: "{{ range .Items }}{{ .Branch }}{{ end }}", Context: &lintContext{}},
+		{Case: "cross segment", Template: "{{ .Segments.Git.Working.Changed }}"},
+		{Case: "segments method", Template: `{{ if .Segments.Contains "Git" }}yes{{ end }}`},
+		{Case: "builtin and sprig functions", Template: `{{ if and (eq .Shell "pwsh") (hasPrefix "a" "abc") }}{{ upper .Folder }}{{ end }}`},
+		{
+			Case:     "unknown field",
+			Template: "{{ .Nope }}",
+			Context:  &lintContext{},
+			Expected: []Issue{{Severity: SeverityError, Message: "unknown property .Nope"}},
+		},
+		{
+			Case:     "unknown nested field",
+			Template: "{{ .Working.Nope }}",
+			Context:  &lintContext{},
+			Expected: []Issue{{Severity: SeverityError, Message: "unknown property .Working.Nope (template.lintStatus has no field or method Nope)"}},
+		},
+		{
+			Case:     "unknown field in with scope",
+			Template: "{{ with .Working }}{{ .HEAD }}{{ end }}",
+			Context:  &lintContext{},
+			Expected: []Issue{{Severity: SeverityError, Message: "unknown property .HEAD (template.lintStatus has no field or method HEAD)"}},
+		},
+		{
+			Case:     "unknown cross segment field",
+			Template: "{{ .Segments.Git.Nope }}",
+			Expected: []Issue{{Severity: SeverityError, Message: "unknown property .Segments.Git.Nope (template.lintContext has no field or method Nope)"}},
+		},
+		{
+			Case:     "unknown segment",
+			Template: "{{ .Segments.Path.Path }}",
+			Expected: []Issue{{Severity: SeverityWarning, Message: `segment "Path" referenced by .Segments.Path is not part of this config`}},
+		},
+		{
+			Case:     "unknown function",
+			Template: "{{ nope .HEAD }}",
+			Context:  &lintContext{},
+			Expected: []Issue{{Severity: SeverityError, Message: `function "nope" is not defined`}},
+		},
+		{
+			Case:     "dangerous function",
+			Template: `{{ cmd "git" }}{{ readFile "x" }}{{ cmd "ls" }}`,
+			Expected: []Issue{
+				{Severity: SeverityWarning, Message: `function "cmd" can access the host system, only use it in configs you trust`},
+				{Severity: SeverityWarning, Message: `function "readFile" can access the host system, only use it in configs you trust`},
+			},
+		},
+		{
+			Case:     "parse error",
+			Template: "{{ .HEAD ",
+			Expected: []Issue{{Severity: SeverityError, Message: "template: lint:1: unclosed action"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Case, func(t *testing.T) {
+			linter := &Linter{Segments: segments}
+			assert.Equal(t, tc.Expected, linter.Lint(tc.Template, tc.Context))
+		})
+	}
+}
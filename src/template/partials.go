@@ -0,0 +1,259 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+// Like capabilities, replaced per render cycle by the serve daemon.
+var partials atomic.Pointer[partialSet]
+
+// partialSet holds the valid partials and, per context type, the template
+// they were patched and parsed into. A partial is patched against the same
+// context as the calling template, so a partial called with . resolves
+// properties exactly like the calling template does.
+type partialSet struct {
+	parsed  sync.Map
+	defined map[string]string
+	names   []string
+}
+
+// SetPartials makes the named templates available to every trusted template
+// that follows, either through {{ template "name" . }} or {{ partial "name" . }}.
+// An invalid partial is reported and left out, so the templates that don't
+// call it still render.
+func SetPartials(defined map[string]string) {
+	if err := setPartials(defined); err != nil {
+		log.Error(err)
+	}
+}
+
+func setPartials(defined map[string]string) error {
+	// parsed templates carry the partials they were parsed with
+	parsedTemplates.Clear()
+
+	if len(defined) == 0 {
+		partials.Store(nil)
+		return nil
+	}
+
+	set := &partialSet{defined: make(map[string]string, len(defined))}
+
+	names := make([]string, 0, len(defined))
+	for name := range defined {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var errs []error
+
+	calls := make(map[string][]string, len(names))
+
+	for _, name := range names {
+		text := &Text{template: defined[name], trusted: true}
+		text.patchTemplate()
+
+		tmpl, err := template.New(name).Funcs(funcMap(true)).Parse(text.template)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("partial %s: %w", name, err))
+			continue
+		}
+
+		calls[name] = partialCalls(tmpl.Root, nil)
+	}
+
+	// a partial calling itself, directly or through others, would recurse until the stack overflows
+	cyclic, err := partialCycles(names, calls)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, name := range names {
+		if _, ok := calls[name]; !ok || cyclic[name] {
+			continue
+		}
+
+		set.defined[name] = defined[name]
+		set.names = append(set.names, name)
+	}
+
+	partials.Store(set)
+
+	return errors.Join(errs...)
+}
+
+// partialCalls appends the names of the partials a template calls through
+// {{ template "name" }} or {{ partial "name" }}. A name that is not a string
+// literal can't be known before rendering.
+func partialCalls(node parse.Node, names []string) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return names
+		}
+
+		for _, child := range n.Nodes {
+			names = partialCalls(child, names)
+		}
+	case *parse.ActionNode:
+		names = partialCalls(n.Pipe, names)
+	case *parse.IfNode:
+		names = partialCalls(n.Pipe, names)
+		names = partialCalls(n.List, names)
+		names = partialCalls(n.ElseList, names)
+	case *parse.WithNode:
+		names = partialCalls(n.Pipe, names)
+		names = partialCalls(n.List, names)
+		names = partialCalls(n.ElseList, names)
+	case *parse.RangeNode:
+		names = partialCalls(n.Pipe, names)
+		names = partialCalls(n.List, names)
+		names = partialCalls(n.ElseList, names)
+	case *parse.TemplateNode:
+		names = append(names, n.Name)
+		names = partialCalls(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return names
+		}
+
+		for _, cmd := range n.Cmds {
+			names = partialCalls(cmd, names)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 1 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "partial" {
+				if name, ok := n.Args[1].(*parse.StringNode); ok {
+					names = append(names, name.Text)
+				}
+			}
+		}
+
+		for _, arg := range n.Args {
+			names = partialCalls(arg, names)
+		}
+	case *parse.ChainNode:
+		names = partialCalls(n.Node, names)
+	}
+
+	return names
+}
+
+// partialCycles returns the partials that call themselves, directly or
+// through other partials, and an error naming every cycle.
+func partialCycles(names []string, calls map[string][]string) (map[string]bool, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[string]int)
+	cyclic := make(map[string]bool)
+
+	var errs []error
+	var stack []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		for _, call := range calls[name] {
+			if _, ok := calls[call]; !ok {
+				continue
+			}
+
+			switch state[call] {
+			case visiting:
+				cycle := stack[slices.Index(stack, call):]
+				errs = append(errs, fmt.Errorf("partials call each other: %s", strings.Join(append(slices.Clone(cycle), call), " -> ")))
+
+				for _, member := range cycle {
+					cyclic[member] = true
+				}
+			case 0:
+				visit(call)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+
+	for _, name := range names {
+		if state[name] == 0 {
+			visit(name)
+		}
+	}
+
+	return cyclic, errors.Join(errs...)
+}
+
+// partial is only a placeholder so templates using it parse, bindPartial
+// replaces it with one bound to the template being executed.
+func partial(name string, _ any) (string, error) {
+	return "", fmt.Errorf("partial %q is not defined", name)
+}
+
+// newTrustedTemplate returns a template holding every partial, patched
+// against context. The partials are parsed once per context type, every
+// call returns a clone the caller can parse its own template into.
+func newTrustedTemplate(context Data) (*template.Template, error) {
+	set := partials.Load()
+	if set == nil {
+		return bindPartial(template.New("cache").Funcs(funcMap(true))), nil
+	}
+
+	key := templateCacheKey("", true, context)
+
+	cached, ok := set.parsed.Load(key)
+	if !ok {
+		tmpl := template.New("cache").Funcs(funcMap(true))
+
+		for _, name := range set.names {
+			text := &Text{template: set.defined[name], context: context, trusted: true}
+			text.patchTemplate()
+
+			if _, err := tmpl.New(name).Parse(text.template); err != nil {
+				return nil, fmt.Errorf("partial %s: %w", name, err)
+			}
+		}
+
+		cached, _ = set.parsed.LoadOrStore(key, tmpl)
+	}
+
+	tmpl, err := cached.(*template.Template).Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return bindPartial(tmpl), nil
+}
+
+func bindPartial(tmpl *template.Template) *template.Template {
+	return tmpl.Funcs(template.FuncMap{
+		"partial": func(name string, data any) (string, error) {
+			if tmpl.Lookup(name) == nil {
+				return "", fmt.Errorf("partial %q is not defined", name)
+			}
+
+			var buffer bytes.Buffer
+			if err := tmpl.ExecuteTemplate(&buffer, name, data); err != nil {
+				return "", err
+			}
+
+			return buffer.String(), nil
+		},
+	})
+}
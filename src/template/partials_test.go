@@ -0,0 +1,176 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartials(t *testing.T) {
+	original := partials.Load()
+	t.Cleanup(func() { partials.Store(original) })
+
+	type lang struct {
+		Full  string
+		Error string
+	}
+
+	defined := map[string]string{
+		"lang":    "{{ if .Error }}{{ .Error }}{{ else }}{{ .Full }}{{ end }}",
+		"shell":   "{{ .Shell }}",
+		"env":     "{{ .Env.HELLO }}",
+		"wrapped": `<{{ template "lang" . }}>`,
+	}
+
+	cases := []struct {
+		Context     any
+		Partials    map[string]string
+		Case        string
+		Template    string
+		Expected    string
+		Untrusted   bool
+		ShouldError bool
+	}{
+		{Case: "template action", Template: `{{ template "lang" . }}`, Expected: "1.2.3", Context: lang{Full: "1.2.3"}},
+		{Case: "partial function", Template: `{{ partial "lang" . | upper }}`, Expected: "OOPS", Context: lang{Full: "1.2.3", Error: "oops"}},
+		{Case: "global property", Template: `{{ template "shell" . }}`, Expected: "foo", Context: lang{}},
+		{Case: "environment variable", Template: `{{ template "env" . }}`, Expected: "world", Context: lang{}},
+		{Case: "nested partial", Template: `{{ partial "wrapped" . }}`, Expected: "<1.2.3>", Context: lang{Full: "1.2.3"}},
+		{Case: "local define wins", Template: `{{ define "lang" }}local{{ end }}{{ template "lang" . }}`, Expected: "local", Context: lang{}},
+		{Case: "unknown partial", Template: `{{ partial "nope" . }}`, Context: lang{}, ShouldError: true},
+		{Case: "invalid partial", Template: `{{ template "invalid" . }}`, Partials: map[string]string{"invalid": "{{ .Full "}, Context: lang{}, ShouldError: true},
+		{
+			Case:     "invalid partial leaves others",
+			Template: `{{ template "lang" . }}`,
+			Partials: map[string]string{"invalid": "{{ .Full ", "lang": defined["lang"]},
+			Expected: "1.2.3",
+			Context:  lang{Full: "1.2.3"},
+		},
+		{Case: "untrusted template", Template: `{{ template "lang" . }}`, Context: lang{}, Untrusted: true, ShouldError: true},
+		{Case: "partial calling itself", Template: `{{ partial "a" . }}`, Partials: map[string]string{"a": `{{ partial "a" . }}`}, Context: lang{}, ShouldError: true},
+		{
+			Case:        "partials calling each other",
+			Template:    `{{ partial "a" . }}`,
+			Partials:    map[string]string{"a": `{{ if .Full }}{{ partial "b" . }}{{ end }}`, "b": `{{ template "a" . }}`},
+			Context:     lang{Full: "1.2.3"},
+			ShouldError: true,
+		},
+		{
+			Case:     "cycle leaves others",
+			Template: `{{ partial "lang" . }}`,
+			Partials: map[string]string{"a": `{{ partial "a" . }}`, "lang": defined["lang"]},
+			Expected: "1.2.3",
+			Context:  lang{Full: "1.2.3"},
+		},
+	}
+
+	for _, tc := range cases {
+		env := &mock.Environment{}
+		env.On("Shell").Return("foo")
+		env.On("Getenv", "HELLO").Return("world")
+
+		Cache = &cache.Template{
+			SimpleTemplate: cache.SimpleTemplate{
+				Shell: "foo",
+			},
+		}
+		Init(env, nil, nil)
+
+		partialSet := tc.Partials
+		if partialSet == nil {
+			partialSet = defined
+		}
+
+		SetPartials(partialSet)
+
+		var text string
+		var err error
+
+		if tc.Untrusted {
+			text, err = RenderUntrusted(tc.Template, tc.Context)
+		} else {
+			text, err = RenderTrusted(tc.Template, tc.Context)
+		}
+
+		if tc.ShouldError {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Expected, text, tc.Case)
+	}
+}
+
+func TestSetPartials(t *testing.T) {
+	original := partials.Load()
+	t.Cleanup(func() { partials.Store(original) })
+
+	err := setPartials(map[string]string{"valid": "{{ .Full }}", "invalid": "{{ .Full "})
+	assert.ErrorContains(t, err, "partial invalid")
+
+	set := partials.Load()
+	assert.Equal(t, []string{"valid"}, set.names)
+
+	type lang struct{ Full string }
+
+	first, err := newTrustedTemplate(lang{})
+	assert.NoError(t, err)
+
+	second, err := newTrustedTemplate(lang{Full: "1.2.3"})
+	assert.NoError(t, err)
+
+	assert.NotSame(t, first, second, "every template gets its own clone")
+
+	parsed := 0
+	set.parsed.Range(func(_, _ any) bool {
+		parsed++
+		return true
+	})
+
+	assert.Equal(t, 1, parsed, "partials are parsed once per context type")
+}
+
+func TestSetPartialsCycles(t *testing.T) {
+	original := partials.Load()
+	t.Cleanup(func() { partials.Store(original) })
+
+	cases := []struct {
+		Partials map[string]string
+		Case     string
+		Error    string
+		Expected []string
+	}{
+		{
+			Case:     "Direct",
+			Partials: map[string]string{"a": `{{ partial "a" . }}`, "b": "b"},
+			Error:    "partials call each other: a -> a",
+			Expected: []string{"b"},
+		},
+		{
+			Case:     "Indirect",
+			Partials: map[string]string{"a": `{{ with .Full }}{{ partial "b" $ }}{{ end }}`, "b": `{{ template "a" . }}`, "c": `{{ partial "a" . }}`},
+			Error:    "partials call each other: a -> b -> a",
+			Expected: []string{"c"},
+		},
+		{
+			Case:     "Shared partial",
+			Partials: map[string]string{"a": `{{ partial "c" . }}{{ partial "b" . }}`, "b": `{{ partial "c" . }}`, "c": "c"},
+			Expected: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tc := range cases {
+		err := setPartials(tc.Partials)
+		if len(tc.Error) != 0 {
+			assert.EqualError(t, err, tc.Error, tc.Case)
+		} else {
+			assert.NoError(t, err, tc.Case)
+		}
+
+		assert.Equal(t, tc.Expected, partials.Load().names, tc.Case)
+	}
+}
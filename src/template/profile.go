@@ -0,0 +1,145 @@
+package template
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// Timing is the accumulated render time of a single template or template function.
+type Timing struct {
+	Name     string        `json:"name"`
+	Count    int           `json:"count"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Profile holds the timings recorded since EnableProfiling, slowest first.
+type Profile struct {
+	Templates []Timing `json:"templates"`
+	Functions []Timing `json:"functions"`
+}
+
+type profiler struct {
+	templates map[string]*Timing
+	functions map[string]*Timing
+	lock      sync.Mutex
+}
+
+var (
+	profiling atomic.Bool
+	profile   *profiler
+)
+
+// EnableProfiling makes every render record how long each template and
+// each function call inside it takes. It is meant for one-shot diagnostic
+// commands only: the wrapped func maps add reflection overhead to every
+// function call.
+func EnableProfiling() {
+	profile = &profiler{
+		templates: make(map[string]*Timing),
+		functions: make(map[string]*Timing),
+	}
+
+	// parsed templates are bound to the func map they were parsed with
+	parsedTemplates.Clear()
+
+	profiling.Store(true)
+}
+
+// GetProfile returns the timings recorded so far.
+func GetProfile() Profile {
+	if !profiling.Load() {
+		return Profile{}
+	}
+
+	profile.lock.Lock()
+	defer profile.lock.Unlock()
+
+	return Profile{
+		Templates: sortTimings(profile.templates),
+		Functions: sortTimings(profile.functions),
+	}
+}
+
+func sortTimings(set map[string]*Timing) []Timing {
+	timings := make([]Timing, 0, len(set))
+	for _, timing := range set {
+		timings = append(timings, *timing)
+	}
+
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Duration == timings[j].Duration {
+			return timings[i].Name < timings[j].Name
+		}
+
+		return timings[i].Duration > timings[j].Duration
+	})
+
+	return timings
+}
+
+func (p *profiler) record(set map[string]*Timing, name string, start time.Time) {
+	elapsed := time.Since(start)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	timing, ok := set[name]
+	if !ok {
+		timing = &Timing{Name: name}
+		set[name] = timing
+	}
+
+	timing.Count++
+	timing.Duration += elapsed
+}
+
+func (p *profiler) template(text string, start time.Time) {
+	p.record(p.templates, text, start)
+}
+
+func (p *profiler) function(name string, start time.Time) {
+	p.record(p.functions, name, start)
+}
+
+var (
+	profiledSharedFuncMap = sync.OnceValue(func() template.FuncMap {
+		return profileFuncMap(sharedFuncMap())
+	})
+
+	profiledRestrictedFuncMap = sync.OnceValue(func() template.FuncMap {
+		return profileFuncMap(restrictedFuncMap())
+	})
+)
+
+func profileFuncMap(fm template.FuncMap) template.FuncMap {
+	profiled := make(template.FuncMap, len(fm))
+
+	for name, fn := range fm {
+		profiled[name] = profileFunc(name, fn)
+	}
+
+	return profiled
+}
+
+// profileFunc wraps fn in a function of the exact same signature, so
+// text/template treats it identically, that records the duration of every call.
+func profileFunc(name string, fn any) any {
+	value := reflect.ValueOf(fn)
+	variadic := value.Type().IsVariadic()
+
+	wrapped := reflect.MakeFunc(value.Type(), func(args []reflect.Value) []reflect.Value {
+		defer profile.function(name, time.Now())
+
+		if variadic {
+			return value.CallSlice(args)
+		}
+
+		return value.Call(args)
+	})
+
+	return wrapped.Interface()
+}
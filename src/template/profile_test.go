@@ -0,0 +1,58 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	origProfiling := profiling.Load()
+	origProfile := profile
+	t.Cleanup(func() {
+		profiling.Store(origProfiling)
+		profile = origProfile
+		parsedTemplates.Clear()
+	})
+
+	env := &mock.Environment{}
+	env.On("Shell").Return("foo")
+	env.On("RunCommand", "echo", []string{"hello"}).Return("hello", nil)
+
+	Cache = new(cache.Template)
+	Init(env, nil, nil)
+	EnableProfiling()
+
+	templates := []string{
+		`{{ cmd "echo" "hello" }}`,
+		`{{ upper "a" }}{{ upper "b" }}`,
+		`{{ cmd "echo" "hello" }}`,
+		"plain text",
+	}
+
+	for _, tmpl := range templates {
+		_, err := RenderTrusted(tmpl, nil)
+		assert.NoError(t, err)
+	}
+
+	got := GetProfile()
+
+	counts := func(timings []Timing) map[string]int {
+		result := make(map[string]int)
+		for _, timing := range timings {
+			result[timing.Name] = timing.Count
+		}
+
+		return result
+	}
+
+	assert.Equal(t, map[string]int{`{{ cmd "echo" "hello" }}`: 2, `{{ upper "a" }}{{ upper "b" }}`: 1}, counts(got.Templates))
+	assert.Equal(t, map[string]int{"cmd": 2, "upper": 2}, counts(got.Functions))
+
+	for i := 1; i < len(got.Templates); i++ {
+		assert.GreaterOrEqual(t, got.Templates[i-1].Duration, got.Templates[i].Duration, "sorted slowest first")
+	}
+}
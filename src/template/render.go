@@ -29,7 +29,7 @@ func (c *context) init(t *Text) {
 	c.Template = *Cache
 
 	if t.trusted {
-		c.Getenv = env.Getenv
+		c.Getenv = getenv
 		return
 	}
 
@@ -106,7 +106,22 @@ func parsedTemplate(text *Text) (*template.Template, error) {
 	text.patchTemplate()
 
 	// Parse into a fresh template with the func map matching this render's trust level.
-	tmpl, err := template.New("cache").Funcs(funcMap(text.trusted)).Parse(text.template)
+	// Partials are user config, so only trusted templates can call them. They
+	// are added first so a define in the template itself takes precedence.
+	var tmpl *template.Template
+	var err error
+
+	if text.trusted {
+		tmpl, err = newTrustedTemplate(text.context)
+	} else {
+		tmpl = template.New("cache").Funcs(funcMap(false))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err = tmpl.Parse(text.template)
 	if err != nil {
 		return nil, err
 	}
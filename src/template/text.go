@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -58,6 +59,14 @@ func render(template string, trusted bool, context any) (string, error) {
 		return t.template, nil
 	}
 
+	if profiling.Load() {
+		defer profile.template(t.template, time.Now())
+	}
+
+	if log.Tracing() {
+		defer log.Span(time.Now(), "template", t.template)
+	}
+
 	renderer := renderPool.Get()
 	defer renderer.release()
 
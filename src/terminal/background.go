@@ -0,0 +1,94 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+const (
+	// backgroundQuery asks for the background color with OSC 11, see Probe.
+	backgroundQuery = "\x1b]11;?\x07"
+
+	Light = "light"
+	Dark  = "dark"
+)
+
+// storeBackground caches whether the background color in the replies is light or dark
+// for the session. A terminal that does not answer leaves the cache empty.
+func storeBackground(replies string) {
+	background, ok := parseBackground(replies)
+	if !ok {
+		log.Debug("terminal did not report its background color")
+		return
+	}
+
+	theme := Dark
+	if background.IsLight() {
+		theme = Light
+	}
+
+	log.Debugf("terminal background: %+v (%s)", background, theme)
+
+	cache.Set(cache.Session, cache.TERMINALBACKGROUNDCACHE, theme, cache.INFINITE)
+}
+
+// parseBackground parses the OSC 11 reply, like "ESC ] 11 ; rgb:ffff/ffff/ffff ST".
+func parseBackground(replies string) (color.RGB, bool) {
+	_, reply, found := strings.Cut(replies, "\x1b]11;")
+	if !found {
+		return color.RGB{}, false
+	}
+
+	value, _, ok := cutStringTerminator(reply)
+	if !ok {
+		return color.RGB{}, false
+	}
+
+	// some terminals add an alpha channel with rgba:
+	spec, found := strings.CutPrefix(value, "rgb:")
+	if !found {
+		spec, found = strings.CutPrefix(value, "rgba:")
+	}
+
+	if !found {
+		return color.RGB{}, false
+	}
+
+	channels := strings.Split(spec, "/")
+	if len(channels) < 3 {
+		return color.RGB{}, false
+	}
+
+	var values [3]uint8
+
+	for i := range values {
+		channel, ok := parseChannel(channels[i])
+		if !ok {
+			return color.RGB{}, false
+		}
+
+		values[i] = channel
+	}
+
+	return color.RGB{R: values[0], G: values[1], B: values[2]}, true
+}
+
+// parseChannel scales a color channel of 1 to 4 hex digits to 8 bits.
+func parseChannel(channel string) (uint8, bool) {
+	if len(channel) == 0 || len(channel) > 4 {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(channel, 16, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	maximum := uint64(1)<<(4*len(channel)) - 1
+
+	return uint8((value*255 + maximum/2) / maximum), true
+}
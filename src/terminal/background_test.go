@@ -0,0 +1,33 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBackground(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Replies  string
+		Expected color.RGB
+		OK       bool
+	}{
+		{Case: "16 bit channels, ST", Replies: "\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\\x1b[?62;22c", Expected: color.RGB{R: 30, G: 30, B: 46}, OK: true},
+		{Case: "8 bit channels, BEL", Replies: "\x1b]11;rgb:ef/f1/f5\a\x1b[?62;22c", Expected: color.RGB{R: 239, G: 241, B: 245}, OK: true},
+		{Case: "4 bit channels", Replies: "\x1b]11;rgb:f/0/8\a", Expected: color.RGB{R: 255, G: 0, B: 136}, OK: true},
+		{Case: "alpha channel", Replies: "\x1b]11;rgba:ffff/ffff/ffff/cccc\x1b\\", Expected: color.RGB{R: 255, G: 255, B: 255}, OK: true},
+		{Case: "no reply", Replies: "\x1b[?62;22c"},
+		{Case: "unterminated", Replies: "\x1b]11;rgb:ffff/ffff/ffff"},
+		{Case: "unknown format", Replies: "\x1b]11;#ffffff\a"},
+		{Case: "invalid channel", Replies: "\x1b]11;rgb:fffff/ffff/ffff\a"},
+	}
+
+	for _, tc := range cases {
+		got, ok := parseBackground(tc.Replies)
+		assert.Equal(t, tc.OK, ok, tc.Case)
+		assert.Equal(t, tc.Expected, got, tc.Case)
+	}
+}
@@ -8,12 +8,21 @@ func init() {
 
 type Features struct {
 	Progress []string `json:"progress,omitempty" toml:"progress,omitempty" yaml:"progress,omitempty"`
+	Probe    bool     `json:"probe,omitempty" toml:"probe,omitempty" yaml:"probe,omitempty"`
 }
 
 func (f *Features) Apply() {
+	if f == nil {
+		return
+	}
+
+	if f.Probe {
+		ApplyCapabilities()
+	}
+
 	// an empty list can't override the default: the gob-encoded session cache
 	// collapses empty slices to nil, so it can't be told apart from unset
-	if f == nil || len(f.Progress) == 0 {
+	if len(f.Progress) == 0 {
 		return
 	}
 
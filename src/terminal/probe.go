@@ -0,0 +1,384 @@
+package terminal
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/cache"
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+	"github.com/jandedobbeleer/oh-my-posh/src/log"
+)
+
+func init() {
+	gob.Register(&Capabilities{})
+}
+
+const (
+	probeTimeout = 500 * time.Millisecond
+
+	// vteModel is the DA2 terminal type VTE based terminals (GNOME Terminal, Tilix, ...)
+	// answer with, their version encoded as 100 * minor + patch, 0.50.0 being 5000.
+	vteModel = 65
+	// appleTerminalModel is the DA2 terminal type macOS Terminal answers with.
+	appleTerminalModel = 1
+	appleTerminalID    = 95
+)
+
+// probeCapabilities are the termcap names asked for with XTGETTCAP.
+var probeCapabilities = []string{"RGB", "Tc", "Smulx", "Su"}
+
+// Capabilities are the features a terminal reported when probed.
+type Capabilities struct {
+	// Name is the XTVERSION reply, like "kitty(0.35.2)".
+	Name string
+	// Attributes are the DA1 reply parameters.
+	Attributes []int
+	// Model and Version are the DA2 reply parameters.
+	Model   int
+	Version int
+
+	TrueColor       bool
+	StyledUnderline bool
+	Hyperlinks      bool
+	Progress        bool
+}
+
+type support struct {
+	trueColor       bool
+	styledUnderline bool
+	hyperlinks      bool
+	progress        bool
+}
+
+// knownTerminals maps a lowercase XTVERSION or TERM_PROGRAM name to what the terminal supports,
+// for the features that can't be queried.
+var knownTerminals = map[string]support{
+	"kitty":            {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"wezterm":          {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"foot":             {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"ghostty":          {trueColor: true, styledUnderline: true, hyperlinks: true, progress: true},
+	"iterm2":           {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"iterm.app":        {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"contour":          {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"xterm.js":         {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"vscode":           {trueColor: true, styledUnderline: true, hyperlinks: true},
+	"konsole":          {trueColor: true, hyperlinks: true},
+	"windows terminal": {trueColor: true, hyperlinks: true, progress: true},
+	"conemu":           {trueColor: true, progress: true},
+	"xterm":            {trueColor: true},
+	"apple_terminal":   {},
+}
+
+// probeQueries returns the queries sent to the terminal, for its capabilities and/or
+// its background color. DA1 comes last: every terminal answers it, so its reply tells
+// all other replies arrived.
+func probeQueries(capabilities, background bool) string {
+	var queries strings.Builder
+
+	if capabilities {
+		// XTVERSION
+		queries.WriteString("\x1b[>0q")
+
+		// XTGETTCAP
+		for _, capability := range probeCapabilities {
+			queries.WriteString("\x1bP+q")
+			queries.WriteString(hex.EncodeToString([]byte(capability)))
+			queries.WriteString("\x1b\\")
+		}
+	}
+
+	if background {
+		queries.WriteString(backgroundQuery)
+	}
+
+	if capabilities {
+		// DA2
+		queries.WriteString("\x1b[>c")
+	}
+
+	// DA1
+	queries.WriteString("\x1b[c")
+
+	return queries.String()
+}
+
+// probeComplete tells if the replies contain the DA1 reply.
+func probeComplete(replies string) bool {
+	for {
+		index := strings.Index(replies, "\x1b[?")
+		if index == -1 {
+			return false
+		}
+
+		replies = replies[index+3:]
+		end := strings.IndexFunc(replies, func(r rune) bool {
+			return (r < '0' || r > '9') && r != ';'
+		})
+
+		if end != -1 && replies[end] == 'c' {
+			return true
+		}
+	}
+}
+
+// parseReplies turns the replies to probeQueries into the capabilities of the terminal.
+func parseReplies(replies string) *Capabilities {
+	caps := &Capabilities{}
+	termcaps := make(map[string]bool)
+
+	for {
+		index := strings.IndexByte(replies, '\x1b')
+		if index == -1 || index+1 >= len(replies) {
+			break
+		}
+
+		replies = replies[index:]
+
+		switch replies[1] {
+		case 'P':
+			body, rest, ok := cutStringTerminator(replies[2:])
+			if !ok {
+				replies = ""
+				continue
+			}
+
+			caps.parseDCS(body, termcaps)
+			replies = rest
+		case '[':
+			end := strings.IndexFunc(replies[2:], func(r rune) bool {
+				return r >= 0x40 && r <= 0x7e
+			})
+			if end == -1 {
+				replies = ""
+				continue
+			}
+
+			caps.parseCSI(replies[2:2+end], replies[2+end])
+			replies = replies[3+end:]
+		default:
+			replies = replies[1:]
+		}
+	}
+
+	caps.resolve(termcaps)
+
+	return caps
+}
+
+// cutStringTerminator splits a DCS body from what follows its ST or BEL terminator.
+func cutStringTerminator(s string) (body, rest string, ok bool) {
+	st := strings.Index(s, "\x1b\\")
+	bel := strings.IndexByte(s, '\a')
+
+	switch {
+	case st == -1 && bel == -1:
+		return "", "", false
+	case st == -1 || (bel != -1 && bel < st):
+		return s[:bel], s[bel+1:], true
+	default:
+		return s[:st], s[st+2:], true
+	}
+}
+
+func (c *Capabilities) parseDCS(body string, termcaps map[string]bool) {
+	// XTVERSION: DCS > | name ST
+	if name, ok := strings.CutPrefix(body, ">|"); ok {
+		c.Name = name
+		return
+	}
+
+	// XTGETTCAP: DCS 1 + r name[=value] ST, or DCS 0 + r [name] ST when unknown
+	valid, ok := strings.CutPrefix(body, "1+r")
+	supported := ok
+	if !ok {
+		valid, ok = strings.CutPrefix(body, "0+r")
+		if !ok {
+			return
+		}
+	}
+
+	for entry := range strings.SplitSeq(valid, ";") {
+		encoded, _, _ := strings.Cut(entry, "=")
+		name, err := hex.DecodeString(encoded)
+		if err != nil || len(name) == 0 {
+			continue
+		}
+
+		termcaps[string(name)] = supported
+	}
+}
+
+func (c *Capabilities) parseCSI(params string, final byte) {
+	if final != 'c' {
+		return
+	}
+
+	if attributes, ok := strings.CutPrefix(params, "?"); ok {
+		c.Attributes = parseParams(attributes)
+		return
+	}
+
+	if secondary, ok := strings.CutPrefix(params, ">"); ok {
+		values := parseParams(secondary)
+		if len(values) >= 2 {
+			c.Model, c.Version = values[0], values[1]
+		}
+	}
+}
+
+func parseParams(params string) []int {
+	var values []int
+
+	for param := range strings.SplitSeq(params, ";") {
+		value, err := strconv.Atoi(param)
+		if err != nil {
+			continue
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// resolve combines the termcap replies with what is known about the identified terminal.
+func (c *Capabilities) resolve(termcaps map[string]bool) {
+	known, identified := c.known()
+
+	// an unidentified terminal, like one behind an SSH jump host, only gets
+	// true color when it says so through COLORTERM or a termcap reply
+	c.TrueColor = known.trueColor
+	if !identified {
+		colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+		c.TrueColor = colorTerm == "truecolor" || colorTerm == "24bit"
+	}
+	c.StyledUnderline = known.styledUnderline
+	c.Hyperlinks = known.hyperlinks
+	c.Progress = known.progress
+
+	// a termcap reply, when there is one, overrules what the terminal is known for
+	rgb, hasRGB := termcaps["RGB"]
+	tc, hasTc := termcaps["Tc"]
+	if hasRGB || hasTc {
+		c.TrueColor = rgb || tc
+	}
+
+	if smulx, ok := termcaps["Smulx"]; ok {
+		c.StyledUnderline = smulx
+	}
+
+	if su, ok := termcaps["Su"]; ok && su {
+		c.StyledUnderline = true
+	}
+}
+
+// known returns what the terminal supports, based on its XTVERSION name, the DA2 reply or TERM_PROGRAM.
+func (c *Capabilities) known() (support, bool) {
+	if len(c.Name) != 0 {
+		name, _, _ := strings.Cut(strings.ToLower(c.Name), "(")
+		name, _, _ = strings.Cut(name, " ")
+
+		if known, ok := knownTerminals[name]; ok {
+			return known, true
+		}
+	}
+
+	switch {
+	case c.Model == vteModel && c.Version >= 5000:
+		return support{trueColor: true, styledUnderline: true, hyperlinks: true}, true
+	case c.Model == vteModel:
+		return support{trueColor: true}, true
+	case c.Model == appleTerminalModel && c.Version == appleTerminalID:
+		return knownTerminals["apple_terminal"], true
+	}
+
+	if known, ok := knownTerminals[strings.ToLower(Program)]; ok {
+		return known, true
+	}
+
+	return support{}, false
+}
+
+// apply enables or disables the features depending on the capabilities,
+// on top of the defaults set by Init.
+func (c *Capabilities) apply() {
+	color.TrueColor = c.TrueColor
+	noHyperlinks = !c.Hyperlinks
+	progress = c.Progress
+}
+
+// capabilitiesKey identifies the terminal the capabilities were probed for. Over SSH,
+// the client address is part of it, as the same host can be used from different terminals.
+func capabilitiesKey() string {
+	parts := []string{
+		os.Getenv("TERM_PROGRAM"),
+		os.Getenv("TERM_PROGRAM_VERSION"),
+		os.Getenv("LC_TERMINAL"),
+		os.Getenv("TERM"),
+	}
+
+	if client, _, found := strings.Cut(os.Getenv("SSH_CLIENT"), " "); found {
+		parts = append(parts, client)
+	}
+
+	return "terminal_capabilities_" + strings.Join(parts, "|")
+}
+
+// Probe queries the terminal for its capabilities and/or its background color, in a
+// single round trip, unless they are cached for this terminal already.
+func Probe(capabilities, background bool) {
+	if len(Program) == 0 {
+		Program = getTerminalName()
+	}
+
+	key := capabilitiesKey()
+	if _, OK := cache.Get[*Capabilities](cache.Device, key); OK {
+		capabilities = false
+	}
+
+	if _, OK := cache.Get[string](cache.Session, cache.TERMINALBACKGROUNDCACHE); OK {
+		background = false
+	}
+
+	if !capabilities && !background {
+		return
+	}
+
+	replies, err := queryTerminal(probeQueries(capabilities, background), probeTimeout)
+	if err != nil {
+		log.Debug("unable to probe the terminal:", err.Error())
+		return
+	}
+
+	if background {
+		storeBackground(replies)
+	}
+
+	if !capabilities {
+		return
+	}
+
+	if !probeComplete(replies) {
+		log.Debug("terminal did not answer the probe in time")
+		return
+	}
+
+	caps := parseReplies(replies)
+	log.Debugf("terminal capabilities: %+v", *caps)
+
+	cache.Set(cache.Device, key, caps, cache.ONEWEEK)
+}
+
+// ApplyCapabilities applies the cached capabilities of the terminal, when it was probed.
+func ApplyCapabilities() {
+	caps, OK := cache.Get[*Capabilities](cache.Device, capabilitiesKey())
+	if !OK || caps == nil {
+		return
+	}
+
+	caps.apply()
+}
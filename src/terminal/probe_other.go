@@ -0,0 +1,14 @@
+//go:build windows || js
+
+package terminal
+
+import (
+	"errors"
+	"time"
+)
+
+// queryTerminal is not supported here: the Windows console input can't be read
+// without echo from a child process of the shell, and js/wasm has no terminal.
+func queryTerminal(_ string, _ time.Duration) (string, error) {
+	return "", errors.New("terminal probing is not supported on this platform")
+}
@@ -0,0 +1,187 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/color"
+	"github.com/jandedobbeleer/oh-my-posh/src/shell"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeQueries(t *testing.T) {
+	capabilities := "\x1b[>0q\x1bP+q524742\x1b\\\x1bP+q5463\x1b\\\x1bP+q536d756c78\x1b\\\x1bP+q5375\x1b\\"
+
+	cases := []struct {
+		Case         string
+		Expected     string
+		Capabilities bool
+		Background   bool
+	}{
+		{Case: "capabilities", Capabilities: true, Expected: capabilities + "\x1b[>c\x1b[c"},
+		{Case: "background", Background: true, Expected: "\x1b]11;?\a\x1b[c"},
+		{Case: "both", Capabilities: true, Background: true, Expected: capabilities + "\x1b]11;?\a\x1b[>c\x1b[c"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, probeQueries(tc.Capabilities, tc.Background), tc.Case)
+	}
+}
+
+func TestProbeComplete(t *testing.T) {
+	cases := []struct {
+		Case     string
+		Replies  string
+		Expected bool
+	}{
+		{Case: "No replies"},
+		{Case: "DA2 only", Replies: "\x1b[>1;4000;29c"},
+		{Case: "Partial DA1", Replies: "\x1b[>1;4000;29c\x1b[?62;22"},
+		{Case: "DA1", Replies: "\x1bP>|kitty(0.35.2)\x1b\\\x1b[>1;4000;29c\x1b[?62;22c", Expected: true},
+		{Case: "DA1 without parameters", Replies: "\x1b[?c", Expected: true},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Expected, probeComplete(tc.Replies), tc.Case)
+	}
+}
+
+func TestParseReplies(t *testing.T) {
+	cases := []struct {
+		Case      string
+		Replies   string
+		Program   string
+		ColorTerm string
+		Expected  Capabilities
+	}{
+		{
+			Case: "kitty",
+			Replies: "\x1bP>|kitty(0.35.2)\x1b\\\x1bP1+r524742=382f382f38\x1b\\\x1bP1+r5463\x1b\\" +
+				"\x1bP1+r536d756c78=5c455b342573703125646d\x1b\\\x1bP0+r5375\x1b\\\x1b[>1;4000;29c\x1b[?62;c",
+			Expected: Capabilities{
+				Name:            "kitty(0.35.2)",
+				Attributes:      []int{62},
+				Model:           1,
+				Version:         4000,
+				TrueColor:       true,
+				StyledUnderline: true,
+				Hyperlinks:      true,
+			},
+		},
+		{
+			Case:    "Ghostty, BEL terminated",
+			Replies: "\x1bP>|ghostty 1.2.0\a\x1b[?62;22c",
+			Expected: Capabilities{
+				Name:            "ghostty 1.2.0",
+				Attributes:      []int{62, 22},
+				TrueColor:       true,
+				StyledUnderline: true,
+				Hyperlinks:      true,
+				Progress:        true,
+			},
+		},
+		{
+			Case:    "XTerm without direct color",
+			Replies: "\x1bP>|XTerm(390)\x1b\\\x1bP0+r524742\x1b\\\x1bP0+r5463\x1b\\\x1b[>41;390;0c\x1b[?64;1;2;6;9;15;16;17;18;21;22;28c",
+			Expected: Capabilities{
+				Name:       "XTerm(390)",
+				Attributes: []int{64, 1, 2, 6, 9, 15, 16, 17, 18, 21, 22, 28},
+				Model:      41,
+				Version:    390,
+			},
+		},
+		{
+			Case:    "VTE",
+			Replies: "\x1b[>65;7600;1c\x1b[?65;1;9c",
+			Expected: Capabilities{
+				Attributes:      []int{65, 1, 9},
+				Model:           65,
+				Version:         7600,
+				TrueColor:       true,
+				StyledUnderline: true,
+				Hyperlinks:      true,
+			},
+		},
+		{
+			Case:    "Apple Terminal",
+			Replies: "\x1b[>1;95;0c\x1b[?1;2c",
+			Expected: Capabilities{
+				Attributes: []int{1, 2},
+				Model:      1,
+				Version:    95,
+			},
+		},
+		{
+			Case:    "Windows Terminal, by TERM_PROGRAM",
+			Replies: "\x1b[?61;6;7;14;21;22;23;24;28;32;42c",
+			Program: WindowsTerminal,
+			Expected: Capabilities{
+				Attributes: []int{61, 6, 7, 14, 21, 22, 23, 24, 28, 32, 42},
+				TrueColor:  true,
+				Hyperlinks: true,
+				Progress:   true,
+			},
+		},
+		{
+			Case:    "Unknown terminal over SSH",
+			Replies: "\x1b[?1;2c",
+			Program: Unknown,
+			Expected: Capabilities{
+				Attributes: []int{1, 2},
+			},
+		},
+		{
+			Case:      "Unknown terminal with COLORTERM",
+			Replies:   "\x1b[?1;2c",
+			Program:   Unknown,
+			ColorTerm: "truecolor",
+			Expected: Capabilities{
+				Attributes: []int{1, 2},
+				TrueColor:  true,
+			},
+		},
+		{
+			Case:    "Unknown terminal with a termcap reply",
+			Replies: "\x1bP1+r524742\x1b\\\x1b[?1;2c",
+			Program: Unknown,
+			Expected: Capabilities{
+				Attributes: []int{1, 2},
+				TrueColor:  true,
+			},
+		},
+		{
+			Case:    "Garbage around the replies",
+			Replies: "ls\x1b\x1b[>0;10;1c\x1bP>|tmux 3.4",
+			Program: Unknown,
+			Expected: Capabilities{
+				Version: 10,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Setenv("COLORTERM", tc.ColorTerm)
+		Program = tc.Program
+		got := parseReplies(tc.Replies)
+		assert.Equal(t, tc.Expected, *got, tc.Case)
+	}
+}
+
+func TestCapabilitiesApply(t *testing.T) {
+	defer Init(shell.GENERIC)
+
+	Init(shell.PWSH)
+	Colors = &color.Defaults{}
+
+	caps := &Capabilities{TrueColor: false, Hyperlinks: false, Progress: true}
+	caps.apply()
+
+	assert.False(t, color.TrueColor)
+	assert.Equal(t, startProgress, StartProgress())
+
+	Write("white", "black", "<LINK>https://ohmyposh.dev<TEXT>docs</TEXT></LINK>")
+	got, length := String()
+
+	assert.Equal(t, "\x1b[47m\x1b[30mdocs\x1b[0m", got)
+	assert.Equal(t, 4, length)
+}
@@ -0,0 +1,73 @@
+//go:build !windows && !js
+
+package terminal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// queryTerminal writes the queries to the controlling terminal and collects the replies
+// until the DA1 reply arrives, or the timeout passes. The terminal is put in raw mode
+// meanwhile, so the replies are neither echoed nor line buffered.
+func queryTerminal(queries string, timeout time.Duration) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return "", err
+	}
+
+	defer tty.Close()
+
+	fd := int(tty.Fd())
+
+	state, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return "", err
+	}
+
+	raw := *state
+	raw.Lflag &^= unix.ICANON | unix.ECHO
+	// return from a read after a tenth of a second without input
+	raw.Cc[unix.VMIN] = 0
+	raw.Cc[unix.VTIME] = 1
+
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return "", err
+	}
+
+	// replies arriving after the deadline would be echoed onto the command line
+	// once the terminal leaves raw mode, so they are dropped first
+	defer func() {
+		_ = flushInput(fd)
+		_ = unix.IoctlSetTermios(fd, ioctlSetTermios, state)
+	}()
+
+	if _, err := tty.WriteString(queries); err != nil {
+		return "", err
+	}
+
+	var replies []byte
+	buffer := make([]byte, 256)
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		// a read returns nothing, io.EOF, after a tenth of a second without
+		// input, a reply can take longer than that over SSH
+		n, err := tty.Read(buffer)
+		if err != nil && !errors.Is(err, io.EOF) {
+			break
+		}
+
+		replies = append(replies, buffer[:n]...)
+
+		if probeComplete(string(replies)) {
+			break
+		}
+	}
+
+	return string(replies), nil
+}
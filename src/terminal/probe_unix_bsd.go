@@ -0,0 +1,18 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package terminal
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+
+	// fread is FREAD from sys/fcntl.h, the queue TIOCFLUSH flushes
+	fread = 0x1
+)
+
+// flushInput drops the input that arrived but wasn't read, like tcflush(fd, TCIFLUSH).
+func flushInput(fd int) error {
+	return unix.IoctlSetPointerInt(fd, unix.TIOCFLUSH, fread)
+}
@@ -0,0 +1,15 @@
+//go:build aix || linux || solaris || zos
+
+package terminal
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
+
+// flushInput drops the input that arrived but wasn't read, like tcflush(fd, TCIFLUSH).
+func flushInput(fd int) error {
+	return unix.IoctlSetInt(fd, unix.TCFLSH, unix.TCIFLUSH)
+}
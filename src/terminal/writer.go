@@ -1,9 +1,11 @@
 package terminal
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -110,8 +112,17 @@ var (
 	Shell   string
 	Program string
 
+	// PromptID identifies the shell session in the semantic prompt marks as
+	// aid=, which lets a terminal tell the prompts of nested shells apart.
+	PromptID string
+
 	progressTerminals []string
 
+	// noHyperlinks and progress are the probed capabilities of the terminal,
+	// see Capabilities.apply.
+	noHyperlinks bool
+	progress     bool
+
 	formats *shell.Formats
 
 	// escapePrefix/escapeSuffix are formats.Escape ("...%s...") split around its
@@ -304,6 +315,8 @@ func Init(sh string) {
 	color.TrueColor = Program != AppleTerminal
 
 	progressTerminals = []string{WindowsTerminal}
+	noHyperlinks = false
+	progress = false
 	formats = shell.GetFormats(Shell)
 
 	escapePrefix, escapeSuffix = "", ""
@@ -457,6 +470,11 @@ func EscapeText(txt string) string {
 	return fmt.Sprintf(formats.Escape, txt)
 }
 
+// Clipboard returns the OSC 52 sequence that puts text on the system clipboard.
+func Clipboard(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
 func SaveCursorPosition() string {
 	return formats.SaveCursorPosition
 }
@@ -465,22 +483,51 @@ func RestoreCursorPosition() string {
 	return formats.RestoreCursorPosition
 }
 
+// PromptKind is the k= parameter of a prompt start mark.
+type PromptKind string
+
+const (
+	PrimaryPrompt   PromptKind = "i"
+	SecondaryPrompt PromptKind = "s"
+	RightPrompt     PromptKind = "r"
+)
+
+// semanticMark returns an OSC 133 mark with its parameters, adding the aid=
+// parameter when identify is set and PromptID is known.
+func semanticMark(identify bool, params ...string) string {
+	if identify && len(PromptID) != 0 {
+		params = append(params, "aid="+PromptID)
+	}
+
+	mark := "\x1b]133;" + strings.Join(params, ";") + "\007"
+
+	return fmt.Sprintf(formats.Escape, mark)
+}
+
 func PromptStart() string {
-	return fmt.Sprintf(formats.Escape, "\x1b]133;A\007")
+	return PromptKindStart(PrimaryPrompt)
+}
+
+// PromptKindStart marks the start of a prompt of the given kind. A right
+// prompt doesn't start on a fresh line, hence the P mark.
+func PromptKindStart(kind PromptKind) string {
+	if kind == RightPrompt {
+		return semanticMark(false, "P", "k=r")
+	}
+
+	return semanticMark(true, "A", "k="+string(kind))
 }
 
 func CommandStart() string {
-	return fmt.Sprintf(formats.Escape, "\x1b]133;B\007")
+	return semanticMark(false, "B")
 }
 
 func CommandFinished(code int, ignore bool) string {
 	if ignore {
-		return fmt.Sprintf(formats.Escape, "\x1b]133;D\007")
+		return semanticMark(true, "D")
 	}
 
-	mark := fmt.Sprintf("\x1b]133;D;%d\007", code)
-
-	return fmt.Sprintf(formats.Escape, mark)
+	return semanticMark(true, "D", strconv.Itoa(code))
 }
 
 func LineBreak() string {
@@ -490,6 +537,10 @@ func LineBreak() string {
 }
 
 func progressSupported() bool {
+	if progress {
+		return true
+	}
+
 	return slices.ContainsFunc(progressTerminals, func(program string) bool {
 		return strings.EqualFold(program, Program)
 	})
@@ -844,12 +895,12 @@ func String() (string, int) {
 
 // writeHyperlinkEscape writes one of the OSC 8 hyperlink wrapper sequences
 // (formats.HyperlinkStart/Center/End) directly to the builder, suppressed
-// entirely in Plain mode. It is not routed through writeEscapedAnsiString:
-// those sequences bracket the URL and link text, which stream through write()
-// separately while isHyperlink is set, so this only needs to guard on Plain,
-// not shell-escape the payload.
+// entirely in Plain mode or when the terminal lacks OSC 8 support. It is not
+// routed through writeEscapedAnsiString: those sequences bracket the URL and
+// link text, which stream through write() separately while isHyperlink is set,
+// so this only needs to guard on Plain, not shell-escape the payload.
 func writeHyperlinkEscape(txt string) {
-	if Plain {
+	if Plain || noHyperlinks {
 		return
 	}
 
@@ -936,9 +987,10 @@ func write(s rune, isInvisible bool) {
 	// text between <LINK> and <TEXT> may reach the builder: the URL runes stream
 	// through here while isHyperlink is set, and unlike the plain-rune path below
 	// they are never counted toward length, so leaving them unguarded would let
-	// invisible text corrupt every width consumer downstream.
+	// invisible text corrupt every width consumer downstream. The same goes for
+	// a terminal without OSC 8 support, which only gets the link text.
 	if isHyperlink {
-		if Plain {
+		if Plain || noHyperlinks {
 			return
 		}
 
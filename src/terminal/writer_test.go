@@ -672,3 +672,28 @@ func TestStripControlRunes(t *testing.T) {
 		})
 	}
 }
+
+func TestClipboard(t *testing.T) {
+	assert.Equal(t, "\x1b]52;c;aGVsbG8gd29ybGQ=\a", Clipboard("hello world"))
+}
+
+func TestSemanticPromptMarks(t *testing.T) {
+	t.Cleanup(func() { PromptID = "" })
+
+	Init(shell.GENERIC)
+
+	PromptID = ""
+	assert.Equal(t, "\x1b]133;A;k=i\a", PromptStart())
+	assert.Equal(t, "\x1b]133;D;1\a", CommandFinished(1, false))
+
+	PromptID = "1337"
+	assert.Equal(t, "\x1b]133;A;k=i;aid=1337\a", PromptStart())
+	assert.Equal(t, "\x1b]133;A;k=s;aid=1337\a", PromptKindStart(SecondaryPrompt))
+	assert.Equal(t, "\x1b]133;P;k=r\a", PromptKindStart(RightPrompt))
+	assert.Equal(t, "\x1b]133;B\a", CommandStart())
+	assert.Equal(t, "\x1b]133;D;aid=1337\a", CommandFinished(0, true))
+	assert.Equal(t, "\x1b]133;D;2;aid=1337\a", CommandFinished(2, false))
+
+	Init(shell.ZSH)
+	assert.Equal(t, "%{\x1b]133;B\a%}", CommandStart())
+}
@@ -277,6 +277,7 @@ export default {
             "segments/system/os",
             "segments/system/path",
             "segments/system/project",
+            "segments/system/recent-dirs",
             "segments/system/root",
             "segments/system/session",
             "segments/system/shell",
@@ -339,6 +340,7 @@ export default {
       },
       items: [
         "dsc",
+        "advanced/completion",
         "advanced/mcp-server",
       ],
     },